@@ -0,0 +1,208 @@
+package ib
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// AccountAmount is a numeric account summary value, with its currency (which
+// is empty for ratios such as Cushion and Leverage).
+type AccountAmount struct {
+	Value    float64
+	Currency string
+}
+
+// AccountSummaryManager streams the account summary of a group of accounts,
+// for a chosen set of tags. Refresh() signals once the initial summary has
+// been received and then after each changed value.
+type AccountSummaryManager struct {
+	AbstractManager
+	id     int64
+	group  string
+	tags   []string
+	values map[AccountSummaryKey]AccountSummary
+	synced bool
+}
+
+// NewAccountSummaryManager requests the tags (eg AccountSummaryTagNetLiquidation)
+// for the group (eg "All"). Without tags, all tags are requested.
+func NewAccountSummaryManager(e *Engine, group string, tags ...string) (*AccountSummaryManager, error) {
+	am, err := NewAbstractManager(e)
+	if err != nil {
+		return nil, err
+	}
+	if len(tags) == 0 {
+		tags = allTags[:]
+	}
+
+	m := &AccountSummaryManager{
+		AbstractManager: *am,
+		id:              e.NextRequestID(),
+		group:           group,
+		tags:            tags,
+		values:          map[AccountSummaryKey]AccountSummary{},
+	}
+
+	go m.startMainLoop(m.preLoop, m.receive, m.preDestroy)
+	return m, nil
+}
+
+func (m *AccountSummaryManager) preLoop() error {
+	m.rwm.Lock()
+	m.synced = false
+	m.rwm.Unlock()
+
+	m.eng.Subscribe(m.rc, m.id)
+	req := &RequestAccountSummary{Group: m.group, Tags: strings.Join(m.tags, ",")}
+	req.SetID(m.id)
+	return m.eng.Send(req)
+}
+
+func (m *AccountSummaryManager) receive(r Reply) (UpdateStatus, error) {
+	switch r.(type) {
+	case *ErrorMessage:
+		r := r.(*ErrorMessage)
+		if r.ID() != m.id || r.SeverityWarning() {
+			return UpdateFalse, nil
+		}
+		return UpdateFalse, r.Error()
+	case *AccountSummary:
+		r := r.(*AccountSummary)
+		old, ok := m.values[r.Key]
+		m.values[r.Key] = *r
+		if !m.synced || (ok && old.Value == r.Value && old.Currency == r.Currency) {
+			return UpdateFalse, nil
+		}
+		return UpdateTrue, nil
+	case *AccountSummaryEnd:
+		if m.synced {
+			return UpdateFalse, nil
+		}
+		m.synced = true
+		return UpdateTrue, nil
+	}
+	return UpdateFalse, nil
+}
+
+func (m *AccountSummaryManager) preDestroy() {
+	m.eng.Unsubscribe(m.rc, m.id)
+	req := &CancelAccountSummary{}
+	req.SetID(m.id)
+	m.eng.Send(req)
+}
+
+// Values returns the raw values received.
+func (m *AccountSummaryManager) Values() map[AccountSummaryKey]AccountSummary {
+	m.rwm.RLock()
+	defer m.rwm.RUnlock()
+	values := make(map[AccountSummaryKey]AccountSummary, len(m.values))
+	for k, v := range m.values {
+		values[k] = v
+	}
+	return values
+}
+
+// Accounts returns the accounts with values, in order.
+func (m *AccountSummaryManager) Accounts() []string {
+	m.rwm.RLock()
+	defer m.rwm.RUnlock()
+	seen := map[string]bool{}
+	var accounts []string
+	for k := range m.values {
+		if !seen[k.AccountCode] {
+			seen[k.AccountCode] = true
+			accounts = append(accounts, k.AccountCode)
+		}
+	}
+	sort.Strings(accounts)
+	return accounts
+}
+
+func (m *AccountSummaryManager) value(account string, tag string) (AccountSummary, error) {
+	m.rwm.RLock()
+	defer m.rwm.RUnlock()
+	v, ok := m.values[AccountSummaryKey{AccountCode: account, Key: tag}]
+	if !ok {
+		return v, fmt.Errorf("goib: no %s for account %q", tag, account)
+	}
+	return v, nil
+}
+
+// Amount returns the numeric value of the tag for the account.
+func (m *AccountSummaryManager) Amount(account string, tag string) (AccountAmount, error) {
+	v, err := m.value(account, tag)
+	if err != nil {
+		return AccountAmount{}, err
+	}
+	f, err := strconv.ParseFloat(v.Value, 64)
+	if err != nil {
+		return AccountAmount{}, fmt.Errorf("goib: %s for account %q is not a number: %q", tag, account, v.Value)
+	}
+	return AccountAmount{Value: f, Currency: v.Currency}, nil
+}
+
+// Int returns the integer value of the tag for the account.
+func (m *AccountSummaryManager) Int(account string, tag string) (int64, error) {
+	v, err := m.value(account, tag)
+	if err != nil {
+		return 0, err
+	}
+	i, err := strconv.ParseInt(v.Value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("goib: %s for account %q is not an integer: %q", tag, account, v.Value)
+	}
+	return i, nil
+}
+
+// String returns the value of the tag for the account.
+func (m *AccountSummaryManager) String(account string, tag string) (string, error) {
+	v, err := m.value(account, tag)
+	return v.Value, err
+}
+
+// AccountType .
+func (m *AccountSummaryManager) AccountType(account string) (string, error) {
+	return m.String(account, AccountSummaryTagAccountType)
+}
+
+// DayTradesRemaining is -1 when unlimited.
+func (m *AccountSummaryManager) DayTradesRemaining(account string) (int64, error) {
+	return m.Int(account, AccountSummaryTagDayTradesRemaining)
+}
+
+// NetLiquidation .
+func (m *AccountSummaryManager) NetLiquidation(account string) (AccountAmount, error) {
+	return m.Amount(account, AccountSummaryTagNetLiquidation)
+}
+
+// ExcessLiquidity .
+func (m *AccountSummaryManager) ExcessLiquidity(account string) (AccountAmount, error) {
+	return m.Amount(account, AccountSummaryTagExcessLiquidity)
+}
+
+// AvailableFunds .
+func (m *AccountSummaryManager) AvailableFunds(account string) (AccountAmount, error) {
+	return m.Amount(account, AccountSummaryTagAvailableFunds)
+}
+
+// BuyingPower .
+func (m *AccountSummaryManager) BuyingPower(account string) (AccountAmount, error) {
+	return m.Amount(account, AccountSummaryTagBuyingPower)
+}
+
+// TotalCashValue .
+func (m *AccountSummaryManager) TotalCashValue(account string) (AccountAmount, error) {
+	return m.Amount(account, AccountSummaryTagTotalCashValue)
+}
+
+// InitMarginReq .
+func (m *AccountSummaryManager) InitMarginReq(account string) (AccountAmount, error) {
+	return m.Amount(account, AccountSummaryTagInitMarginReq)
+}
+
+// MaintMarginReq .
+func (m *AccountSummaryManager) MaintMarginReq(account string) (AccountAmount, error) {
+	return m.Amount(account, AccountSummaryTagMaintMarginReq)
+}
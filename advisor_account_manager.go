@@ -87,7 +87,7 @@ func NewAdvisorAccountManager(e *Engine) (*AdvisorAccountManager, error) {
 	}
 
 	a := &AdvisorAccountManager{AbstractManager: *am,
-		id:        UnmatchedReplyID,
+		id:        e.NextRequestID(),
 		values:    map[AccountSummaryKey]AccountSummary{},
 		portfolio: map[PositionKey]Position{},
 	}
@@ -97,7 +97,10 @@ func NewAdvisorAccountManager(e *Engine) (*AdvisorAccountManager, error) {
 }
 
 func (a *AdvisorAccountManager) preLoop() error {
-	a.id = a.eng.NextRequestID()
+	a.rwm.Lock()
+	a.endMsgs = 0
+	a.rwm.Unlock()
+
 	a.eng.Subscribe(a.rc, a.id)
 
 	var tags bytes.Buffer
@@ -22,6 +22,7 @@ func NewChainManager(e *Engine, c Contract) (*ChainManager, error) {
 
 	m := &ChainManager{
 		AbstractManager: *am,
+		id:              e.NextRequestID(),
 		c:               c,
 		chains:          OptionChains{},
 	}
@@ -31,7 +32,6 @@ func NewChainManager(e *Engine, c Contract) (*ChainManager, error) {
 }
 
 func (c *ChainManager) preLoop() error {
-	c.id = c.eng.NextRequestID()
 	req := &RequestContractData{Contract: c.c}
 	req.Contract.SecurityType = "OPT"
 	req.Contract.LocalSymbol = ""
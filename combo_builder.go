@@ -0,0 +1,238 @@
+package ib
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// ComboLegSpec is a leg of a combo, as passed to BuildCombo.
+type ComboLegSpec struct {
+	Contract Contract // resolved by BuildCombo if it has no ContractID
+	Action   string   // ActionBuy or ActionSell
+	Ratio    int64
+	Price    float64 // the leg's limit price (see ComboLegLimitOrder), or math.MaxFloat64
+}
+
+// Leg returns a leg without a leg price.
+func Leg(c Contract, action string, ratio int64) ComboLegSpec {
+	return ComboLegSpec{Contract: c, Action: action, Ratio: ratio, Price: math.MaxFloat64}
+}
+
+// BuildCombo returns the BAG contract trading the legs together. Legs without
+// a ContractID are resolved with the resolver (which may be nil if every leg
+// has one). The combo takes its Symbol and Currency from the first leg, and is
+// routed to exchange (eg "SMART"), as is each leg.
+//
+// Buying the combo takes each leg's action, and selling it the opposite.
+func BuildCombo(r *ContractResolver, exchange string, legs ...ComboLegSpec) (Contract, error) {
+	if len(legs) < 2 {
+		return Contract{}, errors.New("goib: combo requires at least two legs")
+	}
+	if exchange == "" {
+		return Contract{}, errors.New("goib: combo requires an exchange")
+	}
+
+	combo := Contract{SecurityType: bagSecType, Exchange: exchange}
+	seen := map[int64]bool{}
+	for i, l := range legs {
+		if l.Action != ActionBuy && l.Action != ActionSell {
+			return Contract{}, fmt.Errorf("goib: invalid action %q for combo leg %d", l.Action, i)
+		}
+		if l.Ratio <= 0 {
+			return Contract{}, fmt.Errorf("goib: ratio of combo leg %d must be positive, not %d", i, l.Ratio)
+		}
+
+		c := l.Contract
+		if c.ContractID == 0 {
+			if r == nil {
+				return Contract{}, fmt.Errorf("goib: combo leg %d has no ContractID", i)
+			}
+			var err error
+			if c, err = r.Resolve(c); err != nil {
+				return Contract{}, err
+			}
+		}
+		if seen[c.ContractID] {
+			return Contract{}, fmt.Errorf("goib: contract %d is in more than one combo leg", c.ContractID)
+		}
+		seen[c.ContractID] = true
+
+		if i == 0 {
+			combo.Symbol = c.Symbol
+			combo.Currency = c.Currency
+		} else if c.Currency != "" && combo.Currency != "" && c.Currency != combo.Currency {
+			return Contract{}, fmt.Errorf("goib: combo leg %d is in %s, not %s", i, c.Currency, combo.Currency)
+		}
+		combo.ComboLegs = append(combo.ComboLegs, ComboLeg{
+			ContractID: c.ContractID,
+			Ratio:      l.Ratio,
+			Action:     l.Action,
+			Exchange:   exchange,
+		})
+	}
+	return combo, nil
+}
+
+// ComboLegPrices returns the Order.OrderComboLegs for the legs' prices, in leg
+// order. It returns nil if no leg has a price, and an error if only some do.
+func ComboLegPrices(legs ...ComboLegSpec) ([]OrderComboLeg, error) {
+	n := 0
+	for _, l := range legs {
+		if priced(l.Price) {
+			n++
+		}
+	}
+	switch n {
+	case 0:
+		return nil, nil
+	case len(legs):
+	default:
+		return nil, errors.New("goib: either every combo leg or none must have a price")
+	}
+	prices := make([]OrderComboLeg, len(legs))
+	for i, l := range legs {
+		prices[i].Price = l.Price
+	}
+	return prices, nil
+}
+
+// ComboLimitOrder is a limit order for a combo as a whole. The limit may be
+// zero or negative, as for a credit spread.
+func ComboLimitOrder(action string, qty int64, limit float64) (Order, error) {
+	return newValidatedOrder(ValidateComboOrder, action, qty, OrderTypeLimit, func(o *Order) { o.LimitPrice = limit })
+}
+
+// ComboLegLimitOrder is a limit order for a combo with a limit price on each
+// leg rather than on the combo as a whole. IB only accepts leg prices on
+// non-guaranteed combos (routed to fill each leg on its own), so the order
+// requests that through SmartComboRoutingParams.
+func ComboLegLimitOrder(action string, qty int64, legs ...ComboLegSpec) (Order, error) {
+	prices, err := ComboLegPrices(legs...)
+	if err != nil {
+		return Order{}, err
+	}
+	if prices == nil {
+		return Order{}, errors.New("goib: combo leg limit order requires leg prices")
+	}
+	return newValidatedOrder(ValidateComboOrder, action, qty, OrderTypeLimit, func(o *Order) {
+		o.OrderComboLegs = prices
+		o.SmartComboRoutingParams = []TagValue{{Tag: "NonGuaranteed", Value: "1"}}
+	})
+}
+
+// optionRight normalises an option right to "C" or "P" (IB also accepts "CALL"
+// and "PUT"), or "" if it is neither.
+func optionRight(right string) string {
+	switch right {
+	case "C", "CALL":
+		return "C"
+	case "P", "PUT":
+		return "P"
+	}
+	return ""
+}
+
+// sameSeries checks the options have the same right (unless right is "", in
+// which case any) and expiry, and strikes in strictly ascending order.
+func sameSeries(preset string, right string, options ...Contract) error {
+	for i, o := range options {
+		if optionRight(o.Right) == "" || (right != "" && optionRight(o.Right) != right) {
+			return fmt.Errorf("goib: %s leg %d has the wrong right %q", preset, i, o.Right)
+		}
+		if o.Expiry != options[0].Expiry {
+			return fmt.Errorf("goib: %s legs must have the same expiry", preset)
+		}
+		if i > 0 && o.Strike <= options[i-1].Strike {
+			return fmt.Errorf("goib: %s strikes must be in ascending order", preset)
+		}
+	}
+	return nil
+}
+
+// VerticalSpread buys the long option and sells the short option, which must
+// have the same right and expiry but different strikes (eg a bull call spread
+// buys the lower strike call).
+func VerticalSpread(long Contract, short Contract) ([]ComboLegSpec, error) {
+	lower, upper := long, short
+	if lower.Strike > upper.Strike {
+		lower, upper = upper, lower
+	}
+	if err := sameSeries("vertical spread", optionRight(long.Right), lower, upper); err != nil {
+		return nil, err
+	}
+	return []ComboLegSpec{Leg(long, ActionBuy, 1), Leg(short, ActionSell, 1)}, nil
+}
+
+// Straddle buys a call and a put with the same strike and expiry.
+func Straddle(call Contract, put Contract) ([]ComboLegSpec, error) {
+	if optionRight(call.Right) != "C" || optionRight(put.Right) != "P" {
+		return nil, errors.New("goib: straddle requires a call and a put")
+	}
+	if call.Strike != put.Strike || call.Expiry != put.Expiry {
+		return nil, errors.New("goib: straddle legs must have the same strike and expiry")
+	}
+	return []ComboLegSpec{Leg(call, ActionBuy, 1), Leg(put, ActionBuy, 1)}, nil
+}
+
+// Strangle buys an out of the money call and put with the same expiry, the
+// put's strike below the call's.
+func Strangle(call Contract, put Contract) ([]ComboLegSpec, error) {
+	if optionRight(call.Right) != "C" || optionRight(put.Right) != "P" {
+		return nil, errors.New("goib: strangle requires a call and a put")
+	}
+	if call.Expiry != put.Expiry {
+		return nil, errors.New("goib: strangle legs must have the same expiry")
+	}
+	if put.Strike >= call.Strike {
+		return nil, errors.New("goib: strangle put strike must be below the call strike")
+	}
+	return []ComboLegSpec{Leg(call, ActionBuy, 1), Leg(put, ActionBuy, 1)}, nil
+}
+
+// CalendarSpread sells the near contract and buys the far contract, which
+// must differ only by expiry (options of the same right and strike, or
+// futures).
+func CalendarSpread(near Contract, far Contract) ([]ComboLegSpec, error) {
+	if near.Expiry == "" || far.Expiry == "" || near.Expiry >= far.Expiry {
+		return nil, errors.New("goib: calendar spread near expiry must be before the far expiry")
+	}
+	if optionRight(near.Right) != optionRight(far.Right) || near.Strike != far.Strike {
+		return nil, errors.New("goib: calendar spread legs must have the same right and strike")
+	}
+	return []ComboLegSpec{Leg(near, ActionSell, 1), Leg(far, ActionBuy, 1)}, nil
+}
+
+// Butterfly buys the lower and upper wings and sells two of the body, which
+// must be options of the same right and expiry with ascending, equally spaced
+// strikes.
+func Butterfly(lower Contract, body Contract, upper Contract) ([]ComboLegSpec, error) {
+	if err := sameSeries("butterfly", optionRight(lower.Right), lower, body, upper); err != nil {
+		return nil, err
+	}
+	if math.Abs((body.Strike-lower.Strike)-(upper.Strike-body.Strike)) > 1e-9 {
+		return nil, errors.New("goib: butterfly wings must be equally spaced from the body")
+	}
+	return []ComboLegSpec{Leg(lower, ActionBuy, 1), Leg(body, ActionSell, 2), Leg(upper, ActionBuy, 1)}, nil
+}
+
+// IronCondor sells a put spread and a call spread with the same expiry: it
+// buys the long put, sells the short put and short call, and buys the long
+// call, in order of ascending strike.
+func IronCondor(longPut Contract, shortPut Contract, shortCall Contract, longCall Contract) ([]ComboLegSpec, error) {
+	if err := sameSeries("iron condor", "P", longPut, shortPut); err != nil {
+		return nil, err
+	}
+	if err := sameSeries("iron condor", "C", shortCall, longCall); err != nil {
+		return nil, err
+	}
+	if shortPut.Expiry != shortCall.Expiry || shortPut.Strike >= shortCall.Strike {
+		return nil, errors.New("goib: iron condor put spread must be below the call spread, with the same expiry")
+	}
+	return []ComboLegSpec{
+		Leg(longPut, ActionBuy, 1),
+		Leg(shortPut, ActionSell, 1),
+		Leg(shortCall, ActionSell, 1),
+		Leg(longCall, ActionBuy, 1),
+	}, nil
+}
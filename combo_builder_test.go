@@ -0,0 +1,190 @@
+package ib
+
+import (
+	"math"
+	"testing"
+)
+
+func option(id int64, right string, strike float64, expiry string) Contract {
+	return Contract{ContractID: id, Symbol: "SPY", SecurityType: "OPT", Right: right, Strike: strike, Expiry: expiry, Exchange: "SMART", Currency: "USD"}
+}
+
+func TestBuildCombo(t *testing.T) {
+	legs, err := VerticalSpread(option(1, "C", 400, "20240621"), option(2, "C", 410, "20240621"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	combo, err := BuildCombo(nil, "SMART", legs...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if combo.SecurityType != "BAG" || combo.Symbol != "SPY" || combo.Currency != "USD" || combo.Exchange != "SMART" {
+		t.Fatalf("unexpected combo %+v", combo)
+	}
+	want := []ComboLeg{
+		{ContractID: 1, Ratio: 1, Action: ActionBuy, Exchange: "SMART"},
+		{ContractID: 2, Ratio: 1, Action: ActionSell, Exchange: "SMART"},
+	}
+	if len(combo.ComboLegs) != len(want) {
+		t.Fatalf("unexpected legs %+v", combo.ComboLegs)
+	}
+	for i, l := range want {
+		if combo.ComboLegs[i] != l {
+			t.Fatalf("leg %d: expected %+v, got %+v", i, l, combo.ComboLegs[i])
+		}
+	}
+
+	invalid := [][]ComboLegSpec{
+		{Leg(option(1, "C", 400, "20240621"), ActionBuy, 1)},
+		{Leg(option(1, "C", 400, "20240621"), ActionBuy, 1), Leg(option(2, "C", 410, "20240621"), "HOLD", 1)},
+		{Leg(option(1, "C", 400, "20240621"), ActionBuy, 1), Leg(option(2, "C", 410, "20240621"), ActionSell, 0)},
+		{Leg(option(1, "C", 400, "20240621"), ActionBuy, 1), Leg(option(1, "C", 400, "20240621"), ActionSell, 1)},
+		{Leg(option(1, "C", 400, "20240621"), ActionBuy, 1), Leg(option(0, "C", 410, "20240621"), ActionSell, 1)},
+	}
+	for i, legs := range invalid {
+		if _, err := BuildCombo(nil, "SMART", legs...); err == nil {
+			t.Errorf("invalid combo %d was accepted", i)
+		}
+	}
+}
+
+func TestComboCreditOrder(t *testing.T) {
+	legs, err := IronCondor(option(1, "P", 380, "20240621"), option(2, "P", 390, "20240621"), option(3, "C", 410, "20240621"), option(4, "C", 420, "20240621"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	combo, err := BuildCombo(nil, "SMART", legs...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// buying the condor receives a credit, so its limit price is negative
+	o, err := ComboLimitOrder(ActionBuy, 1, -1.25)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o.OrderType != OrderTypeLimit || o.LimitPrice != -1.25 {
+		t.Fatalf("unexpected order %+v", o)
+	}
+	d := ContractDetails{Summary: combo, MinTick: 0.05, OrderTypes: "LMT,MKT", ValidExchanges: "SMART"}
+	if vs := CheckOrder(o, combo, d); len(vs) != 0 {
+		t.Fatalf("unexpected violations %v", vs)
+	}
+	if err := ValidateOrder(o); err == nil {
+		t.Fatal("expected a negative limit price to be invalid outside a combo")
+	}
+	if _, err := ComboLimitOrder(ActionBuy, 1, 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ComboLimitOrder(ActionBuy, 1, math.MaxFloat64); err == nil {
+		t.Fatal("expected error without a limit price")
+	}
+}
+
+func TestComboLegLimitOrder(t *testing.T) {
+	legs, err := Straddle(option(1, "C", 400, "20240621"), option(2, "P", 400, "20240621"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if prices, err := ComboLegPrices(legs...); err != nil || prices != nil {
+		t.Fatalf("expected no leg prices, got %+v (%v)", prices, err)
+	}
+	if _, err := ComboLegLimitOrder(ActionBuy, 1, legs...); err == nil {
+		t.Fatal("expected error without leg prices")
+	}
+
+	legs[0].Price = 5.1
+	if _, err := ComboLegPrices(legs...); err == nil {
+		t.Fatal("expected error with only some leg prices")
+	}
+
+	legs[1].Price = 4.9
+	o, err := ComboLegLimitOrder(ActionBuy, 1, legs...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o.OrderType != OrderTypeLimit || o.LimitPrice != math.MaxFloat64 || len(o.OrderComboLegs) != 2 || o.OrderComboLegs[1].Price != 4.9 {
+		t.Fatalf("unexpected order %+v", o)
+	}
+	if len(o.SmartComboRoutingParams) != 1 || o.SmartComboRoutingParams[0].Tag != "NonGuaranteed" {
+		t.Fatalf("unexpected routing params %+v", o.SmartComboRoutingParams)
+	}
+
+	o.OrderComboLegs[0].Price = math.MaxFloat64
+	if err := ValidateOrder(o); err == nil {
+		t.Fatal("expected error for a leg without a price")
+	}
+}
+
+func TestComboPresets(t *testing.T) {
+	ratios := func(legs []ComboLegSpec) (s string) {
+		for _, l := range legs {
+			s += l.Action[:1]
+			for i := int64(0); i < l.Ratio; i++ {
+				s += "+"
+			}
+		}
+		return s
+	}
+
+	valid := []struct {
+		name string
+		f    func() ([]ComboLegSpec, error)
+		want string
+	}{
+		{"vertical", func() ([]ComboLegSpec, error) {
+			return VerticalSpread(option(2, "P", 410, "20240621"), option(1, "P", 400, "20240621"))
+		}, "B+S+"},
+		{"strangle", func() ([]ComboLegSpec, error) {
+			return Strangle(option(1, "CALL", 420, "20240621"), option(2, "PUT", 380, "20240621"))
+		}, "B+B+"},
+		{"calendar", func() ([]ComboLegSpec, error) {
+			return CalendarSpread(option(1, "C", 400, "20240621"), option(2, "C", 400, "20240920"))
+		}, "S+B+"},
+		{"butterfly", func() ([]ComboLegSpec, error) {
+			return Butterfly(option(1, "C", 390, "20240621"), option(2, "C", 400, "20240621"), option(3, "C", 410, "20240621"))
+		}, "B+S++B+"},
+		{"iron condor", func() ([]ComboLegSpec, error) {
+			return IronCondor(option(1, "P", 380, "20240621"), option(2, "P", 390, "20240621"), option(3, "C", 410, "20240621"), option(4, "C", 420, "20240621"))
+		}, "B+S+S+B+"},
+	}
+	for _, v := range valid {
+		legs, err := v.f()
+		if err != nil {
+			t.Errorf("%s: %s", v.name, err)
+			continue
+		}
+		if got := ratios(legs); got != v.want {
+			t.Errorf("%s: expected legs %s, got %s", v.name, v.want, got)
+		}
+	}
+
+	invalid := []func() ([]ComboLegSpec, error){
+		func() ([]ComboLegSpec, error) {
+			return VerticalSpread(option(1, "C", 400, "20240621"), option(2, "P", 410, "20240621"))
+		},
+		func() ([]ComboLegSpec, error) {
+			return VerticalSpread(option(1, "C", 400, "20240621"), option(2, "C", 400, "20240621"))
+		},
+		func() ([]ComboLegSpec, error) {
+			return Straddle(option(1, "C", 400, "20240621"), option(2, "P", 410, "20240621"))
+		},
+		func() ([]ComboLegSpec, error) {
+			return Strangle(option(1, "C", 380, "20240621"), option(2, "P", 420, "20240621"))
+		},
+		func() ([]ComboLegSpec, error) {
+			return CalendarSpread(option(1, "C", 400, "20240920"), option(2, "C", 400, "20240621"))
+		},
+		func() ([]ComboLegSpec, error) {
+			return Butterfly(option(1, "C", 390, "20240621"), option(2, "C", 400, "20240621"), option(3, "C", 420, "20240621"))
+		},
+		func() ([]ComboLegSpec, error) {
+			return IronCondor(option(1, "P", 380, "20240621"), option(2, "P", 410, "20240621"), option(3, "C", 400, "20240621"), option(4, "C", 420, "20240621"))
+		},
+	}
+	for i, f := range invalid {
+		if _, err := f(); err == nil {
+			t.Errorf("invalid preset %d was accepted", i)
+		}
+	}
+}
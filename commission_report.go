@@ -1,6 +1,9 @@
 package ib
 
-import "bufio"
+import (
+	"bufio"
+	"bytes"
+)
 
 // This file ports IB API CommissionReport.java. Please preserve declaration order.
 
@@ -41,3 +44,14 @@ func (c *CommissionReport) read(b *bufio.Reader) error {
 	}
 	return nil
 }
+
+func (c *CommissionReport) write(b *bytes.Buffer) error {
+	return (writeMapSlice{
+		{fct: writeString, val: c.ExecutionID},
+		{fct: writeMaxFloat, val: c.Commission},
+		{fct: writeString, val: c.Currency},
+		{fct: writeMaxFloat, val: c.RealizedPNL},
+		{fct: writeMaxFloat, val: c.Yield},
+		{fct: writeMaxInt, val: c.YieldRedemptionDate},
+	}).Dump(b)
+}
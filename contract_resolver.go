@@ -0,0 +1,247 @@
+package ib
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// resolveTimeout is how long a ContractResolver waits for IB's contract details.
+var resolveTimeout = 10 * time.Second
+
+// errorNoSecurityDefinition is the ErrorMessage code for a contract IB cannot
+// find.
+const errorNoSecurityDefinition = 200
+
+// AmbiguousContractError reports a partial contract matching more than one
+// contract. Adding fields from one of the candidates (eg its Exchange,
+// Currency or ContractID) to the contract makes it unambiguous.
+type AmbiguousContractError struct {
+	Contract   Contract
+	Candidates []ContractDetails
+}
+
+func (a *AmbiguousContractError) Error() string {
+	ids := make([]string, len(a.Candidates))
+	for i, d := range a.Candidates {
+		ids[i] = strconv.FormatInt(d.Summary.ContractID, 10)
+	}
+	return fmt.Sprintf("goib: contract %s is ambiguous, matching contracts %s", describeContract(a.Contract), strings.Join(ids, ", "))
+}
+
+// UnknownContractError reports a contract IB has no definition for.
+type UnknownContractError struct {
+	Contract Contract
+}
+
+func (u *UnknownContractError) Error() string {
+	return fmt.Sprintf("goib: no contract matches %s", describeContract(u.Contract))
+}
+
+// describeContract is the non-empty identifying fields of the contract.
+func describeContract(c Contract) string {
+	var fields []string
+	add := func(name string, value string) {
+		if value != "" {
+			fields = append(fields, name+"="+value)
+		}
+	}
+	if c.ContractID != 0 {
+		add("ContractID", strconv.FormatInt(c.ContractID, 10))
+	}
+	add("Symbol", c.Symbol)
+	add("SecurityType", c.SecurityType)
+	add("Expiry", c.Expiry)
+	if c.Strike != 0 {
+		add("Strike", strconv.FormatFloat(c.Strike, 'f', -1, 64))
+	}
+	add("Right", c.Right)
+	add("Multiplier", c.Multiplier)
+	add("Exchange", c.Exchange)
+	add("PrimaryExchange", c.PrimaryExchange)
+	add("Currency", c.Currency)
+	add("LocalSymbol", c.LocalSymbol)
+	add("TradingClass", c.TradingClass)
+	add("SecIDType", c.SecIDType)
+	add("SecID", c.SecID)
+	return "{" + strings.Join(fields, " ") + "}"
+}
+
+// resolverCache is the on-disk form of a ContractResolver's cache.
+type resolverCache struct {
+	Contracts map[int64]ContractDetails `json:"contracts"`
+	Queries   map[string]int64          `json:"queries"`
+}
+
+// ContractResolver qualifies partial contracts (eg only a Symbol and
+// SecurityType, or a SecIDType such as "ISIN" with its SecID) into the one
+// contract IB knows them as, complete with its ContractID.
+//
+// Resolved contract details are cached by ContractID, along with the
+// ContractID each partial contract resolved to, so repeated lookups do not
+// send RequestContractData again. If the resolver has a cache file, the cache
+// is loaded from it on creation and saved to it after each new resolution.
+type ContractResolver struct {
+	eng     *Engine
+	path    string
+	m       sync.Mutex
+	details map[int64]ContractDetails
+	queries map[string]int64
+}
+
+// NewContractResolver returns a resolver caching in memory and, unless path is
+// empty, in the file at path.
+func NewContractResolver(e *Engine, path string) (*ContractResolver, error) {
+	r := &ContractResolver{
+		eng:     e,
+		path:    path,
+		details: map[int64]ContractDetails{},
+		queries: map[string]int64{},
+	}
+	if path == "" {
+		return r, nil
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return r, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var cache resolverCache
+	if err := json.Unmarshal(data, &cache); err != nil {
+		return nil, fmt.Errorf("goib: cannot read contract cache %s: %s", path, err)
+	}
+	for id, d := range cache.Contracts {
+		r.details[id] = d
+	}
+	for q, id := range cache.Queries {
+		if _, ok := r.details[id]; ok {
+			r.queries[q] = id
+		}
+	}
+	return r, nil
+}
+
+// queryKey identifies a partial contract in the cache.
+func queryKey(c Contract) string {
+	c.ComboLegs = nil
+	c.UnderComp = nil
+	c.ComboLegsDescription = ""
+	return describeContract(c)
+}
+
+// Resolve returns the fully specified contract for the partial contract.
+func (r *ContractResolver) Resolve(c Contract) (Contract, error) {
+	d, err := r.ResolveDetails(c)
+	return d.Summary, err
+}
+
+// ResolveDetails returns the contract details for the partial contract. It
+// returns an *AmbiguousContractError if more than one contract matches, and an
+// *UnknownContractError if none does. If the resolution cannot be saved to the
+// cache file, the details are returned along with the error.
+func (r *ContractResolver) ResolveDetails(c Contract) (ContractDetails, error) {
+	key := queryKey(c)
+	r.m.Lock()
+	id, ok := r.queries[key]
+	if !ok && c.ContractID != 0 {
+		id, ok = c.ContractID, true
+	}
+	d, cached := r.details[id]
+	r.m.Unlock()
+	if ok && cached {
+		return d, nil
+	}
+
+	candidates, err := r.request(c)
+	if err != nil {
+		return ContractDetails{}, err
+	}
+	switch len(candidates) {
+	case 0:
+		return ContractDetails{}, &UnknownContractError{Contract: c}
+	case 1:
+	default:
+		return ContractDetails{}, &AmbiguousContractError{Contract: c, Candidates: candidates}
+	}
+
+	d = candidates[0]
+	r.m.Lock()
+	defer r.m.Unlock()
+	r.details[d.Summary.ContractID] = d
+	r.queries[key] = d.Summary.ContractID
+	return d, r.save()
+}
+
+// Cached returns the cached contract details for the ContractID.
+func (r *ContractResolver) Cached(id int64) (ContractDetails, bool) {
+	r.m.Lock()
+	defer r.m.Unlock()
+	d, ok := r.details[id]
+	return d, ok
+}
+
+// request sends RequestContractData for the contract, blocking until all
+// matching contracts have arrived.
+func (r *ContractResolver) request(c Contract) ([]ContractDetails, error) {
+	id := r.eng.NextRequestID()
+	rc := make(chan Reply)
+	r.eng.Subscribe(rc, id)
+	defer r.eng.Unsubscribe(rc, id)
+
+	req := &RequestContractData{Contract: c}
+	req.SetID(id)
+	if err := r.eng.Send(req); err != nil {
+		return nil, err
+	}
+
+	var candidates []ContractDetails
+	timeout := time.After(resolveTimeout)
+	for {
+		select {
+		case reply := <-rc:
+			switch reply := reply.(type) {
+			case *ErrorMessage:
+				if reply.ID() != id || reply.SeverityWarning() {
+					continue
+				}
+				if reply.Code == errorNoSecurityDefinition {
+					return nil, nil
+				}
+				return nil, reply.Error()
+			case *ContractData:
+				candidates = append(candidates, reply.Contract)
+			case *ContractDataEnd:
+				return candidates, nil
+			}
+		case <-timeout:
+			return nil, fmt.Errorf("goib: no contract details for %s within %s", describeContract(c), resolveTimeout)
+		case <-r.eng.terminated:
+			return nil, fmt.Errorf("goib: engine exited before contract details for %s", describeContract(c))
+		}
+	}
+}
+
+// save writes the cache to the cache file, if any. The caller holds r.m.
+func (r *ContractResolver) save() error {
+	if r.path == "" {
+		return nil
+	}
+	data, err := json.Marshal(resolverCache{Contracts: r.details, Queries: r.queries})
+	if err != nil {
+		return err
+	}
+	// write then rename, so a crash never leaves a truncated cache
+	tmp := r.path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, r.path)
+}
@@ -134,11 +134,120 @@ const (
 	mStartAPI                                     = 71
 )
 
+func code2Req(code int64) (r Request, err error) {
+	switch code {
+	case int64(mStartAPI):
+		r = &StartAPI{}
+	case int64(mCancelScannerSubscription):
+		r = &CancelScannerSubscription{}
+	case int64(mRequestScannerParameters):
+		r = &RequestScannerParameters{}
+	case int64(mRequestScannerSubscription):
+		r = &RequestScannerSubscription{}
+	case int64(mRequestMarketData):
+		r = &RequestMarketData{}
+	case int64(mCancelHistoricalData):
+		r = &CancelHistoricalData{}
+	case int64(mCancelRealTimeBars):
+		r = &CancelRealTimeBars{}
+	case int64(mRequestHistoricalData):
+		r = &RequestHistoricalData{}
+	case int64(mRequestRealTimeBars):
+		r = &RequestRealTimeBars{}
+	case int64(mRequestContractData):
+		r = &RequestContractData{}
+	case int64(mRequestMarketDepth):
+		r = &RequestMarketDepth{}
+	case int64(mCancelMarketData):
+		r = &CancelMarketData{}
+	case int64(mCancelMarketDepth):
+		r = &CancelMarketDepth{}
+	case int64(mExerciseOptions):
+		r = &ExerciseOptions{}
+	case int64(mPlaceOrder):
+		r = &PlaceOrder{}
+	case int64(mRequestAccountData):
+		r = &RequestAccountUpdates{}
+	case int64(mRequestExecutions):
+		r = &RequestExecutions{}
+	case int64(mCancelOrder):
+		r = &CancelOrder{}
+	case int64(mRequestOpenOrders):
+		r = &RequestOpenOrders{}
+	case int64(mRequestIDs):
+		r = &RequestIDs{}
+	case int64(mRequestNewsBulletins):
+		r = &RequestNewsBulletins{}
+	case int64(mCancelNewsBulletins):
+		r = &CancelNewsBulletins{}
+	case int64(mSetServerLogLevel):
+		r = &SetServerLogLevel{}
+	case int64(mRequestAutoOpenOrders):
+		r = &RequestAutoOpenOrders{}
+	case int64(mRequestAllOpenOrders):
+		r = &RequestAllOpenOrders{}
+	case int64(mRequestManagedAccounts):
+		r = &RequestManagedAccounts{}
+	case int64(mRequestFA):
+		r = &RequestFA{}
+	case int64(mReplaceFA):
+		r = &ReplaceFA{}
+	case int64(mRequestCurrentTime):
+		r = &RequestCurrentTime{}
+	case int64(mRequestFundamentalData):
+		r = &RequestFundamentalData{}
+	case int64(mCancelFundamentalData):
+		r = &CancelFundamentalData{}
+	case int64(mRequestCalcImpliedVol):
+		r = &RequestCalcImpliedVol{}
+	case int64(mCancelCalcImpliedVol):
+		r = &CancelCalcImpliedVol{}
+	case int64(mRequestCalcOptionPrice):
+		r = &RequestCalcOptionPrice{}
+	case int64(mCancelCalcOptionPrice):
+		r = &CancelCalcOptionPrice{}
+	case int64(mRequestGlobalCancel):
+		r = &RequestGlobalCancel{}
+	case int64(mRequestMarketDataType):
+		r = &RequestMarketDataType{}
+	case int64(mRequestPositions):
+		r = &RequestPositions{}
+	case int64(mCancelPositions):
+		r = &CancelPositions{}
+	case int64(mRequestAccountSummary):
+		r = &RequestAccountSummary{}
+	case int64(mCancelAccountSummary):
+		r = &CancelAccountSummary{}
+	case int64(mVerifyRequest):
+		r = &VerifyRequest{}
+	case int64(mVerifyMessage):
+		r = &VerifyMessage{}
+	case int64(mQueryDisplayGroups):
+		r = &QueryDisplayGroups{}
+	case int64(mSubscribeToGroupEvents):
+		r = &SubscribeToGroupEvents{}
+	case int64(mUpdateDisplayGroup):
+		r = &UpdateDisplayGroup{}
+	case int64(mUnsubscribeFromGroupEvents):
+		r = &UnsubscribeFromGroupEvents{}
+	default:
+		err = fmt.Errorf("Unsupported outgoing message type %d", code)
+	}
+	return r, err
+}
+
 type serverHandshake struct {
 	version int64
 	time    time.Time
 }
 
+func (s *serverHandshake) write(b *bytes.Buffer) error {
+	if err := writeInt(b, s.version); err != nil {
+		return err
+	}
+	return writeTime(b, s.time, timeWriteLocalTime)
+}
+
 func (s *serverHandshake) read(b *bufio.Reader) error {
 	var err error
 
@@ -157,6 +266,7 @@ type StartAPI struct {
 func (s *StartAPI) code() OutgoingMessageID           { return mStartAPI }
 func (s *StartAPI) version() int64                    { return 1 }
 func (s *StartAPI) write(b *bytes.Buffer) (err error) { return writeInt(b, s.Client) }
+func (s *StartAPI) read(b *bufio.Reader) (err error)  { s.Client, err = readInt(b); return err }
 
 // CancelScannerSubscription is equivalent of IB API EClientSocket.cancelScannerSubscription().
 type CancelScannerSubscription struct {
@@ -171,6 +281,10 @@ func (c *CancelScannerSubscription) ID() int64                   { return c.id }
 func (c *CancelScannerSubscription) code() OutgoingMessageID     { return mCancelScannerSubscription }
 func (c *CancelScannerSubscription) version() int64              { return 1 }
 func (c *CancelScannerSubscription) write(b *bytes.Buffer) error { return writeInt(b, c.id) }
+func (c *CancelScannerSubscription) read(b *bufio.Reader) (err error) {
+	c.id, err = readInt(b)
+	return err
+}
 
 // RequestScannerParameters is equivalent of IB API EClientSocket.reqScannerParameters().
 type RequestScannerParameters struct{}
@@ -178,6 +292,7 @@ type RequestScannerParameters struct{}
 func (r *RequestScannerParameters) code() OutgoingMessageID     { return mRequestScannerParameters }
 func (r *RequestScannerParameters) version() int64              { return 1 }
 func (r *RequestScannerParameters) write(b *bytes.Buffer) error { return nil }
+func (r *RequestScannerParameters) read(b *bufio.Reader) error  { return nil }
 
 // RequestScannerSubscription is equivalent of IB API EClientSocket.reqScannerSubscription().
 type RequestScannerSubscription struct {
@@ -232,6 +347,77 @@ func (r *RequestScannerSubscription) write(b *bytes.Buffer) error {
 	return writeString(b, subOptions.String())
 }
 
+func (r *RequestScannerSubscription) read(b *bufio.Reader) (err error) {
+	if r.id, err = readInt(b); err != nil {
+		return err
+	}
+	if r.Subscription.NumberOfRows, err = readInt(b); err != nil {
+		return err
+	}
+	if r.Subscription.Instrument, err = readString(b); err != nil {
+		return err
+	}
+	if r.Subscription.LocationCode, err = readString(b); err != nil {
+		return err
+	}
+	if r.Subscription.ScanCode, err = readString(b); err != nil {
+		return err
+	}
+	if r.Subscription.AbovePrice, err = readFloat(b); err != nil {
+		return err
+	}
+	if r.Subscription.BelowPrice, err = readFloat(b); err != nil {
+		return err
+	}
+	if r.Subscription.AboveVolume, err = readInt(b); err != nil {
+		return err
+	}
+	if r.Subscription.MarketCapAbove, err = readFloat(b); err != nil {
+		return err
+	}
+	if r.Subscription.MarketCapBelow, err = readFloat(b); err != nil {
+		return err
+	}
+	if r.Subscription.MoodyRatingAbove, err = readString(b); err != nil {
+		return err
+	}
+	if r.Subscription.MoodyRatingBelow, err = readString(b); err != nil {
+		return err
+	}
+	if r.Subscription.SPRatingAbove, err = readString(b); err != nil {
+		return err
+	}
+	if r.Subscription.SPRatingBelow, err = readString(b); err != nil {
+		return err
+	}
+	if r.Subscription.MaturityDateAbove, err = readString(b); err != nil {
+		return err
+	}
+	if r.Subscription.MaturityDateBelow, err = readString(b); err != nil {
+		return err
+	}
+	if r.Subscription.CouponRateAbove, err = readFloat(b); err != nil {
+		return err
+	}
+	if r.Subscription.CouponRateBelow, err = readFloat(b); err != nil {
+		return err
+	}
+	if r.Subscription.ExcludeConvertible, err = readString(b); err != nil {
+		return err
+	}
+	if r.Subscription.AverageOptionVolumeAbove, err = readInt(b); err != nil {
+		return err
+	}
+	if r.Subscription.ScannerSettingPairs, err = readString(b); err != nil {
+		return err
+	}
+	if r.Subscription.StockTypeFilter, err = readString(b); err != nil {
+		return err
+	}
+	r.ScannerSubscriptionOptions, err = readTagValueList(b)
+	return err
+}
+
 // RequestMarketData is equivalent of IB API EClientSocket.reqMktData().
 type RequestMarketData struct {
 	id int64
@@ -314,6 +500,93 @@ func (r *RequestMarketData) write(b *bytes.Buffer) error {
 	return writeString(b, mktData.String())
 }
 
+func (r *RequestMarketData) read(b *bufio.Reader) (err error) {
+	if r.id, err = readInt(b); err != nil {
+		return err
+	}
+	if r.Contract.ContractID, err = readInt(b); err != nil {
+		return err
+	}
+	if r.Contract.Symbol, err = readString(b); err != nil {
+		return err
+	}
+	if r.Contract.SecurityType, err = readString(b); err != nil {
+		return err
+	}
+	if r.Contract.Expiry, err = readString(b); err != nil {
+		return err
+	}
+	if r.Contract.Strike, err = readFloat(b); err != nil {
+		return err
+	}
+	if r.Contract.Right, err = readString(b); err != nil {
+		return err
+	}
+	if r.Contract.Multiplier, err = readString(b); err != nil {
+		return err
+	}
+	if r.Contract.Exchange, err = readString(b); err != nil {
+		return err
+	}
+	if r.Contract.PrimaryExchange, err = readString(b); err != nil {
+		return err
+	}
+	if r.Contract.Currency, err = readString(b); err != nil {
+		return err
+	}
+	if r.Contract.LocalSymbol, err = readString(b); err != nil {
+		return err
+	}
+	if r.Contract.TradingClass, err = readString(b); err != nil {
+		return err
+	}
+	if r.Contract.SecurityType == bagSecType {
+		var size int64
+		if size, err = readInt(b); err != nil {
+			return err
+		}
+		r.ComboLegs = make([]ComboLeg, size)
+		for i := range r.ComboLegs {
+			if r.ComboLegs[i].ContractID, err = readInt(b); err != nil {
+				return err
+			}
+			if r.ComboLegs[i].Ratio, err = readInt(b); err != nil {
+				return err
+			}
+			if r.ComboLegs[i].Action, err = readString(b); err != nil {
+				return err
+			}
+			if r.ComboLegs[i].Exchange, err = readString(b); err != nil {
+				return err
+			}
+		}
+	}
+	var haveComp bool
+	if haveComp, err = readBool(b); err != nil {
+		return err
+	}
+	if haveComp {
+		r.Comp = new(UnderComp)
+		if r.Comp.ContractID, err = readInt(b); err != nil {
+			return err
+		}
+		if r.Comp.Delta, err = readFloat(b); err != nil {
+			return err
+		}
+		if r.Comp.Price, err = readFloat(b); err != nil {
+			return err
+		}
+	}
+	if r.GenericTickList, err = readString(b); err != nil {
+		return err
+	}
+	if r.Snapshot, err = readBool(b); err != nil {
+		return err
+	}
+	r.MarketDataOptions, err = readTagValueList(b)
+	return err
+}
+
 // CancelHistoricalData is equivalent of IB API EClientSocket.cancelHistoricalData().
 type CancelHistoricalData struct {
 	id int64
@@ -323,10 +596,11 @@ type CancelHistoricalData struct {
 func (c *CancelHistoricalData) SetID(id int64) { c.id = id }
 
 // ID .
-func (c *CancelHistoricalData) ID() int64                   { return c.id }
-func (c *CancelHistoricalData) code() OutgoingMessageID     { return mCancelHistoricalData }
-func (c *CancelHistoricalData) version() int64              { return 1 }
-func (c *CancelHistoricalData) write(b *bytes.Buffer) error { return writeInt(b, c.id) }
+func (c *CancelHistoricalData) ID() int64                        { return c.id }
+func (c *CancelHistoricalData) code() OutgoingMessageID          { return mCancelHistoricalData }
+func (c *CancelHistoricalData) version() int64                   { return 1 }
+func (c *CancelHistoricalData) write(b *bytes.Buffer) error      { return writeInt(b, c.id) }
+func (c *CancelHistoricalData) read(b *bufio.Reader) (err error) { c.id, err = readInt(b); return err }
 
 // CancelRealTimeBars is equivalent of IB API EClientSocket.cancelRealTimeBars().
 type CancelRealTimeBars struct {
@@ -337,10 +611,11 @@ type CancelRealTimeBars struct {
 func (c *CancelRealTimeBars) SetID(id int64) { c.id = id }
 
 // ID .
-func (c *CancelRealTimeBars) ID() int64                   { return c.id }
-func (c *CancelRealTimeBars) code() OutgoingMessageID     { return mCancelRealTimeBars }
-func (c *CancelRealTimeBars) version() int64              { return 1 }
-func (c *CancelRealTimeBars) write(b *bytes.Buffer) error { return writeInt(b, c.id) }
+func (c *CancelRealTimeBars) ID() int64                        { return c.id }
+func (c *CancelRealTimeBars) code() OutgoingMessageID          { return mCancelRealTimeBars }
+func (c *CancelRealTimeBars) version() int64                   { return 1 }
+func (c *CancelRealTimeBars) write(b *bytes.Buffer) error      { return writeInt(b, c.id) }
+func (c *CancelRealTimeBars) read(b *bufio.Reader) (err error) { c.id, err = readInt(b); return err }
 
 // RequestHistoricalData is equivalent of IB API EClientSocket.requestHistoricalData().
 type RequestHistoricalData struct {
@@ -402,6 +677,74 @@ func (r *RequestHistoricalData) write(b *bytes.Buffer) error {
 	return writeString(b, mktData.String())
 }
 
+func (r *RequestHistoricalData) read(b *bufio.Reader) (err error) {
+	if r.id, err = readInt(b); err != nil {
+		return err
+	}
+	if r.Contract.ContractID, err = readInt(b); err != nil {
+		return err
+	}
+	if r.Contract.Symbol, err = readString(b); err != nil {
+		return err
+	}
+	if r.Contract.SecurityType, err = readString(b); err != nil {
+		return err
+	}
+	if r.Contract.Expiry, err = readString(b); err != nil {
+		return err
+	}
+	if r.Contract.Strike, err = readFloat(b); err != nil {
+		return err
+	}
+	if r.Contract.Right, err = readString(b); err != nil {
+		return err
+	}
+	if r.Contract.Multiplier, err = readString(b); err != nil {
+		return err
+	}
+	if r.Contract.Exchange, err = readString(b); err != nil {
+		return err
+	}
+	if r.Contract.PrimaryExchange, err = readString(b); err != nil {
+		return err
+	}
+	if r.Contract.Currency, err = readString(b); err != nil {
+		return err
+	}
+	if r.Contract.LocalSymbol, err = readString(b); err != nil {
+		return err
+	}
+	if r.Contract.TradingClass, err = readString(b); err != nil {
+		return err
+	}
+	if r.IncludeExpired, err = readBool(b); err != nil {
+		return err
+	}
+	if r.EndDateTime, err = readTime(b, timeReadUTC); err != nil {
+		return err
+	}
+	var barSize, whatToShow string
+	if barSize, err = readString(b); err != nil {
+		return err
+	}
+	r.BarSize = HistDataBarSize(barSize)
+	if r.Duration, err = readString(b); err != nil {
+		return err
+	}
+	if r.UseRTH, err = readBool(b); err != nil {
+		return err
+	}
+	if whatToShow, err = readString(b); err != nil {
+		return err
+	}
+	r.WhatToShow = HistDataToShow(whatToShow)
+	if _, err = readInt(b); err != nil {
+		return err
+	} // formatDate
+	r.ChartOptions, err = readTagValueList(b)
+	return err
+}
+
 // RequestRealTimeBars is equivalent of IB API EClientSocket.reqRealTimeBars().
 type RequestRealTimeBars struct {
 	id                 int64
@@ -451,6 +794,61 @@ func (r *RequestRealTimeBars) write(b *bytes.Buffer) error {
 	return writeString(b, barOption.String())
 }
 
+func (r *RequestRealTimeBars) read(b *bufio.Reader) (err error) {
+	if r.id, err = readInt(b); err != nil {
+		return err
+	}
+	if r.Contract.ContractID, err = readInt(b); err != nil {
+		return err
+	}
+	if r.Contract.Symbol, err = readString(b); err != nil {
+		return err
+	}
+	if r.Contract.SecurityType, err = readString(b); err != nil {
+		return err
+	}
+	if r.Contract.Expiry, err = readString(b); err != nil {
+		return err
+	}
+	if r.Contract.Strike, err = readFloat(b); err != nil {
+		return err
+	}
+	if r.Contract.Right, err = readString(b); err != nil {
+		return err
+	}
+	if r.Contract.Multiplier, err = readString(b); err != nil {
+		return err
+	}
+	if r.Contract.Exchange, err = readString(b); err != nil {
+		return err
+	}
+	if r.Contract.PrimaryExchange, err = readString(b); err != nil {
+		return err
+	}
+	if r.Contract.Currency, err = readString(b); err != nil {
+		return err
+	}
+	if r.Contract.LocalSymbol, err = readString(b); err != nil {
+		return err
+	}
+	if r.Contract.TradingClass, err = readString(b); err != nil {
+		return err
+	}
+	if r.BarSize, err = readInt(b); err != nil {
+		return err
+	}
+	var whatToShow string
+	if whatToShow, err = readString(b); err != nil {
+		return err
+	}
+	r.WhatToShow = RealTimeBarToShow(whatToShow)
+	if r.UseRTH, err = readBool(b); err != nil {
+		return err
+	}
+	r.RealTimeBarOptions, err = readTagValueList(b)
+	return err
+}
+
 // RequestContractData is equivalent of IB API EClientSocket.reqContractDetails().
 type RequestContractData struct {
 	id       int64
@@ -487,6 +885,53 @@ func (r *RequestContractData) write(b *bytes.Buffer) error {
 	return nil
 }
 
+func (r *RequestContractData) read(b *bufio.Reader) (err error) {
+	if r.id, err = readInt(b); err != nil {
+		return err
+	}
+	if r.Contract.ContractID, err = readInt(b); err != nil {
+		return err
+	}
+	if r.Contract.Symbol, err = readString(b); err != nil {
+		return err
+	}
+	if r.Contract.SecurityType, err = readString(b); err != nil {
+		return err
+	}
+	if r.Contract.Expiry, err = readString(b); err != nil {
+		return err
+	}
+	if r.Contract.Strike, err = readFloat(b); err != nil {
+		return err
+	}
+	if r.Contract.Right, err = readString(b); err != nil {
+		return err
+	}
+	if r.Contract.Multiplier, err = readString(b); err != nil {
+		return err
+	}
+	if r.Contract.Exchange, err = readString(b); err != nil {
+		return err
+	}
+	if r.Contract.Currency, err = readString(b); err != nil {
+		return err
+	}
+	if r.Contract.LocalSymbol, err = readString(b); err != nil {
+		return err
+	}
+	if r.Contract.TradingClass, err = readString(b); err != nil {
+		return err
+	}
+	if r.Contract.IncludeExpired, err = readBool(b); err != nil {
+		return err
+	}
+	if r.Contract.SecIDType, err = readString(b); err != nil {
+		return err
+	}
+	r.Contract.SecID, err = readString(b)
+	return err
+}
+
 // RequestMarketDepth is equivalent of IB API EClientSocket.reqMktDepth().
 type RequestMarketDepth struct {
 	id      int64
@@ -531,6 +976,50 @@ func (r *RequestMarketDepth) write(b *bytes.Buffer) error {
 	return writeString(b, mktDepth.String())
 }
 
+func (r *RequestMarketDepth) read(b *bufio.Reader) (err error) {
+	if r.id, err = readInt(b); err != nil {
+		return err
+	}
+	if r.Contract.ContractID, err = readInt(b); err != nil {
+		return err
+	}
+	if r.Contract.Symbol, err = readString(b); err != nil {
+		return err
+	}
+	if r.Contract.SecurityType, err = readString(b); err != nil {
+		return err
+	}
+	if r.Contract.Expiry, err = readString(b); err != nil {
+		return err
+	}
+	if r.Contract.Strike, err = readFloat(b); err != nil {
+		return err
+	}
+	if r.Contract.Right, err = readString(b); err != nil {
+		return err
+	}
+	if r.Contract.Multiplier, err = readString(b); err != nil {
+		return err
+	}
+	if r.Contract.Exchange, err = readString(b); err != nil {
+		return err
+	}
+	if r.Contract.Currency, err = readString(b); err != nil {
+		return err
+	}
+	if r.Contract.LocalSymbol, err = readString(b); err != nil {
+		return err
+	}
+	if r.Contract.TradingClass, err = readString(b); err != nil {
+		return err
+	}
+	if r.NumRows, err = readInt(b); err != nil {
+		return err
+	}
+	r.MarketDepthOptions, err = readTagValueList(b)
+	return err
+}
+
 // CancelMarketData is equivalent of IB API EClientSocket.cancelMktData().
 type CancelMarketData struct {
 	id int64
@@ -540,10 +1029,11 @@ type CancelMarketData struct {
 func (c *CancelMarketData) SetID(id int64) { c.id = id }
 
 // ID .
-func (c *CancelMarketData) ID() int64                   { return c.id }
-func (c *CancelMarketData) code() OutgoingMessageID     { return mCancelMarketData }
-func (c *CancelMarketData) version() int64              { return 1 }
-func (c *CancelMarketData) write(b *bytes.Buffer) error { return writeInt(b, c.id) }
+func (c *CancelMarketData) ID() int64                        { return c.id }
+func (c *CancelMarketData) code() OutgoingMessageID          { return mCancelMarketData }
+func (c *CancelMarketData) version() int64                   { return 1 }
+func (c *CancelMarketData) write(b *bytes.Buffer) error      { return writeInt(b, c.id) }
+func (c *CancelMarketData) read(b *bufio.Reader) (err error) { c.id, err = readInt(b); return err }
 
 // CancelMarketDepth is equivalent of IB API EClientSocket.cancelMktDepth().
 type CancelMarketDepth struct {
@@ -554,10 +1044,11 @@ type CancelMarketDepth struct {
 func (c *CancelMarketDepth) SetID(id int64) { c.id = id }
 
 // ID .
-func (c *CancelMarketDepth) ID() int64                   { return c.id }
-func (c *CancelMarketDepth) code() OutgoingMessageID     { return mCancelMarketDepth }
-func (c *CancelMarketDepth) version() int64              { return 1 }
-func (c *CancelMarketDepth) write(b *bytes.Buffer) error { return writeInt(b, c.id) }
+func (c *CancelMarketDepth) ID() int64                        { return c.id }
+func (c *CancelMarketDepth) code() OutgoingMessageID          { return mCancelMarketDepth }
+func (c *CancelMarketDepth) version() int64                   { return 1 }
+func (c *CancelMarketDepth) write(b *bytes.Buffer) error      { return writeInt(b, c.id) }
+func (c *CancelMarketDepth) read(b *bufio.Reader) (err error) { c.id, err = readInt(b); return err }
 
 // ExerciseOptions is equivalent of IB API EClientSocket.exerciseOptions().
 type ExerciseOptions struct {
@@ -600,13 +1091,63 @@ func (r *ExerciseOptions) write(b *bytes.Buffer) error {
 	return nil
 }
 
-// PlaceOrder is equivalent of IB API EClientSocket.placeOrder().
-type PlaceOrder struct {
-	id int64
-	Contract
-	Order
-}
-
+func (r *ExerciseOptions) read(b *bufio.Reader) (err error) {
+	if r.id, err = readInt(b); err != nil {
+		return err
+	}
+	if r.Contract.ContractID, err = readInt(b); err != nil {
+		return err
+	}
+	if r.Contract.Symbol, err = readString(b); err != nil {
+		return err
+	}
+	if r.Contract.SecurityType, err = readString(b); err != nil {
+		return err
+	}
+	if r.Contract.Expiry, err = readString(b); err != nil {
+		return err
+	}
+	if r.Contract.Strike, err = readFloat(b); err != nil {
+		return err
+	}
+	if r.Contract.Right, err = readString(b); err != nil {
+		return err
+	}
+	if r.Contract.Multiplier, err = readString(b); err != nil {
+		return err
+	}
+	if r.Contract.Exchange, err = readString(b); err != nil {
+		return err
+	}
+	if r.Contract.Currency, err = readString(b); err != nil {
+		return err
+	}
+	if r.Contract.LocalSymbol, err = readString(b); err != nil {
+		return err
+	}
+	if r.Contract.TradingClass, err = readString(b); err != nil {
+		return err
+	}
+	if r.ExerciseAction, err = readInt(b); err != nil {
+		return err
+	}
+	if r.ExerciseQuantity, err = readInt(b); err != nil {
+		return err
+	}
+	if r.Account, err = readString(b); err != nil {
+		return err
+	}
+	r.Override, err = readInt(b)
+	return err
+}
+
+// PlaceOrder is equivalent of IB API EClientSocket.placeOrder().
+type PlaceOrder struct {
+	id int64
+	Contract
+	Order
+}
+
 // SetID assigns the TWS "reqId", which is used for reply correlation and request cancellation.
 func (r *PlaceOrder) SetID(id int64) { r.id = id }
 
@@ -693,6 +1234,9 @@ func (r *PlaceOrder) write(b *bytes.Buffer) error {
 			}
 		}
 
+		if err := writeInt(b, int64(len(r.Order.SmartComboRoutingParams))); err != nil {
+			return err
+		}
 		if len(r.Order.SmartComboRoutingParams) > 0 {
 			for _, tv := range r.Order.SmartComboRoutingParams {
 				if err := writeString(b, tv.Tag); err != nil {
@@ -704,265 +1248,658 @@ func (r *PlaceOrder) write(b *bytes.Buffer) error {
 			}
 		}
 	}
-
-	// send deprecated sharesAllocation field
-	if err := writeString(b, ""); err != nil {
+
+	// send deprecated sharesAllocation field
+	if err := writeString(b, ""); err != nil {
+		return err
+	}
+
+	if err := writeFloat(b, r.Order.DiscretionaryAmount); err != nil {
+		return err
+	}
+	if err := writeString(b, r.Order.GoodAfterTime); err != nil {
+		return err
+	}
+	if err := writeString(b, r.Order.GoodTillDate); err != nil {
+		return err
+	}
+	if err := writeString(b, r.Order.FAGroup); err != nil {
+		return err
+	}
+	if err := writeString(b, r.Order.FAMethod); err != nil {
+		return err
+	}
+	if err := writeString(b, r.Order.FAPercentage); err != nil {
+		return err
+	}
+	if err := writeString(b, r.Order.FAProfile); err != nil {
+		return err
+	}
+
+	// institutional short sale slot fields.
+	if err := writeInt(b, r.Order.ShortSaleSlot); err != nil { // 0 only for retail, 1 or 2 only for institution.
+		return err
+	}
+	if err := writeString(b, r.Order.DesignatedLocation); err != nil { // only populate whenb, r.Order.m_shortSaleSlot = 2.
+		return err
+	}
+	if err := writeInt(b, r.Order.ExemptCode); err != nil {
+		return err
+	}
+	if err := writeInt(b, r.Order.OCAType); err != nil {
+		return err
+	}
+	if err := writeString(b, r.Order.Rule80A); err != nil {
+		return err
+	}
+	if err := writeString(b, r.Order.SettlingFirm); err != nil {
+		return err
+	}
+	if err := writeBool(b, r.Order.AllOrNone); err != nil {
+		return err
+	}
+	if err := writeMaxInt(b, r.Order.MinQty); err != nil {
+		return err
+	}
+	if err := writeMaxFloat(b, r.Order.PercentOffset); err != nil {
+		return err
+	}
+	if err := writeInt(b, r.Order.ETradeOnly); err != nil {
+		return err
+	}
+	if err := writeBool(b, r.Order.FirmQuoteOnly); err != nil {
+		return err
+	}
+	if err := writeMaxFloat(b, r.Order.NBBOPriceCap); err != nil {
+		return err
+	}
+	if err := writeMaxInt(b, r.Order.AuctionStrategy); err != nil {
+		return err
+	}
+	if err := writeMaxFloat(b, r.Order.StartingPrice); err != nil {
+		return err
+	}
+	if err := writeMaxFloat(b, r.Order.StockRefPrice); err != nil {
+		return err
+	}
+	if err := writeMaxFloat(b, r.Order.Delta); err != nil {
+		return err
+	}
+	if err := writeMaxFloat(b, r.Order.StockRangeLower); err != nil {
+		return err
+	}
+	if err := writeMaxFloat(b, r.Order.StockRangeUpper); err != nil {
+		return err
+	}
+
+	if err := writeBool(b, r.Order.OverridePercentageConstraints); err != nil {
+		return err
+	}
+
+	if err := writeMaxFloat(b, r.Order.Volatility); err != nil {
+		return err
+	}
+	if err := writeMaxInt(b, r.Order.VolatilityType); err != nil {
+		return err
+	}
+
+	if err := writeString(b, r.Order.DeltaNeutralOrderType); err != nil {
+		return err
+	}
+	if err := writeMaxFloat(b, r.Order.DeltaNeutralAuxPrice); err != nil {
+		return err
+	}
+
+	if r.Order.DeltaNeutralOrderType != "" {
+		if err := writeInt(b, r.Order.DeltaNeutral.ContractID); err != nil {
+			return err
+		}
+		if err := writeString(b, r.Order.DeltaNeutral.SettlingFirm); err != nil {
+			return err
+		}
+		if err := writeString(b, r.Order.DeltaNeutral.ClearingAccount); err != nil {
+			return err
+		}
+		if err := writeString(b, r.Order.DeltaNeutral.ClearingIntent); err != nil {
+			return err
+		}
+		if err := writeString(b, r.Order.DeltaNeutral.OpenClose); err != nil {
+			return err
+		}
+		if err := writeBool(b, r.Order.DeltaNeutral.ShortSale); err != nil {
+			return err
+		}
+		if err := writeInt(b, r.Order.DeltaNeutral.ShortSaleSlot); err != nil {
+			return err
+		}
+		if err := writeString(b, r.Order.DeltaNeutral.DesignatedLocation); err != nil {
+			return err
+		}
+	}
+
+	if err := writeInt(b, r.Order.ContinuousUpdate); err != nil {
+		return err
+	}
+	if err := writeMaxInt(b, r.Order.ReferencePriceType); err != nil {
+		return err
+	}
+	if err := writeMaxFloat(b, r.Order.TrailStopPrice); err != nil {
+		return err
+	}
+	if err := writeMaxFloat(b, r.Order.TrailingPercent); err != nil {
+		return err
+	}
+
+	if err := writeMaxInt(b, r.Order.ScaleInitLevelSize); err != nil {
+		return err
+	}
+	if err := writeMaxInt(b, r.Order.ScaleSubsLevelSize); err != nil {
+		return err
+	}
+	if err := writeMaxFloat(b, r.Order.ScalePriceIncrement); err != nil {
+		return err
+	}
+
+	if r.Order.ScalePriceIncrement > 0.0 && r.Order.ScalePriceIncrement != math.MaxFloat64 {
+		if err := writeMaxFloat(b, r.Order.ScalePriceAdjustValue); err != nil {
+			return err
+		}
+		if err := writeMaxInt(b, r.Order.ScalePriceAdjustInterval); err != nil {
+			return err
+		}
+		if err := writeMaxFloat(b, r.Order.ScaleProfitOffset); err != nil {
+			return err
+		}
+		if err := writeBool(b, r.Order.ScaleAutoReset); err != nil {
+			return err
+		}
+		if err := writeMaxInt(b, r.Order.ScaleInitPosition); err != nil {
+			return err
+		}
+		if err := writeMaxInt(b, r.Order.ScaleInitFillQty); err != nil {
+			return err
+		}
+		if err := writeBool(b, r.Order.ScaleRandomPercent); err != nil {
+			return err
+		}
+	}
+
+	if err := writeString(b, r.Order.ScaleTable); err != nil {
+		return err
+	}
+	if err := writeString(b, r.Order.ActiveStartTime); err != nil {
+		return err
+	}
+	if err := writeString(b, r.Order.ActiveStopTime); err != nil {
+		return err
+	}
+
+	if err := writeString(b, r.Order.HedgeType); err != nil {
+		return err
+	}
+	if len(r.Order.HedgeType) > 0 {
+		if err := writeString(b, r.Order.HedgeParam); err != nil {
+			return err
+		}
+	}
+
+	if err := writeBool(b, r.Order.OptOutSmartRouting); err != nil {
+		return err
+	}
+
+	if err := writeString(b, r.Order.ClearingAccount); err != nil {
+		return err
+	}
+	if err := writeString(b, r.Order.ClearingIntent); err != nil {
+		return err
+	}
+
+	if err := writeBool(b, r.Order.NotHeld); err != nil {
+		return err
+	}
+
+	if r.Contract.UnderComp != nil {
+		if err := writeBool(b, true); err != nil {
+			return err
+		}
+		if err := writeInt(b, r.Contract.UnderComp.ContractID); err != nil {
+			return err
+		}
+		if err := writeFloat(b, r.Contract.UnderComp.Delta); err != nil {
+			return err
+		}
+		if err := writeFloat(b, r.Contract.UnderComp.Price); err != nil {
+			return err
+		}
+	} else {
+		if err := writeBool(b, false); err != nil {
+			return err
+		}
+	}
+
+	if err := writeString(b, r.Order.AlgoStrategy); err != nil {
+		return err
+	}
+	if len(r.Order.AlgoStrategy) > 0 {
+		if err := writeInt(b, int64(len(r.Order.AlgoParams.Params))); err != nil {
+			return err
+		}
+		for _, tv := range r.Order.AlgoParams.Params {
+			if err := writeString(b, tv.Tag); err != nil {
+				return err
+			}
+			if err := writeString(b, tv.Value); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := writeBool(b, r.Order.WhatIf); err != nil {
+		return err
+	}
+
+	var miscOptions bytes.Buffer
+	miscOptions.WriteString("")
+	for _, opt := range r.Order.OrderMiscOptions {
+		miscOptions.WriteString(opt.Tag)
+		miscOptions.WriteString("=")
+		miscOptions.WriteString(opt.Value)
+		miscOptions.WriteString(";")
+	}
+	return writeString(b, miscOptions.String())
+}
+
+func (r *PlaceOrder) read(b *bufio.Reader) (err error) {
+	if r.id, err = readInt(b); err != nil {
+		return err
+	}
+	if r.Contract.ContractID, err = readInt(b); err != nil {
+		return err
+	}
+	if r.Contract.Symbol, err = readString(b); err != nil {
+		return err
+	}
+	if r.Contract.SecurityType, err = readString(b); err != nil {
+		return err
+	}
+	if r.Contract.Expiry, err = readString(b); err != nil {
+		return err
+	}
+	if r.Contract.Strike, err = readFloat(b); err != nil {
+		return err
+	}
+	if r.Contract.Right, err = readString(b); err != nil {
+		return err
+	}
+	if r.Contract.Multiplier, err = readString(b); err != nil {
+		return err
+	}
+	if r.Contract.Exchange, err = readString(b); err != nil {
+		return err
+	}
+	if r.Contract.PrimaryExchange, err = readString(b); err != nil {
+		return err
+	}
+	if r.Contract.Currency, err = readString(b); err != nil {
+		return err
+	}
+	if r.Contract.LocalSymbol, err = readString(b); err != nil {
+		return err
+	}
+	if r.Contract.TradingClass, err = readString(b); err != nil {
+		return err
+	}
+	if r.Contract.SecIDType, err = readString(b); err != nil {
+		return err
+	}
+	if r.Contract.SecID, err = readString(b); err != nil {
+		return err
+	}
+	if r.Order.Action, err = readString(b); err != nil {
+		return err
+	}
+	if r.Order.TotalQty, err = readInt(b); err != nil {
+		return err
+	}
+	if r.Order.OrderType, err = readString(b); err != nil {
+		return err
+	}
+	if r.Order.LimitPrice, err = readFloat(b); err != nil {
+		return err
+	}
+	if r.Order.AuxPrice, err = readFloat(b); err != nil {
+		return err
+	}
+	if r.Order.TIF, err = readString(b); err != nil {
+		return err
+	}
+	if r.Order.OCAGroup, err = readString(b); err != nil {
+		return err
+	}
+	if r.Order.Account, err = readString(b); err != nil {
+		return err
+	}
+	if r.Order.OpenClose, err = readString(b); err != nil {
+		return err
+	}
+	if r.Order.Origin, err = readInt(b); err != nil {
+		return err
+	}
+	if r.Order.OrderRef, err = readString(b); err != nil {
+		return err
+	}
+	if r.Order.Transmit, err = readBool(b); err != nil {
+		return err
+	}
+	if r.Order.ParentID, err = readInt(b); err != nil {
+		return err
+	}
+	if r.Order.BlockOrder, err = readBool(b); err != nil {
+		return err
+	}
+	if r.Order.SweepToFill, err = readBool(b); err != nil {
+		return err
+	}
+	if r.Order.DisplaySize, err = readInt(b); err != nil {
+		return err
+	}
+	if r.Order.TriggerMethod, err = readInt(b); err != nil {
+		return err
+	}
+	if r.Order.OutsideRTH, err = readBool(b); err != nil {
+		return err
+	}
+	if r.Order.Hidden, err = readBool(b); err != nil {
+		return err
+	}
+	if r.Contract.SecurityType == bagSecType {
+		var size int64
+		if size, err = readInt(b); err != nil {
+			return err
+		}
+		r.Contract.ComboLegs = make([]ComboLeg, size)
+		for i := range r.Contract.ComboLegs {
+			cl := &r.Contract.ComboLegs[i]
+			if cl.ContractID, err = readInt(b); err != nil {
+				return err
+			}
+			if cl.Ratio, err = readInt(b); err != nil {
+				return err
+			}
+			if cl.Action, err = readString(b); err != nil {
+				return err
+			}
+			if cl.Exchange, err = readString(b); err != nil {
+				return err
+			}
+			if cl.OpenClose, err = readInt(b); err != nil {
+				return err
+			}
+			if cl.ShortSaleSlot, err = readInt(b); err != nil {
+				return err
+			}
+			if cl.DesignatedLocation, err = readString(b); err != nil {
+				return err
+			}
+			if cl.ExemptCode, err = readInt(b); err != nil {
+				return err
+			}
+		}
+		if size, err = readInt(b); err != nil {
+			return err
+		}
+		r.Order.OrderComboLegs = make([]OrderComboLeg, size)
+		for i := range r.Order.OrderComboLegs {
+			if r.Order.OrderComboLegs[i].Price, err = readFloat(b); err != nil {
+				return err
+			}
+		}
+		if size, err = readInt(b); err != nil {
+			return err
+		}
+		r.Order.SmartComboRoutingParams = make([]TagValue, size)
+		for i := range r.Order.SmartComboRoutingParams {
+			if r.Order.SmartComboRoutingParams[i].Tag, err = readString(b); err != nil {
+				return err
+			}
+			if r.Order.SmartComboRoutingParams[i].Value, err = readString(b); err != nil {
+				return err
+			}
+		}
+	}
+	if _, err = readString(b); err != nil {
 		return err
-	}
-
-	if err := writeFloat(b, r.Order.DiscretionaryAmount); err != nil {
+	} // deprecated sharesAllocation field
+	if r.Order.DiscretionaryAmount, err = readFloat(b); err != nil {
 		return err
 	}
-	if err := writeString(b, r.Order.GoodAfterTime); err != nil {
+	if r.Order.GoodAfterTime, err = readString(b); err != nil {
 		return err
 	}
-	if err := writeString(b, r.Order.GoodTillDate); err != nil {
+	if r.Order.GoodTillDate, err = readString(b); err != nil {
 		return err
 	}
-	if err := writeString(b, r.Order.FAGroup); err != nil {
+	if r.Order.FAGroup, err = readString(b); err != nil {
 		return err
 	}
-	if err := writeString(b, r.Order.FAMethod); err != nil {
+	if r.Order.FAMethod, err = readString(b); err != nil {
 		return err
 	}
-	if err := writeString(b, r.Order.FAPercentage); err != nil {
+	if r.Order.FAPercentage, err = readString(b); err != nil {
 		return err
 	}
-	if err := writeString(b, r.Order.FAProfile); err != nil {
+	if r.Order.FAProfile, err = readString(b); err != nil {
 		return err
 	}
-
-	// institutional short sale slot fields.
-	if err := writeInt(b, r.Order.ShortSaleSlot); err != nil { // 0 only for retail, 1 or 2 only for institution.
+	if r.Order.ShortSaleSlot, err = readInt(b); err != nil {
 		return err
 	}
-	if err := writeString(b, r.Order.DesignatedLocation); err != nil { // only populate whenb, r.Order.m_shortSaleSlot = 2.
+	if r.Order.DesignatedLocation, err = readString(b); err != nil {
 		return err
 	}
-	if err := writeInt(b, r.Order.ExemptCode); err != nil {
+	if r.Order.ExemptCode, err = readInt(b); err != nil {
 		return err
 	}
-	if err := writeInt(b, r.Order.OCAType); err != nil {
+	if r.Order.OCAType, err = readInt(b); err != nil {
 		return err
 	}
-	if err := writeString(b, r.Order.Rule80A); err != nil {
+	if r.Order.Rule80A, err = readString(b); err != nil {
 		return err
 	}
-	if err := writeString(b, r.Order.SettlingFirm); err != nil {
+	if r.Order.SettlingFirm, err = readString(b); err != nil {
 		return err
 	}
-	if err := writeBool(b, r.Order.AllOrNone); err != nil {
+	if r.Order.AllOrNone, err = readBool(b); err != nil {
 		return err
 	}
-	if err := writeMaxInt(b, r.Order.MinQty); err != nil {
+	if r.Order.MinQty, err = readInt(b); err != nil {
 		return err
 	}
-	if err := writeMaxFloat(b, r.Order.PercentOffset); err != nil {
+	if r.Order.PercentOffset, err = readFloat(b); err != nil {
 		return err
 	}
-	if err := writeInt(b, r.Order.ETradeOnly); err != nil {
+	if r.Order.ETradeOnly, err = readInt(b); err != nil {
 		return err
 	}
-	if err := writeBool(b, r.Order.FirmQuoteOnly); err != nil {
+	if r.Order.FirmQuoteOnly, err = readBool(b); err != nil {
 		return err
 	}
-	if err := writeMaxFloat(b, r.Order.NBBOPriceCap); err != nil {
+	if r.Order.NBBOPriceCap, err = readFloat(b); err != nil {
 		return err
 	}
-	if err := writeMaxInt(b, r.Order.AuctionStrategy); err != nil {
+	if r.Order.AuctionStrategy, err = readInt(b); err != nil {
 		return err
 	}
-	if err := writeMaxFloat(b, r.Order.StartingPrice); err != nil {
+	if r.Order.StartingPrice, err = readFloat(b); err != nil {
 		return err
 	}
-	if err := writeMaxFloat(b, r.Order.StockRefPrice); err != nil {
+	if r.Order.StockRefPrice, err = readFloat(b); err != nil {
 		return err
 	}
-	if err := writeMaxFloat(b, r.Order.Delta); err != nil {
+	if r.Order.Delta, err = readFloat(b); err != nil {
 		return err
 	}
-	if err := writeMaxFloat(b, r.Order.StockRangeLower); err != nil {
+	if r.Order.StockRangeLower, err = readFloat(b); err != nil {
 		return err
 	}
-	if err := writeMaxFloat(b, r.Order.StockRangeUpper); err != nil {
+	if r.Order.StockRangeUpper, err = readFloat(b); err != nil {
 		return err
 	}
-
-	if err := writeBool(b, r.Order.OverridePercentageConstraints); err != nil {
+	if r.Order.OverridePercentageConstraints, err = readBool(b); err != nil {
 		return err
 	}
-
-	if err := writeMaxFloat(b, r.Order.Volatility); err != nil {
+	if r.Order.Volatility, err = readFloat(b); err != nil {
 		return err
 	}
-	if err := writeMaxInt(b, r.Order.VolatilityType); err != nil {
+	if r.Order.VolatilityType, err = readInt(b); err != nil {
 		return err
 	}
-
-	if err := writeString(b, r.Order.DeltaNeutralOrderType); err != nil {
+	if r.Order.DeltaNeutralOrderType, err = readString(b); err != nil {
 		return err
 	}
-	if err := writeMaxFloat(b, r.Order.DeltaNeutralAuxPrice); err != nil {
+	if r.Order.DeltaNeutralAuxPrice, err = readFloat(b); err != nil {
 		return err
 	}
-
 	if r.Order.DeltaNeutralOrderType != "" {
-		if err := writeInt(b, r.Order.DeltaNeutral.ContractID); err != nil {
+		if r.Order.DeltaNeutral.ContractID, err = readInt(b); err != nil {
 			return err
 		}
-		if err := writeString(b, r.Order.DeltaNeutral.SettlingFirm); err != nil {
+		if r.Order.DeltaNeutral.SettlingFirm, err = readString(b); err != nil {
 			return err
 		}
-		if err := writeString(b, r.Order.DeltaNeutral.ClearingAccount); err != nil {
+		if r.Order.DeltaNeutral.ClearingAccount, err = readString(b); err != nil {
 			return err
 		}
-		if err := writeString(b, r.Order.DeltaNeutral.ClearingIntent); err != nil {
+		if r.Order.DeltaNeutral.ClearingIntent, err = readString(b); err != nil {
 			return err
 		}
-		if err := writeString(b, r.Order.DeltaNeutral.OpenClose); err != nil {
+		if r.Order.DeltaNeutral.OpenClose, err = readString(b); err != nil {
 			return err
 		}
-		if err := writeBool(b, r.Order.DeltaNeutral.ShortSale); err != nil {
+		if r.Order.DeltaNeutral.ShortSale, err = readBool(b); err != nil {
 			return err
 		}
-		if err := writeInt(b, r.Order.DeltaNeutral.ShortSaleSlot); err != nil {
+		if r.Order.DeltaNeutral.ShortSaleSlot, err = readInt(b); err != nil {
 			return err
 		}
-		if err := writeString(b, r.Order.DeltaNeutral.DesignatedLocation); err != nil {
+		if r.Order.DeltaNeutral.DesignatedLocation, err = readString(b); err != nil {
 			return err
 		}
 	}
-
-	if err := writeInt(b, r.Order.ContinuousUpdate); err != nil {
+	if r.Order.ContinuousUpdate, err = readInt(b); err != nil {
 		return err
 	}
-	if err := writeMaxInt(b, r.Order.ReferencePriceType); err != nil {
+	if r.Order.ReferencePriceType, err = readInt(b); err != nil {
 		return err
 	}
-	if err := writeMaxFloat(b, r.Order.TrailStopPrice); err != nil {
+	if r.Order.TrailStopPrice, err = readFloat(b); err != nil {
 		return err
 	}
-	if err := writeMaxFloat(b, r.Order.TrailingPercent); err != nil {
+	if r.Order.TrailingPercent, err = readFloat(b); err != nil {
 		return err
 	}
-
-	if err := writeMaxInt(b, r.Order.ScaleInitLevelSize); err != nil {
+	if r.Order.ScaleInitLevelSize, err = readInt(b); err != nil {
 		return err
 	}
-	if err := writeMaxInt(b, r.Order.ScaleSubsLevelSize); err != nil {
+	if r.Order.ScaleSubsLevelSize, err = readInt(b); err != nil {
 		return err
 	}
-	if err := writeMaxFloat(b, r.Order.ScalePriceIncrement); err != nil {
+	if r.Order.ScalePriceIncrement, err = readFloat(b); err != nil {
 		return err
 	}
-
 	if r.Order.ScalePriceIncrement > 0.0 && r.Order.ScalePriceIncrement != math.MaxFloat64 {
-		if err := writeMaxFloat(b, r.Order.ScalePriceAdjustValue); err != nil {
+		if r.Order.ScalePriceAdjustValue, err = readFloat(b); err != nil {
 			return err
 		}
-		if err := writeMaxInt(b, r.Order.ScalePriceAdjustInterval); err != nil {
+		if r.Order.ScalePriceAdjustInterval, err = readInt(b); err != nil {
 			return err
 		}
-		if err := writeMaxFloat(b, r.Order.ScaleProfitOffset); err != nil {
+		if r.Order.ScaleProfitOffset, err = readFloat(b); err != nil {
 			return err
 		}
-		if err := writeBool(b, r.Order.ScaleAutoReset); err != nil {
+		if r.Order.ScaleAutoReset, err = readBool(b); err != nil {
 			return err
 		}
-		if err := writeMaxInt(b, r.Order.ScaleInitPosition); err != nil {
+		if r.Order.ScaleInitPosition, err = readInt(b); err != nil {
 			return err
 		}
-		if err := writeMaxInt(b, r.Order.ScaleInitFillQty); err != nil {
+		if r.Order.ScaleInitFillQty, err = readInt(b); err != nil {
 			return err
 		}
-		if err := writeBool(b, r.Order.ScaleRandomPercent); err != nil {
+		if r.Order.ScaleRandomPercent, err = readBool(b); err != nil {
 			return err
 		}
 	}
-
-	if err := writeString(b, r.Order.ScaleTable); err != nil {
+	if r.Order.ScaleTable, err = readString(b); err != nil {
 		return err
 	}
-	if err := writeString(b, r.Order.ActiveStartTime); err != nil {
+	if r.Order.ActiveStartTime, err = readString(b); err != nil {
 		return err
 	}
-	if err := writeString(b, r.Order.ActiveStopTime); err != nil {
+	if r.Order.ActiveStopTime, err = readString(b); err != nil {
 		return err
 	}
-
-	if err := writeString(b, r.Order.HedgeType); err != nil {
+	if r.Order.HedgeType, err = readString(b); err != nil {
 		return err
 	}
-	if len(r.Order.HedgeType) > 0 {
-		if err := writeString(b, r.Order.HedgeParam); err != nil {
+	if r.Order.HedgeType != "" {
+		if r.Order.HedgeParam, err = readString(b); err != nil {
 			return err
 		}
 	}
-
-	if err := writeBool(b, r.Order.OptOutSmartRouting); err != nil {
+	if r.Order.OptOutSmartRouting, err = readBool(b); err != nil {
 		return err
 	}
-
-	if err := writeString(b, r.Order.ClearingAccount); err != nil {
+	if r.Order.ClearingAccount, err = readString(b); err != nil {
 		return err
 	}
-	if err := writeString(b, r.Order.ClearingIntent); err != nil {
+	if r.Order.ClearingIntent, err = readString(b); err != nil {
 		return err
 	}
-
-	if err := writeBool(b, r.Order.NotHeld); err != nil {
+	if r.Order.NotHeld, err = readBool(b); err != nil {
 		return err
 	}
-
-	if r.Contract.UnderComp != nil {
-		if err := writeBool(b, true); err != nil {
-			return err
-		}
-		if err := writeInt(b, r.Contract.UnderComp.ContractID); err != nil {
-			return err
-		}
-		if err := writeFloat(b, r.Contract.UnderComp.Delta); err != nil {
+	var haveUnderComp bool
+	if haveUnderComp, err = readBool(b); err != nil {
+		return err
+	}
+	if haveUnderComp {
+		r.Contract.UnderComp = new(UnderComp)
+		if r.Contract.UnderComp.ContractID, err = readInt(b); err != nil {
 			return err
 		}
-		if err := writeFloat(b, r.Contract.UnderComp.Price); err != nil {
+		if r.Contract.UnderComp.Delta, err = readFloat(b); err != nil {
 			return err
 		}
-	} else {
-		if err := writeBool(b, false); err != nil {
+		if r.Contract.UnderComp.Price, err = readFloat(b); err != nil {
 			return err
 		}
 	}
-
-	if err := writeString(b, r.Order.AlgoStrategy); err != nil {
+	if r.Order.AlgoStrategy, err = readString(b); err != nil {
 		return err
 	}
-	if len(r.Order.AlgoStrategy) > 0 {
-		if err := writeInt(b, int64(len(r.Order.AlgoParams.Params))); err != nil {
+	if r.Order.AlgoStrategy != "" {
+		var size int64
+		if size, err = readInt(b); err != nil {
 			return err
 		}
-		for _, tv := range r.Order.AlgoParams.Params {
-			if err := writeString(b, tv.Tag); err != nil {
+		r.Order.AlgoParams.Params = make([]*TagValue, size)
+		for i := range r.Order.AlgoParams.Params {
+			tv := &TagValue{}
+			if tv.Tag, err = readString(b); err != nil {
 				return err
 			}
-			if err := writeString(b, tv.Value); err != nil {
+			if tv.Value, err = readString(b); err != nil {
 				return err
 			}
+			r.Order.AlgoParams.Params[i] = tv
 		}
 	}
-
-	if err := writeBool(b, r.Order.WhatIf); err != nil {
+	if r.Order.WhatIf, err = readBool(b); err != nil {
 		return err
 	}
-
-	var miscOptions bytes.Buffer
-	miscOptions.WriteString("")
-	for _, opt := range r.Order.OrderMiscOptions {
-		miscOptions.WriteString(opt.Tag)
-		miscOptions.WriteString("=")
-		miscOptions.WriteString(opt.Value)
-		miscOptions.WriteString(";")
-	}
-	return writeString(b, miscOptions.String())
+	r.Order.OrderMiscOptions, err = readTagValueList(b)
+	return err
 }
 
 // RequestAccountUpdates is equivalent of IB API EClientSocket.reqAccountUpdates().
@@ -980,6 +1917,14 @@ func (r *RequestAccountUpdates) write(b *bytes.Buffer) error {
 	return writeString(b, r.AccountCode)
 }
 
+func (r *RequestAccountUpdates) read(b *bufio.Reader) (err error) {
+	if r.Subscribe, err = readBool(b); err != nil {
+		return err
+	}
+	r.AccountCode, err = readString(b)
+	return err
+}
+
 // RequestExecutions is equivalent of IB API EClientSocket.reqExecutions().
 type RequestExecutions struct {
 	id     int64
@@ -1018,6 +1963,32 @@ func (r *RequestExecutions) write(b *bytes.Buffer) error {
 	return writeString(b, r.Filter.Side)
 }
 
+func (r *RequestExecutions) read(b *bufio.Reader) (err error) {
+	if r.id, err = readInt(b); err != nil {
+		return err
+	}
+	if r.Filter.ClientID, err = readInt(b); err != nil {
+		return err
+	}
+	if r.Filter.AccountCode, err = readString(b); err != nil {
+		return err
+	}
+	if r.Filter.Time, err = readTime(b, timeReadLocalDateTime); err != nil {
+		return err
+	}
+	if r.Filter.Symbol, err = readString(b); err != nil {
+		return err
+	}
+	if r.Filter.SecType, err = readString(b); err != nil {
+		return err
+	}
+	if r.Filter.Exchange, err = readString(b); err != nil {
+		return err
+	}
+	r.Filter.Side, err = readString(b)
+	return err
+}
+
 // CancelOrder is equivalent of IB API EClientSocket.cancelOrder().
 type CancelOrder struct {
 	id int64
@@ -1027,10 +1998,11 @@ type CancelOrder struct {
 func (c *CancelOrder) SetID(id int64) { c.id = id }
 
 // ID .
-func (c *CancelOrder) ID() int64                   { return c.id }
-func (c *CancelOrder) code() OutgoingMessageID     { return mCancelOrder }
-func (c *CancelOrder) version() int64              { return 1 }
-func (c *CancelOrder) write(b *bytes.Buffer) error { return writeInt(b, c.id) }
+func (c *CancelOrder) ID() int64                        { return c.id }
+func (c *CancelOrder) code() OutgoingMessageID          { return mCancelOrder }
+func (c *CancelOrder) version() int64                   { return 1 }
+func (c *CancelOrder) write(b *bytes.Buffer) error      { return writeInt(b, c.id) }
+func (c *CancelOrder) read(b *bufio.Reader) (err error) { c.id, err = readInt(b); return err }
 
 // RequestOpenOrders is equivalent of IB API EClientSocket.reqOpenOrders().
 type RequestOpenOrders struct{}
@@ -1038,13 +2010,15 @@ type RequestOpenOrders struct{}
 func (r *RequestOpenOrders) code() OutgoingMessageID     { return mRequestOpenOrders }
 func (r *RequestOpenOrders) version() int64              { return 1 }
 func (r *RequestOpenOrders) write(b *bytes.Buffer) error { return nil }
+func (r *RequestOpenOrders) read(b *bufio.Reader) error  { return nil }
 
 // RequestIDs is equivalent of IB API EClientSocket.reqIds().
 type RequestIDs struct{}
 
-func (r *RequestIDs) code() OutgoingMessageID     { return mRequestIDs }
-func (r *RequestIDs) version() int64              { return 1 }
-func (r *RequestIDs) write(b *bytes.Buffer) error { return writeInt(b, 1) }
+func (r *RequestIDs) code() OutgoingMessageID          { return mRequestIDs }
+func (r *RequestIDs) version() int64                   { return 1 }
+func (r *RequestIDs) write(b *bytes.Buffer) error      { return writeInt(b, 1) }
+func (r *RequestIDs) read(b *bufio.Reader) (err error) { _, err = readInt(b); return err }
 
 // RequestNewsBulletins is equivalent of IB API EClientSocket.reqNewsBulletins().
 type RequestNewsBulletins struct {
@@ -1054,6 +2028,10 @@ type RequestNewsBulletins struct {
 func (r *RequestNewsBulletins) code() OutgoingMessageID     { return mRequestNewsBulletins }
 func (r *RequestNewsBulletins) version() int64              { return 1 }
 func (r *RequestNewsBulletins) write(b *bytes.Buffer) error { return writeBool(b, r.AllMsgs) }
+func (r *RequestNewsBulletins) read(b *bufio.Reader) (err error) {
+	r.AllMsgs, err = readBool(b)
+	return err
+}
 
 // CancelNewsBulletins is equivalent of IB API EClientSocket.cancelNewsBulletins().
 type CancelNewsBulletins struct{}
@@ -1061,6 +2039,7 @@ type CancelNewsBulletins struct{}
 func (c *CancelNewsBulletins) code() OutgoingMessageID     { return mCancelNewsBulletins }
 func (c *CancelNewsBulletins) version() int64              { return 1 }
 func (c *CancelNewsBulletins) write(b *bytes.Buffer) error { return nil }
+func (c *CancelNewsBulletins) read(b *bufio.Reader) error  { return nil }
 
 // SetServerLogLevel is equivalent of IB API EClientSocket.setServerLogLevel().
 type SetServerLogLevel struct {
@@ -1070,6 +2049,10 @@ type SetServerLogLevel struct {
 func (s *SetServerLogLevel) code() OutgoingMessageID     { return mSetServerLogLevel }
 func (s *SetServerLogLevel) version() int64              { return 1 }
 func (s *SetServerLogLevel) write(b *bytes.Buffer) error { return writeInt(b, s.LogLevel) }
+func (s *SetServerLogLevel) read(b *bufio.Reader) (err error) {
+	s.LogLevel, err = readInt(b)
+	return err
+}
 
 // RequestAutoOpenOrders is equivalent of IB API EClientSocket.reqAutoOpenOrders().
 type RequestAutoOpenOrders struct {
@@ -1081,6 +2064,10 @@ func (r *RequestAutoOpenOrders) SetAutoBind(autobind bool)   { r.AutoBind = auto
 func (r *RequestAutoOpenOrders) code() OutgoingMessageID     { return mRequestAutoOpenOrders }
 func (r *RequestAutoOpenOrders) version() int64              { return 1 }
 func (r *RequestAutoOpenOrders) write(b *bytes.Buffer) error { return writeBool(b, r.AutoBind) }
+func (r *RequestAutoOpenOrders) read(b *bufio.Reader) (err error) {
+	r.AutoBind, err = readBool(b)
+	return err
+}
 
 // RequestAllOpenOrders is equivalent of IB API EClientSocket.reqAllOpenOrders().
 type RequestAllOpenOrders struct{}
@@ -1088,6 +2075,7 @@ type RequestAllOpenOrders struct{}
 func (r *RequestAllOpenOrders) code() OutgoingMessageID     { return mRequestAllOpenOrders }
 func (r *RequestAllOpenOrders) version() int64              { return 1 }
 func (r *RequestAllOpenOrders) write(b *bytes.Buffer) error { return nil }
+func (r *RequestAllOpenOrders) read(b *bufio.Reader) error  { return nil }
 
 // RequestManagedAccounts is equivalent of IB API EClientSocket.reqManagedAccts().
 type RequestManagedAccounts struct{}
@@ -1095,15 +2083,17 @@ type RequestManagedAccounts struct{}
 func (r *RequestManagedAccounts) code() OutgoingMessageID     { return mRequestManagedAccounts }
 func (r *RequestManagedAccounts) version() int64              { return 1 }
 func (r *RequestManagedAccounts) write(b *bytes.Buffer) error { return nil }
+func (r *RequestManagedAccounts) read(b *bufio.Reader) error  { return nil }
 
 // RequestFA is equivalent of IB API EClientSocket.requestFA().
 type RequestFA struct {
 	faDataType int64
 }
 
-func (r *RequestFA) code() OutgoingMessageID     { return mRequestFA }
-func (r *RequestFA) version() int64              { return 1 }
-func (r *RequestFA) write(b *bytes.Buffer) error { return writeInt(b, r.faDataType) }
+func (r *RequestFA) code() OutgoingMessageID          { return mRequestFA }
+func (r *RequestFA) version() int64                   { return 1 }
+func (r *RequestFA) write(b *bytes.Buffer) error      { return writeInt(b, r.faDataType) }
+func (r *RequestFA) read(b *bufio.Reader) (err error) { r.faDataType, err = readInt(b); return err }
 
 // ReplaceFA is equivalent of IB API EClientSocket.replaceFA().
 type ReplaceFA struct {
@@ -1120,12 +2110,21 @@ func (r *ReplaceFA) write(b *bytes.Buffer) error {
 	return writeString(b, r.xml)
 }
 
+func (r *ReplaceFA) read(b *bufio.Reader) (err error) {
+	if r.faDataType, err = readInt(b); err != nil {
+		return err
+	}
+	r.xml, err = readString(b)
+	return err
+}
+
 // RequestCurrentTime is equivalent of IB API EClientSocket.reqCurrentTime().
 type RequestCurrentTime struct{}
 
 func (r *RequestCurrentTime) code() OutgoingMessageID     { return mRequestCurrentTime }
 func (r *RequestCurrentTime) version() int64              { return 1 }
 func (r *RequestCurrentTime) write(b *bytes.Buffer) error { return nil }
+func (r *RequestCurrentTime) read(b *bufio.Reader) error  { return nil }
 
 // RequestFundamentalData is equivalent of IB API EClientSocket.reqFundamentalData().
 type RequestFundamentalData struct {
@@ -1169,6 +2168,35 @@ func (r *RequestFundamentalData) write(b *bytes.Buffer) error {
 	return writeString(b, r.ReportType)
 }
 
+func (r *RequestFundamentalData) read(b *bufio.Reader) (err error) {
+	if r.id, err = readInt(b); err != nil {
+		return err
+	}
+	if r.Contract.ContractID, err = readInt(b); err != nil {
+		return err
+	}
+	if r.Contract.Symbol, err = readString(b); err != nil {
+		return err
+	}
+	if r.Contract.SecurityType, err = readString(b); err != nil {
+		return err
+	}
+	if r.Contract.Exchange, err = readString(b); err != nil {
+		return err
+	}
+	if r.Contract.PrimaryExchange, err = readString(b); err != nil {
+		return err
+	}
+	if r.Contract.Currency, err = readString(b); err != nil {
+		return err
+	}
+	if r.Contract.LocalSymbol, err = readString(b); err != nil {
+		return err
+	}
+	r.ReportType, err = readString(b)
+	return err
+}
+
 // CancelFundamentalData is equivalent of IB API EClientSocket.cancelFundamentalData().
 type CancelFundamentalData struct {
 	id int64
@@ -1178,10 +2206,11 @@ type CancelFundamentalData struct {
 func (c *CancelFundamentalData) SetID(id int64) { c.id = id }
 
 // ID .
-func (c *CancelFundamentalData) ID() int64                   { return c.id }
-func (c *CancelFundamentalData) code() OutgoingMessageID     { return mCancelFundamentalData }
-func (c *CancelFundamentalData) version() int64              { return 1 }
-func (c *CancelFundamentalData) write(b *bytes.Buffer) error { return writeInt(b, c.id) }
+func (c *CancelFundamentalData) ID() int64                        { return c.id }
+func (c *CancelFundamentalData) code() OutgoingMessageID          { return mCancelFundamentalData }
+func (c *CancelFundamentalData) version() int64                   { return 1 }
+func (c *CancelFundamentalData) write(b *bytes.Buffer) error      { return writeInt(b, c.id) }
+func (c *CancelFundamentalData) read(b *bufio.Reader) (err error) { c.id, err = readInt(b); return err }
 
 // RequestCalcImpliedVol is equivalent of IB API EClientSocket.calculateImpliedVolatility().
 type RequestCalcImpliedVol struct {
@@ -1244,6 +2273,53 @@ func (r *RequestCalcImpliedVol) write(b *bytes.Buffer) error {
 	return writeFloat(b, r.UnderPrice)
 }
 
+func (r *RequestCalcImpliedVol) read(b *bufio.Reader) (err error) {
+	if r.id, err = readInt(b); err != nil {
+		return err
+	}
+	if r.Contract.ContractID, err = readInt(b); err != nil {
+		return err
+	}
+	if r.Contract.Symbol, err = readString(b); err != nil {
+		return err
+	}
+	if r.Contract.SecurityType, err = readString(b); err != nil {
+		return err
+	}
+	if r.Contract.Expiry, err = readString(b); err != nil {
+		return err
+	}
+	if r.Contract.Strike, err = readFloat(b); err != nil {
+		return err
+	}
+	if r.Contract.Right, err = readString(b); err != nil {
+		return err
+	}
+	if r.Contract.Multiplier, err = readString(b); err != nil {
+		return err
+	}
+	if r.Contract.Exchange, err = readString(b); err != nil {
+		return err
+	}
+	if r.Contract.PrimaryExchange, err = readString(b); err != nil {
+		return err
+	}
+	if r.Contract.Currency, err = readString(b); err != nil {
+		return err
+	}
+	if r.Contract.LocalSymbol, err = readString(b); err != nil {
+		return err
+	}
+	if r.Contract.TradingClass, err = readString(b); err != nil {
+		return err
+	}
+	if r.OptionPrice, err = readFloat(b); err != nil {
+		return err
+	}
+	r.UnderPrice, err = readFloat(b)
+	return err
+}
+
 // CancelCalcImpliedVol is equivalent of IB API EClientSocket.cancelCalculateImpliedVolatility().
 type CancelCalcImpliedVol struct {
 	id int64
@@ -1253,10 +2329,11 @@ type CancelCalcImpliedVol struct {
 func (c *CancelCalcImpliedVol) SetID(id int64) { c.id = id }
 
 // ID .
-func (c *CancelCalcImpliedVol) ID() int64                   { return c.id }
-func (c *CancelCalcImpliedVol) code() OutgoingMessageID     { return mCancelCalcImpliedVol }
-func (c *CancelCalcImpliedVol) version() int64              { return 1 }
-func (c *CancelCalcImpliedVol) write(b *bytes.Buffer) error { return writeInt(b, c.id) }
+func (c *CancelCalcImpliedVol) ID() int64                        { return c.id }
+func (c *CancelCalcImpliedVol) code() OutgoingMessageID          { return mCancelCalcImpliedVol }
+func (c *CancelCalcImpliedVol) version() int64                   { return 1 }
+func (c *CancelCalcImpliedVol) write(b *bytes.Buffer) error      { return writeInt(b, c.id) }
+func (c *CancelCalcImpliedVol) read(b *bufio.Reader) (err error) { c.id, err = readInt(b); return err }
 
 // RequestCalcOptionPrice is equivalent of IB API EClientSocket.calculateOptionPrice().
 type RequestCalcOptionPrice struct {
@@ -1319,6 +2396,53 @@ func (r *RequestCalcOptionPrice) write(b *bytes.Buffer) error {
 	return writeFloat(b, r.UnderPrice)
 }
 
+func (r *RequestCalcOptionPrice) read(b *bufio.Reader) (err error) {
+	if r.id, err = readInt(b); err != nil {
+		return err
+	}
+	if r.Contract.ContractID, err = readInt(b); err != nil {
+		return err
+	}
+	if r.Contract.Symbol, err = readString(b); err != nil {
+		return err
+	}
+	if r.Contract.SecurityType, err = readString(b); err != nil {
+		return err
+	}
+	if r.Contract.Expiry, err = readString(b); err != nil {
+		return err
+	}
+	if r.Contract.Strike, err = readFloat(b); err != nil {
+		return err
+	}
+	if r.Contract.Right, err = readString(b); err != nil {
+		return err
+	}
+	if r.Contract.Multiplier, err = readString(b); err != nil {
+		return err
+	}
+	if r.Contract.Exchange, err = readString(b); err != nil {
+		return err
+	}
+	if r.Contract.PrimaryExchange, err = readString(b); err != nil {
+		return err
+	}
+	if r.Contract.Currency, err = readString(b); err != nil {
+		return err
+	}
+	if r.Contract.LocalSymbol, err = readString(b); err != nil {
+		return err
+	}
+	if r.Contract.TradingClass, err = readString(b); err != nil {
+		return err
+	}
+	if r.Volatility, err = readFloat(b); err != nil {
+		return err
+	}
+	r.UnderPrice, err = readFloat(b)
+	return err
+}
+
 // CancelCalcOptionPrice is equivalent of IB API EClientSocket.cancelCalculateOptionPrice().
 type CancelCalcOptionPrice struct {
 	id int64
@@ -1328,10 +2452,11 @@ type CancelCalcOptionPrice struct {
 func (c *CancelCalcOptionPrice) SetID(id int64) { c.id = id }
 
 // ID .
-func (c *CancelCalcOptionPrice) ID() int64                   { return c.id }
-func (c *CancelCalcOptionPrice) code() OutgoingMessageID     { return mCancelCalcOptionPrice }
-func (c *CancelCalcOptionPrice) version() int64              { return 1 }
-func (c *CancelCalcOptionPrice) write(b *bytes.Buffer) error { return writeInt(b, c.id) }
+func (c *CancelCalcOptionPrice) ID() int64                        { return c.id }
+func (c *CancelCalcOptionPrice) code() OutgoingMessageID          { return mCancelCalcOptionPrice }
+func (c *CancelCalcOptionPrice) version() int64                   { return 1 }
+func (c *CancelCalcOptionPrice) write(b *bytes.Buffer) error      { return writeInt(b, c.id) }
+func (c *CancelCalcOptionPrice) read(b *bufio.Reader) (err error) { c.id, err = readInt(b); return err }
 
 // RequestGlobalCancel is equivalent of IB API EClientSocket.reqGlobalCancel()
 type RequestGlobalCancel struct{}
@@ -1339,6 +2464,7 @@ type RequestGlobalCancel struct{}
 func (r *RequestGlobalCancel) code() OutgoingMessageID     { return mRequestGlobalCancel }
 func (r *RequestGlobalCancel) version() int64              { return 1 }
 func (r *RequestGlobalCancel) write(b *bytes.Buffer) error { return nil }
+func (r *RequestGlobalCancel) read(b *bufio.Reader) error  { return nil }
 
 // RequestMarketDataType is equivalent of IB API EClientSocket.reqMarketDataType()
 type RequestMarketDataType struct {
@@ -1348,6 +2474,10 @@ type RequestMarketDataType struct {
 func (r *RequestMarketDataType) code() OutgoingMessageID     { return mRequestMarketDataType }
 func (r *RequestMarketDataType) version() int64              { return 1 }
 func (r *RequestMarketDataType) write(b *bytes.Buffer) error { return writeInt(b, r.MarketDataType) }
+func (r *RequestMarketDataType) read(b *bufio.Reader) (err error) {
+	r.MarketDataType, err = readInt(b)
+	return err
+}
 
 // RequestPositions is equivalent of IB API EClientSocket.reqPositions()
 type RequestPositions struct{}
@@ -1355,6 +2485,7 @@ type RequestPositions struct{}
 func (r *RequestPositions) code() OutgoingMessageID     { return mRequestPositions }
 func (r *RequestPositions) version() int64              { return 1 }
 func (r *RequestPositions) write(b *bytes.Buffer) error { return nil }
+func (r *RequestPositions) read(b *bufio.Reader) error  { return nil }
 
 // CancelPositions is equivalent of IB API EClientSocket.cancelPositions()
 type CancelPositions struct{}
@@ -1362,6 +2493,7 @@ type CancelPositions struct{}
 func (c *CancelPositions) code() OutgoingMessageID     { return mCancelPositions }
 func (c *CancelPositions) version() int64              { return 1 }
 func (c *CancelPositions) write(b *bytes.Buffer) error { return nil }
+func (c *CancelPositions) read(b *bufio.Reader) error  { return nil }
 
 // RequestAccountSummary is equivalent of IB API EClientSocket.reqAccountSummary()
 type RequestAccountSummary struct {
@@ -1387,6 +2519,17 @@ func (r *RequestAccountSummary) write(b *bytes.Buffer) error {
 	return writeString(b, r.Tags)
 }
 
+func (r *RequestAccountSummary) read(b *bufio.Reader) (err error) {
+	if r.id, err = readInt(b); err != nil {
+		return err
+	}
+	if r.Group, err = readString(b); err != nil {
+		return err
+	}
+	r.Tags, err = readString(b)
+	return err
+}
+
 // CancelAccountSummary is equivalent of IB API EClientSocket.cancelAccountSummary()
 type CancelAccountSummary struct {
 	id int64
@@ -1396,10 +2539,11 @@ type CancelAccountSummary struct {
 func (c *CancelAccountSummary) SetID(id int64) { c.id = id }
 
 // ID .
-func (c *CancelAccountSummary) ID() int64                   { return c.id }
-func (c *CancelAccountSummary) code() OutgoingMessageID     { return mCancelAccountSummary }
-func (c *CancelAccountSummary) version() int64              { return 1 }
-func (c *CancelAccountSummary) write(b *bytes.Buffer) error { return writeInt(b, c.id) }
+func (c *CancelAccountSummary) ID() int64                        { return c.id }
+func (c *CancelAccountSummary) code() OutgoingMessageID          { return mCancelAccountSummary }
+func (c *CancelAccountSummary) version() int64                   { return 1 }
+func (c *CancelAccountSummary) write(b *bytes.Buffer) error      { return writeInt(b, c.id) }
+func (c *CancelAccountSummary) read(b *bufio.Reader) (err error) { c.id, err = readInt(b); return err }
 
 // VerifyRequest is equivalent of IB API EClientSocket.verifyRequest()
 type VerifyRequest struct {
@@ -1417,14 +2561,23 @@ func (v *VerifyRequest) write(b *bytes.Buffer) error {
 	return writeString(b, v.apiVersion)
 }
 
+func (v *VerifyRequest) read(b *bufio.Reader) (err error) {
+	if v.apiName, err = readString(b); err != nil {
+		return err
+	}
+	v.apiVersion, err = readString(b)
+	return err
+}
+
 // VerifyMessage is equivalent of IB API EClientSocket.verifyMessage()
 type VerifyMessage struct {
 	apiData string
 }
 
-func (v *VerifyMessage) code() OutgoingMessageID     { return mVerifyMessage }
-func (v *VerifyMessage) version() int64              { return 1 }
-func (v *VerifyMessage) write(b *bytes.Buffer) error { return writeString(b, v.apiData) }
+func (v *VerifyMessage) code() OutgoingMessageID          { return mVerifyMessage }
+func (v *VerifyMessage) version() int64                   { return 1 }
+func (v *VerifyMessage) write(b *bytes.Buffer) error      { return writeString(b, v.apiData) }
+func (v *VerifyMessage) read(b *bufio.Reader) (err error) { v.apiData, err = readString(b); return err }
 
 // QueryDisplayGroups is equivalent of IB API EClientSocket.queryDisplayGroups()
 type QueryDisplayGroups struct {
@@ -1437,10 +2590,11 @@ func (q *QueryDisplayGroups) SetID(id int64) {
 }
 
 // ID .
-func (q *QueryDisplayGroups) ID() int64                   { return q.id }
-func (q *QueryDisplayGroups) code() OutgoingMessageID     { return mQueryDisplayGroups }
-func (q *QueryDisplayGroups) version() int64              { return 1 }
-func (q *QueryDisplayGroups) write(b *bytes.Buffer) error { return writeInt(b, q.id) }
+func (q *QueryDisplayGroups) ID() int64                        { return q.id }
+func (q *QueryDisplayGroups) code() OutgoingMessageID          { return mQueryDisplayGroups }
+func (q *QueryDisplayGroups) version() int64                   { return 1 }
+func (q *QueryDisplayGroups) write(b *bytes.Buffer) error      { return writeInt(b, q.id) }
+func (q *QueryDisplayGroups) read(b *bufio.Reader) (err error) { q.id, err = readInt(b); return err }
 
 // SubscribeToGroupEvents is equivalent of IB API EClientSocket.subscribeToGroupEvents()
 type SubscribeToGroupEvents struct {
@@ -1462,6 +2616,14 @@ func (s *SubscribeToGroupEvents) write(b *bytes.Buffer) error {
 	return writeInt(b, s.groupid)
 }
 
+func (s *SubscribeToGroupEvents) read(b *bufio.Reader) (err error) {
+	if s.id, err = readInt(b); err != nil {
+		return err
+	}
+	s.groupid, err = readInt(b)
+	return err
+}
+
 // UpdateDisplayGroup is equivalent of IB API EClientSocket.updateDisplayGroup()
 type UpdateDisplayGroup struct {
 	id           int64
@@ -1482,6 +2644,14 @@ func (u *UpdateDisplayGroup) write(b *bytes.Buffer) error {
 	return writeString(b, u.ContractInfo)
 }
 
+func (u *UpdateDisplayGroup) read(b *bufio.Reader) (err error) {
+	if u.id, err = readInt(b); err != nil {
+		return err
+	}
+	u.ContractInfo, err = readString(b)
+	return err
+}
+
 // UnsubscribeFromGroupEvents is equivalent of IB API EClientSocket.unsubscribeFromGroupEvents()
 type UnsubscribeFromGroupEvents struct {
 	id int64
@@ -1495,3 +2665,7 @@ func (u *UnsubscribeFromGroupEvents) ID() int64                   { return u.id
 func (u *UnsubscribeFromGroupEvents) code() OutgoingMessageID     { return mUnsubscribeFromGroupEvents }
 func (u *UnsubscribeFromGroupEvents) version() int64              { return 1 }
 func (u *UnsubscribeFromGroupEvents) write(b *bytes.Buffer) error { return writeInt(b, u.id) }
+func (u *UnsubscribeFromGroupEvents) read(b *bufio.Reader) (err error) {
+	u.id, err = readInt(b)
+	return err
+}
@@ -3,7 +3,8 @@
 // Engine is the main type. It provides a mechanism to connect to either IB
 // Gateway or TWS, send Request values and receive Reply values. The Engine
 // provides an observer pattern both for receiving Reply values as well as Engine
-// termination notification. Any network level errors will terminate the Engine.
+// state notification. Any network level errors will terminate the Engine, unless
+// EngineOptions.Reconnect is set (in which case the Engine redials the gateway).
 //
 // A high-level Manager interface is also provided. This provides a way to
 // easily use IB API without needing to deal directly with Engine and the
@@ -21,6 +22,7 @@ import (
 	"net"
 	"reflect"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -30,11 +32,43 @@ const (
 	UnmatchedReplyID = int64(-9223372036854775808)
 )
 
+// errorDuplicateOrderID is the ErrorMessage code for a reused order ID.
+const errorDuplicateOrderID = 103
+
+// orderIDTimeout is how long to wait for a NextValidID reply.
+var orderIDTimeout = 10 * time.Second
+
 // EngineOptions .
 type EngineOptions struct {
 	Gateway          string
 	Client           int64
 	DumpConversation bool
+	Reconnect        bool    // redial the gateway after network errors
+	MaxReconnects    int     // consecutive attempts before giving up (0 is unlimited)
+	Backoff          Backoff // delay before each attempt (nil uses DefaultBackoff)
+	HistoricalPacing HistoricalPacing
+}
+
+// Backoff returns how long to wait before the given reconnection attempt.
+// Attempts are numbered from 1 and restart after each successful reconnection.
+type Backoff func(attempt int) time.Duration
+
+// DefaultBackoff is used when EngineOptions.Backoff is nil.
+var DefaultBackoff = ExponentialBackoff(time.Second, time.Minute)
+
+// ExponentialBackoff returns a Backoff which starts at min and doubles on each
+// attempt, up to max.
+func ExponentialBackoff(min, max time.Duration) Backoff {
+	return func(attempt int) time.Duration {
+		d := min
+		for i := 1; i < attempt && d < max; i++ {
+			d *= 2
+		}
+		if d > max {
+			d = max
+		}
+		return d
+	}
 }
 
 // Engine is the entry point to the IB IB API
@@ -49,14 +83,19 @@ type Engine struct {
 	reader           *bufio.Reader
 	input            *bytes.Buffer
 	output           *bytes.Buffer
+	session          chan struct{}
 	rxReply          chan Reply
 	rxErr            chan error
 	txRequest        chan txrequest
 	txErr            chan error
+	redialed         chan error
+	reconnect        bool
+	maxReconnects    int
+	backoff          Backoff
 	observers        map[int64]chan<- Reply
 	unObservers      []chan<- Reply
 	allObservers     []chan<- Reply
-	stObservers      []chan<- EngineState
+	stObservers      []*stateObserver
 	state            EngineState
 	serverTime       time.Time
 	clientVersion    int64
@@ -65,6 +104,10 @@ type Engine struct {
 	lastDumpRead     int64
 	lastDumpID       int64
 	fatalError       error
+	orderMu          sync.Mutex
+	orderID          int64         // next order ID, 0 until the first NextValidID
+	orderIDSeen      chan struct{} // closed (and replaced) on each NextValidID
+	historical       *HistoricalScheduler
 }
 
 type command struct {
@@ -74,7 +117,7 @@ type command struct {
 
 type txrequest struct {
 	req Request
-	ack chan struct{}
+	ack chan error
 }
 
 func uniqueID(start int64) chan int64 {
@@ -112,6 +155,11 @@ func NewEngine(opt EngineOptions) (*Engine, error) {
 		client = <-clientSeq
 	}
 
+	backoff := opt.Backoff
+	if backoff == nil {
+		backoff = DefaultBackoff
+	}
+
 	e := Engine{
 		id:               uniqueID(100),
 		exit:             make(chan bool),
@@ -123,23 +171,27 @@ func NewEngine(opt EngineOptions) (*Engine, error) {
 		reader:           bufio.NewReader(conn),
 		input:            bytes.NewBuffer(make([]byte, 0, 4096)),
 		output:           bytes.NewBuffer(make([]byte, 0, 4096)),
-		rxReply:          make(chan Reply),
-		rxErr:            make(chan error),
 		txRequest:        make(chan txrequest),
-		txErr:            make(chan error),
+		redialed:         make(chan error),
+		reconnect:        opt.Reconnect,
+		maxReconnects:    opt.MaxReconnects,
+		backoff:          backoff,
 		observers:        map[int64]chan<- Reply{},
 		state:            EngineReady,
 		dumpConversation: opt.DumpConversation,
+		orderIDSeen:      make(chan struct{}),
 	}
+	e.historical = newHistoricalScheduler(&e, opt.HistoricalPacing)
 
 	if err := e.handshake(); err != nil {
+		conn.Close()
 		return nil, err
 	}
 
 	// start worker goroutines (these exit on request or error)
-	go e.startReceiver()
-	go e.startTransmitter()
+	e.startSession()
 	go e.startMainLoop()
+	go e.historical.run()
 
 	// send the StartAPI request
 	e.Send(&StartAPI{Client: e.client})
@@ -175,49 +227,84 @@ func (e *Engine) handshake() error {
 	return nil
 }
 
-func (e *Engine) startReceiver() {
+// startSession starts the receiver and transmitter goroutines for the current
+// connection. They exit when the connection fails or closeSession is called.
+func (e *Engine) startSession() {
+	e.session = make(chan struct{})
+	e.rxReply = make(chan Reply)
+	e.rxErr = make(chan error)
+	e.txErr = make(chan error)
+	go e.startReceiver(e.session, e.rxReply, e.rxErr)
+	go e.startTransmitter(e.session, e.txErr)
+}
+
+// closeSession closes the current connection and blocks until its receiver and
+// transmitter goroutines have exited. It is safe to call without a session.
+func (e *Engine) closeSession() {
+	if e.session == nil {
+		return
+	}
+	close(e.session)
+
+	// Safe to kill the connection, as we're advising other goroutines we're quitting
+	e.con.Close()
+
+	// Wait for other goroutines to indicate they've finished
+	for range e.txErr {
+	}
+	for range e.rxErr {
+	}
+
+	e.session = nil
+	e.rxReply = nil
+	e.rxErr = nil
+	e.txErr = nil
+}
+
+func (e *Engine) startReceiver(done chan struct{}, rxReply chan<- Reply, rxErr chan<- error) {
 	defer func() {
-		close(e.rxReply)
-		close(e.rxErr)
+		close(rxReply)
+		close(rxErr)
 	}()
 	for {
 		r, err := e.receive()
 		if err != nil {
 			select {
-			case <-e.terminated:
+			case <-done:
 				return
-			case e.rxErr <- err:
+			case rxErr <- err:
 				return
 			}
 		}
 
 		select {
-		case <-e.terminated:
+		case <-done:
 			return
-		case e.rxReply <- r:
+		case rxReply <- r:
 		}
 	}
 }
 
-func (e *Engine) startTransmitter() {
+func (e *Engine) startTransmitter(done chan struct{}, txErr chan<- error) {
 	defer func() {
 		// Don't close txRequest, as we are not the sender
-		close(e.txErr)
+		close(txErr)
 	}()
 	for {
 		select {
-		case <-e.terminated:
+		case <-done:
 			return
 		case t := <-e.txRequest:
-			if err := e.transmit(t.req); err != nil {
+			err := e.transmit(t.req)
+			t.ack <- err
+			if err != nil {
 				select {
-				case <-e.terminated:
+				case <-done:
 					return
-				case e.txErr <- err:
+				case txErr <- err:
 					return
 				}
 			}
-			close(t.ack)
 		}
 	}
 }
@@ -227,24 +314,8 @@ func (e *Engine) startMainLoop() {
 		// Signal terminating for benefit of other goroutines
 		close(e.terminated)
 
-		// Safe to kill the connection, as we're advising other goroutines we're quitting
-		e.con.Close()
-
-		// Wait for other goroutines to indicate they've finished
-		<-e.txErr
-		<-e.rxErr
-
-	outer:
-		for _, ob := range e.stObservers {
-			for {
-				select {
-				case ob <- e.state:
-					continue outer
-				case <-time.After(5 * time.Second):
-					log.Printf("Waited 5 seconds for state channel %v\n", ob)
-				}
-			}
-		}
+		e.closeSession()
+		e.notifyState(e.state)
 	}()
 	for {
 		select {
@@ -253,40 +324,165 @@ func (e *Engine) startMainLoop() {
 			return
 		case err := <-e.rxErr:
 			log.Printf("%s engine: RX error %s", e.ConnectionInfo(), err)
-			e.fatalError = err
-			e.state = EngineExitError
-			return
+			if !e.startReconnect(err) {
+				return
+			}
 		case err := <-e.txErr:
 			log.Printf("%s engine: TX error %s", e.ConnectionInfo(), err)
-			e.fatalError = err
-			e.state = EngineExitError
-			return
+			if !e.startReconnect(err) {
+				return
+			}
+		case err := <-e.redialed:
+			if err != nil {
+				log.Printf("%s engine: reconnect failed %s", e.ConnectionInfo(), err)
+				e.fatalError = err
+				e.state = EngineExitError
+				return
+			}
+			log.Printf("%s engine: reconnected", e.ConnectionInfo())
+			e.startSession()
+			e.state = EngineReady
+			e.notifyState(EngineReconnected)
 		case cmd := <-e.ch:
 			cmd.fun()
 			close(cmd.ack)
 		case r := <-e.rxReply:
+			e.observeOrderID(r)
 			e.deliverToObservers(r)
 		}
 	}
 }
 
+// startReconnect handles a network error. It returns false if the engine is
+// not configured to reconnect, in which case it records the fatal error.
+// Otherwise it tears down the failed session and begins redialing in the
+// background, with the outcome later delivered to the redialed channel.
+func (e *Engine) startReconnect(cause error) bool {
+	if !e.reconnect {
+		e.fatalError = cause
+		e.state = EngineExitError
+		return false
+	}
+	e.closeSession()
+	e.state = EngineReconnecting
+	e.notifyState(e.state)
+	go e.redial(cause)
+	return true
+}
+
+// redial repeatedly attempts to reconnect to the gateway, observing the backoff
+// policy and maximum attempts. On success the new connection has completed the
+// handshake and been sent StartAPI using the original client ID.
+func (e *Engine) redial(cause error) {
+	err := cause
+	for attempt := 1; e.maxReconnects == 0 || attempt <= e.maxReconnects; attempt++ {
+		select {
+		case <-e.terminated:
+			return
+		case <-time.After(e.backoff(attempt)):
+		}
+
+		if err = e.dial(); err == nil {
+			break
+		}
+		log.Printf("%s engine: reconnect attempt %d failed %s", e.ConnectionInfo(), attempt, err)
+	}
+
+	select {
+	case <-e.terminated:
+		if err == nil {
+			e.con.Close()
+		}
+	case e.redialed <- err:
+	}
+}
+
+// dial opens a new connection to the gateway and restarts the API on it.
+func (e *Engine) dial() error {
+	conn, err := net.Dial("tcp", e.gateway)
+	if err != nil {
+		return err
+	}
+	e.con = conn
+	e.reader = bufio.NewReader(conn)
+
+	if err := e.handshake(); err != nil {
+		conn.Close()
+		return err
+	}
+	if err := e.transmit(&StartAPI{Client: e.client}); err != nil {
+		conn.Close()
+		return err
+	}
+	return nil
+}
+
+// stateObserver relays state changes to a state observer's channel, so a slow
+// observer never blocks the main loop. Only the latest undelivered state is
+// kept: an observer which has fallen behind skips to the current state.
+type stateObserver struct {
+	ch     chan<- EngineState
+	latest chan EngineState // buffered (1), only sent to by the main loop
+	done   chan struct{}    // closed on UnsubscribeState
+}
+
+func newStateObserver(o chan<- EngineState) *stateObserver {
+	ob := &stateObserver{ch: o, latest: make(chan EngineState, 1), done: make(chan struct{})}
+	go ob.relay()
+	return ob
+}
+
+func (ob *stateObserver) relay() {
+	for {
+		select {
+		case s := <-ob.latest:
+			select {
+			case ob.ch <- s:
+			case <-ob.done:
+				return
+			}
+			if s == EngineExitNormal || s == EngineExitError {
+				return
+			}
+		case <-ob.done:
+			return
+		}
+	}
+}
+
+// notify replaces any undelivered state with s, without blocking.
+func (ob *stateObserver) notify(s EngineState) {
+	select {
+	case <-ob.latest:
+	default:
+	}
+	ob.latest <- s
+}
+
+// notifyState delivers the given state to every state observer.
+func (e *Engine) notifyState(s EngineState) {
+	for _, ob := range e.stObservers {
+		ob.notify(s)
+	}
+}
+
 func (e *Engine) deliverToObservers(r Reply) {
 	if r.code() == mErrorMessage {
-		var done []chan<- Reply
-		for _, o := range e.observers {
-			for _, prevDone := range done {
-				if o == prevDone {
-					continue
-				}
+		done := map[chan<- Reply]bool{}
+		deliver := func(o chan<- Reply) {
+			if !done[o] {
+				done[o] = true
+				e.deliverToObserver(o, r)
 			}
-			done = append(done, o)
-			e.deliverToObserver(o, r)
+		}
+		for _, o := range e.observers {
+			deliver(o)
 		}
 		for _, o := range e.unObservers {
-			e.deliverToObserver(o, r)
+			deliver(o)
 		}
 		for _, o := range e.allObservers {
-			e.deliverToObserver(o, r)
+			deliver(o)
 		}
 		return
 	}
@@ -353,6 +549,94 @@ func (e *Engine) NextRequestID() int64 {
 	return <-e.id
 }
 
+// NextOrderID returns a unique order ID. Order IDs come from their own
+// sequence, which starts at the NextValidID sent by the gateway after StartAPI
+// (this call blocks until it arrives) and is raised by any later NextValidID.
+func (e *Engine) NextOrderID() (int64, error) {
+	e.orderMu.Lock()
+	for e.orderID == 0 {
+		seen := e.orderIDSeen
+		e.orderMu.Unlock()
+		if err := e.awaitOrderID(seen); err != nil {
+			return 0, err
+		}
+		e.orderMu.Lock()
+	}
+	defer e.orderMu.Unlock()
+	id := e.orderID
+	e.orderID++
+	return id, nil
+}
+
+// SyncOrderID re-synchronises the order ID sequence with the gateway, blocking
+// until the NextValidID replying to a RequestIDs has been applied.
+func (e *Engine) SyncOrderID() error {
+	e.orderMu.Lock()
+	seen := e.orderIDSeen
+	e.orderMu.Unlock()
+	if err := e.Send(&RequestIDs{}); err != nil {
+		return err
+	}
+	return e.awaitOrderID(seen)
+}
+
+func (e *Engine) awaitOrderID(seen chan struct{}) error {
+	select {
+	case <-seen:
+		return nil
+	case <-e.terminated:
+		if err := e.FatalError(); err != nil {
+			return err
+		}
+		return fmt.Errorf("Engine has already exited normally")
+	case <-time.After(orderIDTimeout):
+		return fmt.Errorf("%s sent no NextValidID within %s", e.ConnectionInfo(), orderIDTimeout)
+	}
+}
+
+// observeOrderID maintains the order ID sequence. It runs on the main loop.
+func (e *Engine) observeOrderID(r Reply) {
+	switch r := r.(type) {
+	case *NextValidID:
+		e.orderMu.Lock()
+		defer e.orderMu.Unlock()
+		if r.OrderID > e.orderID {
+			e.orderID = r.OrderID
+		}
+		close(e.orderIDSeen)
+		e.orderIDSeen = make(chan struct{})
+	case *ErrorMessage:
+		if r.Code != errorDuplicateOrderID {
+			return
+		}
+		e.orderMu.Lock()
+		if r.ID() >= e.orderID {
+			e.orderID = r.ID() + 1
+		}
+		e.orderMu.Unlock()
+		// ask for the gateway's view too (asynchronously, as Send may block
+		// awaiting a reconnect which only this loop can complete)
+		go e.Send(&RequestIDs{})
+	}
+}
+
+// DuplicateOrderIDError reports an order rejected because its ID was already
+// used (ErrorMessage code 103). The engine's order ID sequence has already
+// been advanced, so the order can be placed again with a new NextOrderID.
+type DuplicateOrderIDError struct {
+	OrderID int64
+}
+
+func (d *DuplicateOrderIDError) Error() string {
+	return fmt.Sprintf("goib: duplicate order ID %d", d.OrderID)
+}
+
+// HistoricalScheduler returns the scheduler pacing this engine's historical
+// data requests.
+func (e *Engine) HistoricalScheduler() *HistoricalScheduler {
+	return e.historical
+}
+
 // ClientID .
 func (e *Engine) ClientID() int64 {
 	return e.client
@@ -387,6 +671,8 @@ func (e *Engine) sendCommand(c func()) {
 }
 
 // Subscribe will notify subscribers of future events with given id.
+// Subscriptions survive engine reconnections and repeating a subscription
+// has no further effect.
 // Many request types implement MatchedRequest and therefore provide a SetID().
 // To receive the corresponding MatchedReply events, firstly subscribe with the
 // same id as will be assigned with SetID(). Any incoming events that do not
@@ -405,6 +691,11 @@ func (e *Engine) Subscribe(o chan<- Reply, id int64) {
 			return
 		}
 
+		for _, existing := range e.unObservers {
+			if existing == o {
+				return
+			}
+		}
 		e.unObservers = append(e.unObservers, o)
 	})
 }
@@ -439,7 +730,7 @@ func (e *Engine) Unsubscribe(o chan Reply, id int64) {
 		newUnObs := []chan<- Reply{}
 		for _, existing := range e.unObservers {
 			if existing != o {
-				newUnObs = append(newUnObs, o)
+				newUnObs = append(newUnObs, existing)
 			}
 		}
 		e.unObservers = newUnObs
@@ -463,7 +754,7 @@ func (e *Engine) UnsubscribeAll(o chan Reply) {
 		newUnObs := []chan<- Reply{}
 		for _, existing := range e.allObservers {
 			if existing != o {
-				newUnObs = append(newUnObs, o)
+				newUnObs = append(newUnObs, existing)
 			}
 		}
 		e.allObservers = newUnObs
@@ -472,13 +763,18 @@ func (e *Engine) UnsubscribeAll(o chan Reply) {
 }
 
 // SubscribeState will register an engine state subscriber that is notified when
-// the engine exits for any reason. The engine will close the channel after use.
+// the engine exits for any reason. If reconnection is enabled, the subscriber is
+// also sent EngineReconnecting when the connection is lost and EngineReconnected
+// once the engine is ready again (at which point previously sent requests must
+// be repeated, as the gateway has forgotten them). States are delivered without
+// blocking the engine, so a subscriber which falls behind only receives the
+// latest state.
 // This call will block until the subscriber is registered or engine terminates.
 func (e *Engine) SubscribeState(o chan<- EngineState) {
 	if o == nil {
 		return
 	}
-	e.sendCommand(func() { e.stObservers = append(e.stObservers, o) })
+	e.sendCommand(func() { e.stObservers = append(e.stObservers, newStateObserver(o)) })
 }
 
 // UnsubscribeState blocks until the observer is removed. It also maintains a
@@ -496,11 +792,13 @@ func (e *Engine) UnsubscribeState(o chan EngineState) {
 		}
 	}()
 	e.sendCommand(func() {
-		var r []chan<- EngineState
+		var r []*stateObserver
 		for _, exist := range e.stObservers {
-			if exist != o {
-				r = append(r, exist)
+			if exist.ch == o {
+				close(exist.done)
+				continue
 			}
+			r = append(r, exist)
 		}
 		e.stObservers = r
 	})
@@ -554,14 +852,16 @@ func (v *header) read(b *bufio.Reader) error {
 // Send a message to the engine, blocking until sent or the engine exits.
 // This method will return an error if the UnmatchedReplyID is used or the
 // engine exits. A nil error indicates successful transmission. Any transmission
-// failure (eg connectivity loss) will cause the engine to exit with an error.
+// failure (eg connectivity loss) will cause the engine to exit with an error,
+// or to reconnect if so configured. While reconnecting, Send blocks until the
+// new connection is ready.
 func (e *Engine) Send(r Request) error {
 	if mr, ok := r.(MatchedRequest); ok {
 		if mr.ID() == UnmatchedReplyID {
 			return fmt.Errorf("%d is a reserved ID (try using NextRequestID)", UnmatchedReplyID)
 		}
 	}
-	t := txrequest{r, make(chan struct{})}
+	t := txrequest{r, make(chan error, 1)}
 
 	// send tx request
 	select {
@@ -580,8 +880,8 @@ func (e *Engine) Send(r Request) error {
 			return err
 		}
 		return fmt.Errorf("Engine has already exited normally")
-	case <-t.ack:
-		return nil
+	case err := <-t.ack:
+		return err
 	}
 }
 
@@ -661,6 +961,8 @@ const (
 	EngineReady EngineState = 1 << iota
 	EngineExitError
 	EngineExitNormal
+	EngineReconnecting
+	EngineReconnected
 )
 
 func (s EngineState) String() string {
@@ -671,6 +973,10 @@ func (s EngineState) String() string {
 		return "EngineExitError"
 	case EngineExitNormal:
 		return "EngineExitNormal"
+	case EngineReconnecting:
+		return "EngineReconnecting"
+	case EngineReconnected:
+		return "EngineReconnected"
 	default:
 		panic("unreachable")
 	}
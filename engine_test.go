@@ -1,10 +1,13 @@
 package ib
 
 import (
+	"bufio"
 	"errors"
 	"flag"
+	"net"
 	"os"
 	"reflect"
+	"strconv"
 	"testing"
 	"time"
 )
@@ -144,3 +147,101 @@ func logreply(t *testing.T, reply Reply, err error) {
 	}
 	t.Logf("\n")
 }
+
+// acceptHandshake accepts a connection on l and performs the server side of the
+// handshake, returning the connection and the fields of the StartAPI message.
+func acceptHandshake(t *testing.T, l net.Listener) (net.Conn, []string) {
+	conn, err := l.Accept()
+	if err != nil {
+		t.Fatalf("cannot accept: %v", err)
+	}
+	r := bufio.NewReader(conn)
+	if _, err := readString(r); err != nil {
+		t.Fatalf("cannot read client version: %v", err)
+	}
+	b := makebuf()
+	writeInt(b, minServerVersion)
+	writeTime(b, time.Now(), timeWriteLocalTime)
+	if _, err := conn.Write(b.Bytes()); err != nil {
+		t.Fatalf("cannot write server handshake: %v", err)
+	}
+	var fields []string
+	for i := 0; i < 3; i++ {
+		f, err := readString(r)
+		if err != nil {
+			t.Fatalf("cannot read StartAPI: %v", err)
+		}
+		fields = append(fields, f)
+	}
+	return conn, fields
+}
+
+func TestReconnect(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("cannot listen: %v", err)
+	}
+	defer l.Close()
+
+	conns := make(chan net.Conn)
+	starts := make(chan []string)
+	go func() {
+		for i := 0; i < 2; i++ {
+			conn, fields := acceptHandshake(t, l)
+			starts <- fields
+			conns <- conn
+		}
+	}()
+
+	opts := EngineOptions{
+		Gateway:   l.Addr().String(),
+		Reconnect: true,
+		Backoff:   func(int) time.Duration { return 10 * time.Millisecond },
+	}
+	engine, err := NewEngine(opts)
+	if err != nil {
+		t.Fatalf("cannot connect engine: %s", err)
+	}
+	defer engine.Stop()
+
+	<-starts
+	first := <-conns
+
+	states := make(chan EngineState)
+	engine.SubscribeState(states)
+
+	// simulate a gateway reset
+	first.Close()
+
+	if s := <-states; s != EngineReconnecting {
+		t.Fatalf("expected %v, got %v", EngineReconnecting, s)
+	}
+	start := <-starts
+	second := <-conns
+	defer second.Close()
+	if s := <-states; s != EngineReconnected {
+		t.Fatalf("expected %v, got %v", EngineReconnected, s)
+	}
+
+	if start[2] != strconv.FormatInt(engine.ClientID(), 10) {
+		t.Fatalf("expected StartAPI for client %d, got %v", engine.ClientID(), start)
+	}
+	if engine.State() != EngineReady {
+		t.Fatalf("engine state %v after reconnect", engine.State())
+	}
+
+	engine.Stop()
+	if s := <-states; s != EngineExitNormal {
+		t.Fatalf("expected %v, got %v", EngineExitNormal, s)
+	}
+}
+
+func TestExponentialBackoff(t *testing.T) {
+	b := ExponentialBackoff(time.Second, 5*time.Second)
+	expected := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for i, d := range expected {
+		if got := b(i + 1); got != d {
+			t.Fatalf("attempt %d: expected %v, got %v", i+1, d, got)
+		}
+	}
+}
@@ -2,12 +2,19 @@ package ib
 
 import (
 	"bufio"
+	"bytes"
 	"fmt"
 	"math"
+	"strconv"
+	"strings"
 	"time"
 )
 
 // This file ports IB API EReader.java. Please preserve declaration order.
+//
+// Replies which can be encoded (for package ibtest and friends) implement
+// write as the exact inverse of read. As readInt/readFloat decode empty fields
+// to math.MaxInt64/math.MaxFloat64, write uses writeMaxInt/writeMaxFloat.
 
 // IncomingMessageID .
 type IncomingMessageID int64
@@ -165,6 +172,7 @@ type TickPrice struct {
 
 // ID contains the TWS "tickerId", which was nominated at market data request time.
 func (t *TickPrice) ID() int64               { return t.id }
+func (t *TickPrice) SetID(id int64)          { t.id = id }
 func (t *TickPrice) code() IncomingMessageID { return mTickPrice }
 func (t *TickPrice) read(b *bufio.Reader) (err error) {
 	if t.id, err = readInt(b); err != nil {
@@ -183,6 +191,16 @@ func (t *TickPrice) read(b *bufio.Reader) (err error) {
 	return err
 }
 
+func (t *TickPrice) write(b *bytes.Buffer) error {
+	return (writeMapSlice{
+		{fct: writeMaxInt, val: t.id},
+		{fct: writeMaxInt, val: t.Type},
+		{fct: writeMaxFloat, val: t.Price},
+		{fct: writeMaxInt, val: t.Size},
+		{fct: writeBool, val: t.CanAutoExecute},
+	}).Dump(b)
+}
+
 // TickSize .
 type TickSize struct {
 	id   int64
@@ -192,6 +210,7 @@ type TickSize struct {
 
 // ID contains the TWS "tickerId", which was nominated at market data request time.
 func (t *TickSize) ID() int64               { return t.id }
+func (t *TickSize) SetID(id int64)          { t.id = id }
 func (t *TickSize) code() IncomingMessageID { return mTickSize }
 func (t *TickSize) read(b *bufio.Reader) (err error) {
 	if t.id, err = readInt(b); err != nil {
@@ -204,6 +223,14 @@ func (t *TickSize) read(b *bufio.Reader) (err error) {
 	return err
 }
 
+func (t *TickSize) write(b *bytes.Buffer) error {
+	return (writeMapSlice{
+		{fct: writeMaxInt, val: t.id},
+		{fct: writeMaxInt, val: t.Type},
+		{fct: writeMaxInt, val: t.Size},
+	}).Dump(b)
+}
+
 // TickOptionComputation .
 type TickOptionComputation struct {
 	id          int64
@@ -220,6 +247,7 @@ type TickOptionComputation struct {
 
 // ID contains the TWS "tickerId", which was nominated at market data request time.
 func (t *TickOptionComputation) ID() int64               { return t.id }
+func (t *TickOptionComputation) SetID(id int64)          { t.id = id }
 func (t *TickOptionComputation) code() IncomingMessageID { return mTickOptionComputation }
 func (t *TickOptionComputation) read(b *bufio.Reader) error {
 	var err error
@@ -257,6 +285,21 @@ func (t *TickOptionComputation) read(b *bufio.Reader) error {
 	return nil
 }
 
+func (t *TickOptionComputation) write(b *bytes.Buffer) error {
+	return (writeMapSlice{
+		{fct: writeMaxInt, val: t.id},
+		{fct: writeMaxInt, val: t.Type},
+		{fct: writeMaxFloat, val: t.ImpliedVol},
+		{fct: writeMaxFloat, val: t.Delta},
+		{fct: writeMaxFloat, val: t.OptionPrice},
+		{fct: writeMaxFloat, val: t.PvDividend},
+		{fct: writeMaxFloat, val: t.Gamma},
+		{fct: writeMaxFloat, val: t.Vega},
+		{fct: writeMaxFloat, val: t.Theta},
+		{fct: writeMaxFloat, val: t.SpotPrice},
+	}).Dump(b)
+}
+
 // TickGeneric .
 type TickGeneric struct {
 	id    int64
@@ -266,6 +309,7 @@ type TickGeneric struct {
 
 // ID contains the TWS "tickerId", which was nominated at market data request time.
 func (t *TickGeneric) ID() int64               { return t.id }
+func (t *TickGeneric) SetID(id int64)          { t.id = id }
 func (t *TickGeneric) code() IncomingMessageID { return mTickGeneric }
 func (t *TickGeneric) read(b *bufio.Reader) (err error) {
 	if t.id, err = readInt(b); err != nil {
@@ -278,6 +322,14 @@ func (t *TickGeneric) read(b *bufio.Reader) (err error) {
 	return err
 }
 
+func (t *TickGeneric) write(b *bytes.Buffer) error {
+	return (writeMapSlice{
+		{fct: writeMaxInt, val: t.id},
+		{fct: writeMaxInt, val: t.Type},
+		{fct: writeMaxFloat, val: t.Value},
+	}).Dump(b)
+}
+
 // TickString .
 type TickString struct {
 	id    int64
@@ -287,6 +339,7 @@ type TickString struct {
 
 // ID contains the TWS "tickerId", which was nominated at market data request time.
 func (t *TickString) ID() int64               { return t.id }
+func (t *TickString) SetID(id int64)          { t.id = id }
 func (t *TickString) code() IncomingMessageID { return mTickString }
 func (t *TickString) read(b *bufio.Reader) (err error) {
 	if t.id, err = readInt(b); err != nil {
@@ -299,6 +352,14 @@ func (t *TickString) read(b *bufio.Reader) (err error) {
 	return err
 }
 
+func (t *TickString) write(b *bytes.Buffer) error {
+	return (writeMapSlice{
+		{fct: writeMaxInt, val: t.id},
+		{fct: writeMaxInt, val: t.Type},
+		{fct: writeString, val: t.Value},
+	}).Dump(b)
+}
+
 // TickEFP .
 type TickEFP struct {
 	id                   int64
@@ -314,6 +375,7 @@ type TickEFP struct {
 
 // ID contains the TWS "tickerId", which was nominated at market data request time.
 func (t *TickEFP) ID() int64               { return t.id }
+func (t *TickEFP) SetID(id int64)          { t.id = id }
 func (t *TickEFP) code() IncomingMessageID { return mTickEFP }
 func (t *TickEFP) read(b *bufio.Reader) error {
 	var err error
@@ -348,6 +410,20 @@ func (t *TickEFP) read(b *bufio.Reader) error {
 	return nil
 }
 
+func (t *TickEFP) write(b *bytes.Buffer) error {
+	return (writeMapSlice{
+		{fct: writeMaxInt, val: t.id},
+		{fct: writeMaxInt, val: t.Type},
+		{fct: writeMaxFloat, val: t.BasisPoints},
+		{fct: writeString, val: t.FormattedBasisPoints},
+		{fct: writeMaxFloat, val: t.ImpliedFuturesPrice},
+		{fct: writeMaxInt, val: t.HoldDays},
+		{fct: writeString, val: t.FuturesExpiry},
+		{fct: writeMaxFloat, val: t.DividendImpact},
+		{fct: writeMaxFloat, val: t.DividendsToExpiry},
+	}).Dump(b)
+}
+
 // OrderStatus .
 type OrderStatus struct {
 	id               int64
@@ -364,6 +440,7 @@ type OrderStatus struct {
 
 // ID contains the TWS order "id", which was nominated when the order was placed.
 func (o *OrderStatus) ID() int64               { return o.id }
+func (o *OrderStatus) SetID(id int64)          { o.id = id }
 func (o *OrderStatus) code() IncomingMessageID { return mOrderStatus }
 func (o *OrderStatus) read(b *bufio.Reader) (err error) {
 	if o.id, err = readInt(b); err != nil {
@@ -397,6 +474,21 @@ func (o *OrderStatus) read(b *bufio.Reader) (err error) {
 	return err
 }
 
+func (o *OrderStatus) write(b *bytes.Buffer) error {
+	return (writeMapSlice{
+		{fct: writeMaxInt, val: o.id},
+		{fct: writeString, val: o.Status},
+		{fct: writeMaxInt, val: o.Filled},
+		{fct: writeMaxInt, val: o.Remaining},
+		{fct: writeMaxFloat, val: o.AverageFillPrice},
+		{fct: writeMaxInt, val: o.PermID},
+		{fct: writeMaxInt, val: o.ParentID},
+		{fct: writeMaxFloat, val: o.LastFillPrice},
+		{fct: writeMaxInt, val: o.ClientID},
+		{fct: writeString, val: o.WhyHeld},
+	}).Dump(b)
+}
+
 // AccountValue .
 type AccountValue struct {
 	Key      AccountValueKey
@@ -429,6 +521,15 @@ func (a *AccountValue) read(b *bufio.Reader) error {
 	return nil
 }
 
+func (a *AccountValue) write(b *bytes.Buffer) error {
+	return (writeMapSlice{
+		{fct: writeString, val: a.Key.Key},
+		{fct: writeString, val: a.Value},
+		{fct: writeString, val: a.Currency},
+		{fct: writeString, val: a.Key.AccountCode},
+	}).Dump(b)
+}
+
 // PortfolioValue .
 type PortfolioValue struct {
 	Key           PortfolioValueKey
@@ -507,6 +608,29 @@ func (p *PortfolioValue) read(b *bufio.Reader) (err error) {
 	return err
 }
 
+func (p *PortfolioValue) write(b *bytes.Buffer) error {
+	return (writeMapSlice{
+		{fct: writeMaxInt, val: p.Contract.ContractID},
+		{fct: writeString, val: p.Contract.Symbol},
+		{fct: writeString, val: p.Contract.SecurityType},
+		{fct: writeString, val: p.Contract.Expiry},
+		{fct: writeMaxFloat, val: p.Contract.Strike},
+		{fct: writeString, val: p.Contract.Right},
+		{fct: writeString, val: p.Contract.Multiplier},
+		{fct: writeString, val: p.Contract.PrimaryExchange},
+		{fct: writeString, val: p.Contract.Currency},
+		{fct: writeString, val: p.Contract.LocalSymbol},
+		{fct: writeString, val: p.Contract.TradingClass},
+		{fct: writeMaxInt, val: p.Position},
+		{fct: writeMaxFloat, val: p.MarketPrice},
+		{fct: writeMaxFloat, val: p.MarketValue},
+		{fct: writeMaxFloat, val: p.AverageCost},
+		{fct: writeMaxFloat, val: p.UnrealizedPNL},
+		{fct: writeMaxFloat, val: p.RealizedPNL},
+		{fct: writeString, val: p.Key.AccountCode},
+	}).Dump(b)
+}
+
 // AccountUpdateTime .
 type AccountUpdateTime struct {
 	Time time.Time
@@ -518,6 +642,10 @@ func (a *AccountUpdateTime) read(b *bufio.Reader) (err error) {
 	return err
 }
 
+func (a *AccountUpdateTime) write(b *bytes.Buffer) error {
+	return writeString(b, a.Time.Format("15:04:05"))
+}
+
 // ErrorMessage .
 type ErrorMessage struct {
 	id      int64
@@ -527,6 +655,7 @@ type ErrorMessage struct {
 
 // ID .
 func (e *ErrorMessage) ID() int64               { return e.id }
+func (e *ErrorMessage) SetID(id int64)          { e.id = id }
 func (e *ErrorMessage) code() IncomingMessageID { return mErrorMessage }
 func (e *ErrorMessage) read(b *bufio.Reader) (err error) {
 	if e.id, err = readInt(b); err != nil {
@@ -539,10 +668,25 @@ func (e *ErrorMessage) read(b *bufio.Reader) (err error) {
 	return err
 }
 
+func (e *ErrorMessage) write(b *bytes.Buffer) error {
+	return (writeMapSlice{
+		{fct: writeMaxInt, val: e.id},
+		{fct: writeMaxInt, val: e.Code},
+		{fct: writeString, val: e.Message},
+	}).Dump(b)
+}
+
 // SeverityWarning returns true if this error is of "warning" level.
 func (e *ErrorMessage) SeverityWarning() bool { return e.Code >= 2100 && e.Code <= 2110 }
 func (e *ErrorMessage) Error() error          { return fmt.Errorf("%s (%d/%d)", e.Message, e.id, e.Code) }
 
+// SeveritySystem returns true if this error is a system message or warning,
+// such as the loss or restoration of connectivity or a data farm, rather than
+// the failure of a request.
+func (e *ErrorMessage) SeveritySystem() bool {
+	return e.Code >= 1100 && e.Code <= 1300 || e.Code >= 2100 && e.Code <= 2169
+}
+
 // OpenOrder .
 type OpenOrder struct {
 	Order      Order
@@ -552,6 +696,7 @@ type OpenOrder struct {
 
 // ID contains the TWS "orderId", which was nominated when the order was placed.
 func (o *OpenOrder) ID() int64               { return o.Order.OrderID }
+func (o *OpenOrder) SetID(id int64)          { o.Order.OrderID = id }
 func (o *OpenOrder) code() IncomingMessageID { return mOpenOrder }
 func (o *OpenOrder) read(b *bufio.Reader) (err error) {
 	if o.Order.OrderID, err = readInt(b); err != nil {
@@ -793,7 +938,8 @@ func (o *OpenOrder) read(b *bufio.Reader) (err error) {
 		return err
 	}
 	o.Contract.ComboLegs = make([]ComboLeg, comboLegsCount)
-	for _, cl := range o.Contract.ComboLegs {
+	for i := range o.Contract.ComboLegs {
+		cl := &o.Contract.ComboLegs[i]
 		if cl.ContractID, err = readInt(b); err != nil {
 			return err
 		}
@@ -824,7 +970,8 @@ func (o *OpenOrder) read(b *bufio.Reader) (err error) {
 		return err
 	}
 	o.Order.OrderComboLegs = make([]OrderComboLeg, orderComboLegsCount)
-	for _, ocl := range o.Order.OrderComboLegs {
+	for i := range o.Order.OrderComboLegs {
+		ocl := &o.Order.OrderComboLegs[i]
 		if ocl.Price, err = readFloat(b); err != nil {
 			return err
 		}
@@ -834,7 +981,8 @@ func (o *OpenOrder) read(b *bufio.Reader) (err error) {
 		return err
 	}
 	o.Order.SmartComboRoutingParams = make([]TagValue, smartSize)
-	for _, sc := range o.Order.SmartComboRoutingParams {
+	for i := range o.Order.SmartComboRoutingParams {
+		sc := &o.Order.SmartComboRoutingParams[i]
 		if sc.Tag, err = readString(b); err != nil {
 			return err
 		}
@@ -919,13 +1067,15 @@ func (o *OpenOrder) read(b *bufio.Reader) (err error) {
 			return err
 		}
 		o.Order.AlgoParams.Params = make([]*TagValue, algoParamsCount)
-		for _, p := range o.Order.AlgoParams.Params {
-			if p.Tag, err = readString(b); err != nil {
+		for i := range o.Order.AlgoParams.Params {
+			tv := &TagValue{}
+			if tv.Tag, err = readString(b); err != nil {
 				return err
 			}
-			if p.Value, err = readString(b); err != nil {
+			if tv.Value, err = readString(b); err != nil {
 				return err
 			}
+			o.Order.AlgoParams.Params[i] = tv
 		}
 	}
 	if o.Order.WhatIf, err = readBool(b); err != nil {
@@ -959,6 +1109,208 @@ func (o *OpenOrder) read(b *bufio.Reader) (err error) {
 	return err
 }
 
+func (o *OpenOrder) write(b *bytes.Buffer) error {
+	if err := (writeMapSlice{
+		{fct: writeMaxInt, val: o.Order.OrderID},
+		{fct: writeMaxInt, val: o.Contract.ContractID},
+		{fct: writeString, val: o.Contract.Symbol},
+		{fct: writeString, val: o.Contract.SecurityType},
+		{fct: writeString, val: o.Contract.Expiry},
+		{fct: writeMaxFloat, val: o.Contract.Strike},
+		{fct: writeString, val: o.Contract.Right},
+		{fct: writeString, val: o.Contract.Multiplier},
+		{fct: writeString, val: o.Contract.Exchange},
+		{fct: writeString, val: o.Contract.Currency},
+		{fct: writeString, val: o.Contract.LocalSymbol},
+		{fct: writeString, val: o.Contract.TradingClass},
+		{fct: writeString, val: o.Order.Action},
+		{fct: writeMaxInt, val: o.Order.TotalQty},
+		{fct: writeString, val: o.Order.OrderType},
+		{fct: writeMaxFloat, val: o.Order.LimitPrice},
+		{fct: writeMaxFloat, val: o.Order.AuxPrice},
+		{fct: writeString, val: o.Order.TIF},
+		{fct: writeString, val: o.Order.OCAGroup},
+		{fct: writeString, val: o.Order.Account},
+		{fct: writeString, val: o.Order.OpenClose},
+		{fct: writeMaxInt, val: o.Order.Origin},
+		{fct: writeString, val: o.Order.OrderRef},
+		{fct: writeMaxInt, val: o.Order.ClientID},
+		{fct: writeMaxInt, val: o.Order.PermID},
+		{fct: writeBool, val: o.Order.OutsideRTH},
+		{fct: writeBool, val: o.Order.Hidden},
+		{fct: writeMaxFloat, val: o.Order.DiscretionaryAmount},
+		{fct: writeString, val: o.Order.GoodAfterTime},
+		{fct: writeString, val: ""}, // deprecated sharesAllocation field
+		{fct: writeString, val: o.Order.FAGroup},
+		{fct: writeString, val: o.Order.FAMethod},
+		{fct: writeString, val: o.Order.FAPercentage},
+		{fct: writeString, val: o.Order.FAProfile},
+		{fct: writeString, val: o.Order.GoodTillDate},
+		{fct: writeString, val: o.Order.Rule80A},
+		{fct: writeMaxFloat, val: o.Order.PercentOffset},
+		{fct: writeString, val: o.Order.SettlingFirm},
+		{fct: writeMaxInt, val: o.Order.ShortSaleSlot},
+		{fct: writeString, val: o.Order.DesignatedLocation},
+		{fct: writeMaxInt, val: o.Order.ExemptCode},
+		{fct: writeMaxInt, val: o.Order.AuctionStrategy},
+		{fct: writeMaxFloat, val: o.Order.StartingPrice},
+		{fct: writeMaxFloat, val: o.Order.StockRefPrice},
+		{fct: writeMaxFloat, val: o.Order.Delta},
+		{fct: writeMaxFloat, val: o.Order.StockRangeLower},
+		{fct: writeMaxFloat, val: o.Order.StockRangeUpper},
+		{fct: writeMaxInt, val: o.Order.DisplaySize},
+		{fct: writeBool, val: o.Order.BlockOrder},
+		{fct: writeBool, val: o.Order.SweepToFill},
+		{fct: writeBool, val: o.Order.AllOrNone},
+		{fct: writeMaxInt, val: o.Order.MinQty},
+		{fct: writeMaxInt, val: o.Order.OCAType},
+		{fct: writeMaxInt, val: o.Order.ETradeOnly},
+		{fct: writeBool, val: o.Order.FirmQuoteOnly},
+		{fct: writeMaxFloat, val: o.Order.NBBOPriceCap},
+		{fct: writeMaxInt, val: o.Order.ParentID},
+		{fct: writeMaxInt, val: o.Order.TriggerMethod},
+		{fct: writeMaxFloat, val: o.Order.Volatility},
+		{fct: writeMaxInt, val: o.Order.VolatilityType},
+		{fct: writeString, val: o.Order.DeltaNeutralOrderType},
+		{fct: writeMaxFloat, val: o.Order.DeltaNeutralAuxPrice},
+	}).Dump(b); err != nil {
+		return err
+	}
+	if o.Order.DeltaNeutralOrderType != "" {
+		if err := (writeMapSlice{
+			{fct: writeMaxInt, val: o.Order.DeltaNeutral.ContractID},
+			{fct: writeString, val: o.Order.DeltaNeutral.SettlingFirm},
+			{fct: writeString, val: o.Order.DeltaNeutral.ClearingAccount},
+			{fct: writeString, val: o.Order.DeltaNeutral.ClearingIntent},
+			{fct: writeString, val: o.Order.DeltaNeutral.OpenClose},
+			{fct: writeBool, val: o.Order.DeltaNeutral.ShortSale},
+			{fct: writeMaxInt, val: o.Order.DeltaNeutral.ShortSaleSlot},
+			{fct: writeString, val: o.Order.DeltaNeutral.DesignatedLocation},
+		}).Dump(b); err != nil {
+			return err
+		}
+	}
+	if err := (writeMapSlice{
+		{fct: writeMaxInt, val: o.Order.ContinuousUpdate},
+		{fct: writeMaxInt, val: o.Order.ReferencePriceType},
+		{fct: writeMaxFloat, val: o.Order.TrailStopPrice},
+		{fct: writeMaxFloat, val: o.Order.TrailingPercent},
+		{fct: writeMaxFloat, val: o.Order.BasisPoints},
+		{fct: writeMaxInt, val: o.Order.BasisPointsType},
+		{fct: writeString, val: o.Contract.ComboLegsDescription},
+		{fct: writeMaxInt, val: int64(len(o.Contract.ComboLegs))},
+	}).Dump(b); err != nil {
+		return err
+	}
+	for _, cl := range o.Contract.ComboLegs {
+		if err := (writeMapSlice{
+			{fct: writeMaxInt, val: cl.ContractID},
+			{fct: writeMaxInt, val: cl.Ratio},
+			{fct: writeString, val: cl.Action},
+			{fct: writeString, val: cl.Exchange},
+			{fct: writeMaxInt, val: cl.OpenClose},
+			{fct: writeMaxInt, val: cl.ShortSaleSlot},
+			{fct: writeString, val: cl.DesignatedLocation},
+			{fct: writeMaxInt, val: cl.ExemptCode},
+		}).Dump(b); err != nil {
+			return err
+		}
+	}
+	if err := writeMaxInt(b, int64(len(o.Order.OrderComboLegs))); err != nil {
+		return err
+	}
+	for _, ocl := range o.Order.OrderComboLegs {
+		if err := writeMaxFloat(b, ocl.Price); err != nil {
+			return err
+		}
+	}
+	if err := writeMaxInt(b, int64(len(o.Order.SmartComboRoutingParams))); err != nil {
+		return err
+	}
+	for _, tv := range o.Order.SmartComboRoutingParams {
+		if err := writeString(b, tv.Tag); err != nil {
+			return err
+		}
+		if err := writeString(b, tv.Value); err != nil {
+			return err
+		}
+	}
+	if err := (writeMapSlice{
+		{fct: writeMaxInt, val: o.Order.ScaleInitLevelSize},
+		{fct: writeMaxInt, val: o.Order.ScaleSubsLevelSize},
+		{fct: writeMaxFloat, val: o.Order.ScalePriceIncrement},
+	}).Dump(b); err != nil {
+		return err
+	}
+	if o.Order.ScalePriceIncrement > 0.0 && o.Order.ScalePriceIncrement < math.MaxFloat64 {
+		if err := (writeMapSlice{
+			{fct: writeMaxFloat, val: o.Order.ScalePriceAdjustValue},
+			{fct: writeMaxInt, val: o.Order.ScalePriceAdjustInterval},
+			{fct: writeMaxFloat, val: o.Order.ScaleProfitOffset},
+			{fct: writeBool, val: o.Order.ScaleAutoReset},
+			{fct: writeMaxInt, val: o.Order.ScaleInitPosition},
+			{fct: writeMaxInt, val: o.Order.ScaleInitFillQty},
+			{fct: writeBool, val: o.Order.ScaleRandomPercent},
+		}).Dump(b); err != nil {
+			return err
+		}
+	}
+	if err := writeString(b, o.Order.HedgeType); err != nil {
+		return err
+	}
+	if o.Order.HedgeType != "" {
+		if err := writeString(b, o.Order.HedgeParam); err != nil {
+			return err
+		}
+	}
+	if err := (writeMapSlice{
+		{fct: writeBool, val: o.Order.OptOutSmartRouting},
+		{fct: writeString, val: o.Order.ClearingAccount},
+		{fct: writeString, val: o.Order.ClearingIntent},
+		{fct: writeBool, val: o.Order.NotHeld},
+		{fct: writeBool, val: o.Contract.UnderComp != nil},
+	}).Dump(b); err != nil {
+		return err
+	}
+	if o.Contract.UnderComp != nil {
+		if err := (writeMapSlice{
+			{fct: writeMaxInt, val: o.Contract.UnderComp.ContractID},
+			{fct: writeMaxFloat, val: o.Contract.UnderComp.Delta},
+			{fct: writeMaxFloat, val: o.Contract.UnderComp.Price},
+		}).Dump(b); err != nil {
+			return err
+		}
+	}
+	if err := writeString(b, o.Order.AlgoStrategy); err != nil {
+		return err
+	}
+	if o.Order.AlgoStrategy != "" {
+		if err := writeMaxInt(b, int64(len(o.Order.AlgoParams.Params))); err != nil {
+			return err
+		}
+		for _, tv := range o.Order.AlgoParams.Params {
+			if err := writeString(b, tv.Tag); err != nil {
+				return err
+			}
+			if err := writeString(b, tv.Value); err != nil {
+				return err
+			}
+		}
+	}
+	return (writeMapSlice{
+		{fct: writeBool, val: o.Order.WhatIf},
+		{fct: writeString, val: o.OrderState.Status},
+		{fct: writeString, val: o.OrderState.InitialMargin},
+		{fct: writeString, val: o.OrderState.MaintenanceMargin},
+		{fct: writeString, val: o.OrderState.EquityWithLoan},
+		{fct: writeMaxFloat, val: o.OrderState.Commission},
+		{fct: writeMaxFloat, val: o.OrderState.MinCommission},
+		{fct: writeMaxFloat, val: o.OrderState.MaxCommission},
+		{fct: writeString, val: o.OrderState.CommissionCurrency},
+		{fct: writeString, val: o.OrderState.WarningText},
+	}).Dump(b)
+}
+
 // NextValidID .
 type NextValidID struct {
 	OrderID int64
@@ -970,6 +1322,8 @@ func (n *NextValidID) read(b *bufio.Reader) (err error) {
 	return err
 }
 
+func (n *NextValidID) write(b *bytes.Buffer) error { return writeMaxInt(b, n.OrderID) }
+
 // ScannerData .
 type ScannerData struct {
 	id     int64
@@ -978,6 +1332,7 @@ type ScannerData struct {
 
 // ID contains the TWS "reqId", which is used for reply correlation.
 func (s *ScannerData) ID() int64               { return s.id }
+func (s *ScannerData) SetID(id int64)          { s.id = id }
 func (s *ScannerData) code() IncomingMessageID { return mScannerData }
 func (s *ScannerData) read(b *bufio.Reader) (err error) {
 	if s.id, err = readInt(b); err != nil {
@@ -988,7 +1343,8 @@ func (s *ScannerData) read(b *bufio.Reader) (err error) {
 		return err
 	}
 	s.Detail = make([]ScannerDetail, size)
-	for _, sd := range s.Detail {
+	for i := range s.Detail {
+		sd := &s.Detail[i]
 		if sd.Rank, err = readInt(b); err != nil {
 			return err
 		}
@@ -1041,6 +1397,38 @@ func (s *ScannerData) read(b *bufio.Reader) (err error) {
 	return err
 }
 
+func (s *ScannerData) write(b *bytes.Buffer) error {
+	if err := (writeMapSlice{
+		{fct: writeMaxInt, val: s.id},
+		{fct: writeMaxInt, val: int64(len(s.Detail))},
+	}).Dump(b); err != nil {
+		return err
+	}
+	for _, sd := range s.Detail {
+		if err := (writeMapSlice{
+			{fct: writeMaxInt, val: sd.Rank},
+			{fct: writeMaxInt, val: sd.ContractID},
+			{fct: writeString, val: sd.Contract.Summary.Symbol},
+			{fct: writeString, val: sd.Contract.Summary.SecurityType},
+			{fct: writeString, val: sd.Contract.Summary.Expiry},
+			{fct: writeMaxFloat, val: sd.Contract.Summary.Strike},
+			{fct: writeString, val: sd.Contract.Summary.Right},
+			{fct: writeString, val: sd.Contract.Summary.Exchange},
+			{fct: writeString, val: sd.Contract.Summary.Currency},
+			{fct: writeString, val: sd.Contract.Summary.LocalSymbol},
+			{fct: writeString, val: sd.Contract.MarketName},
+			{fct: writeString, val: sd.Contract.Summary.TradingClass},
+			{fct: writeString, val: sd.Distance},
+			{fct: writeString, val: sd.Benchmark},
+			{fct: writeString, val: sd.Projection},
+			{fct: writeString, val: sd.Legs},
+		}).Dump(b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // ContractData .
 type ContractData struct {
 	id       int64
@@ -1049,6 +1437,7 @@ type ContractData struct {
 
 // ID contains the TWS "reqId", which is used for reply correlation.
 func (c *ContractData) ID() int64               { return c.id }
+func (c *ContractData) SetID(id int64)          { c.id = id }
 func (c *ContractData) code() IncomingMessageID { return mContractData }
 func (c *ContractData) read(b *bufio.Reader) (err error) {
 	if c.id, err = readInt(b); err != nil {
@@ -1159,6 +1548,52 @@ func (c *ContractData) read(b *bufio.Reader) (err error) {
 	return err
 }
 
+func (c *ContractData) write(b *bytes.Buffer) error {
+	if err := (writeMapSlice{
+		{fct: writeMaxInt, val: c.id},
+		{fct: writeString, val: c.Contract.Summary.Symbol},
+		{fct: writeString, val: c.Contract.Summary.SecurityType},
+		{fct: writeString, val: c.Contract.Summary.Expiry},
+		{fct: writeMaxFloat, val: c.Contract.Summary.Strike},
+		{fct: writeString, val: c.Contract.Summary.Right},
+		{fct: writeString, val: c.Contract.Summary.Exchange},
+		{fct: writeString, val: c.Contract.Summary.Currency},
+		{fct: writeString, val: c.Contract.Summary.LocalSymbol},
+		{fct: writeString, val: c.Contract.MarketName},
+		{fct: writeString, val: c.Contract.Summary.TradingClass},
+		{fct: writeMaxInt, val: c.Contract.Summary.ContractID},
+		{fct: writeMaxFloat, val: c.Contract.MinTick},
+		{fct: writeString, val: c.Contract.Summary.Multiplier},
+		{fct: writeString, val: c.Contract.OrderTypes},
+		{fct: writeString, val: c.Contract.ValidExchanges},
+		{fct: writeMaxInt, val: c.Contract.PriceMagnifier},
+		{fct: writeMaxInt, val: c.Contract.UnderContractID},
+		{fct: writeString, val: c.Contract.LongName},
+		{fct: writeString, val: c.Contract.Summary.PrimaryExchange},
+		{fct: writeString, val: c.Contract.ContractMonth},
+		{fct: writeString, val: c.Contract.Industry},
+		{fct: writeString, val: c.Contract.Category},
+		{fct: writeString, val: c.Contract.Subcategory},
+		{fct: writeString, val: c.Contract.TimezoneID},
+		{fct: writeString, val: c.Contract.TradingHours},
+		{fct: writeString, val: c.Contract.LiquidHours},
+		{fct: writeString, val: c.Contract.EVRule},
+		{fct: writeMaxFloat, val: c.Contract.EVMultiplier},
+		{fct: writeMaxInt, val: int64(len(c.Contract.SecIDList))},
+	}).Dump(b); err != nil {
+		return err
+	}
+	for _, si := range c.Contract.SecIDList {
+		if err := writeString(b, si.Tag); err != nil {
+			return err
+		}
+		if err := writeString(b, si.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // BondContractData .
 type BondContractData struct {
 	id       int64
@@ -1167,6 +1602,7 @@ type BondContractData struct {
 
 // ID contains the TWS "reqId", which is used for reply correlation.
 func (bcd *BondContractData) ID() int64               { return bcd.id }
+func (bcd *BondContractData) SetID(id int64)          { bcd.id = id }
 func (bcd *BondContractData) code() IncomingMessageID { return mBondContractData }
 func (bcd *BondContractData) read(b *bufio.Reader) (err error) {
 	if bcd.id, err = readInt(b); err != nil {
@@ -1261,7 +1697,8 @@ func (bcd *BondContractData) read(b *bufio.Reader) (err error) {
 		return err
 	}
 	bcd.Contract.SecIDList = make([]TagValue, secIDListCount)
-	for _, si := range bcd.Contract.SecIDList {
+	for i := range bcd.Contract.SecIDList {
+		si := &bcd.Contract.SecIDList[i]
 		if si.Tag, err = readString(b); err != nil {
 			return err
 		}
@@ -1273,6 +1710,52 @@ func (bcd *BondContractData) read(b *bufio.Reader) (err error) {
 
 }
 
+func (bcd *BondContractData) write(b *bytes.Buffer) error {
+	if err := (writeMapSlice{
+		{fct: writeMaxInt, val: bcd.id},
+		{fct: writeString, val: bcd.Contract.Summary.Symbol},
+		{fct: writeString, val: bcd.Contract.Summary.SecurityType},
+		{fct: writeString, val: bcd.Contract.Cusip},
+		{fct: writeMaxFloat, val: bcd.Contract.Coupon},
+		{fct: writeString, val: bcd.Contract.Maturity},
+		{fct: writeString, val: bcd.Contract.IssueDate},
+		{fct: writeString, val: bcd.Contract.Ratings},
+		{fct: writeString, val: bcd.Contract.BondType},
+		{fct: writeString, val: bcd.Contract.CouponType},
+		{fct: writeBool, val: bcd.Contract.Convertible},
+		{fct: writeBool, val: bcd.Contract.Callable},
+		{fct: writeBool, val: bcd.Contract.Putable},
+		{fct: writeString, val: bcd.Contract.DescAppend},
+		{fct: writeString, val: bcd.Contract.Summary.Exchange},
+		{fct: writeString, val: bcd.Contract.Summary.Currency},
+		{fct: writeString, val: bcd.Contract.MarketName},
+		{fct: writeString, val: bcd.Contract.TradingClass},
+		{fct: writeMaxInt, val: bcd.Contract.Summary.ContractID},
+		{fct: writeMaxFloat, val: bcd.Contract.MinTick},
+		{fct: writeString, val: bcd.Contract.OrderTypes},
+		{fct: writeString, val: bcd.Contract.ValidExchanges},
+		{fct: writeString, val: bcd.Contract.NextOptionDate},
+		{fct: writeString, val: bcd.Contract.NextOptionType},
+		{fct: writeBool, val: bcd.Contract.NextOptionPartial},
+		{fct: writeString, val: bcd.Contract.Notes},
+		{fct: writeString, val: bcd.Contract.LongName},
+		{fct: writeString, val: bcd.Contract.EVRule},
+		{fct: writeMaxFloat, val: bcd.Contract.EVMultiplier},
+		{fct: writeMaxInt, val: int64(len(bcd.Contract.SecIDList))},
+	}).Dump(b); err != nil {
+		return err
+	}
+	for _, si := range bcd.Contract.SecIDList {
+		if err := writeString(b, si.Tag); err != nil {
+			return err
+		}
+		if err := writeString(b, si.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // ExecutionData .
 type ExecutionData struct {
 	id       int64
@@ -1282,6 +1765,7 @@ type ExecutionData struct {
 
 // ID contains the TWS "reqId", which is used for reply correlation.
 func (e *ExecutionData) ID() int64               { return e.id }
+func (e *ExecutionData) SetID(id int64)          { e.id = id }
 func (e *ExecutionData) code() IncomingMessageID { return mExecutionData }
 func (e *ExecutionData) read(b *bufio.Reader) (err error) {
 	if e.id, err = readInt(b); err != nil {
@@ -1369,6 +1853,39 @@ func (e *ExecutionData) read(b *bufio.Reader) (err error) {
 	return err
 }
 
+func (e *ExecutionData) write(b *bytes.Buffer) error {
+	return (writeMapSlice{
+		{fct: writeMaxInt, val: e.id},
+		{fct: writeMaxInt, val: e.Exec.OrderID},
+		{fct: writeMaxInt, val: e.Contract.ContractID},
+		{fct: writeString, val: e.Contract.Symbol},
+		{fct: writeString, val: e.Contract.SecurityType},
+		{fct: writeString, val: e.Contract.Expiry},
+		{fct: writeMaxFloat, val: e.Contract.Strike},
+		{fct: writeString, val: e.Contract.Right},
+		{fct: writeString, val: e.Contract.Multiplier},
+		{fct: writeString, val: e.Contract.Exchange},
+		{fct: writeString, val: e.Contract.Currency},
+		{fct: writeString, val: e.Contract.LocalSymbol},
+		{fct: writeString, val: e.Contract.TradingClass},
+		{fct: writeString, val: e.Exec.ExecID},
+		{fct: writeTime, val: e.Exec.Time, extra: timeWriteLocalTime},
+		{fct: writeString, val: e.Exec.AccountCode},
+		{fct: writeString, val: e.Exec.Exchange},
+		{fct: writeString, val: e.Exec.Side},
+		{fct: writeMaxInt, val: e.Exec.Shares},
+		{fct: writeMaxFloat, val: e.Exec.Price},
+		{fct: writeMaxInt, val: e.Exec.PermID},
+		{fct: writeMaxInt, val: e.Exec.ClientID},
+		{fct: writeMaxInt, val: e.Exec.Liquidation},
+		{fct: writeMaxInt, val: e.Exec.CumQty},
+		{fct: writeMaxFloat, val: e.Exec.AveragePrice},
+		{fct: writeString, val: e.Exec.OrderRef},
+		{fct: writeString, val: e.Exec.EVRule},
+		{fct: writeMaxFloat, val: e.Exec.EVMultiplier},
+	}).Dump(b)
+}
+
 // MarketDepth .
 type MarketDepth struct {
 	id        int64
@@ -1381,6 +1898,7 @@ type MarketDepth struct {
 
 // ID contains the TWS "tickerId", which was nominated at market data request time.
 func (m *MarketDepth) ID() int64               { return m.id }
+func (m *MarketDepth) SetID(id int64)          { m.id = id }
 func (m *MarketDepth) code() IncomingMessageID { return mMarketDepth }
 func (m *MarketDepth) read(b *bufio.Reader) (err error) {
 	if m.id, err = readInt(b); err != nil {
@@ -1402,6 +1920,17 @@ func (m *MarketDepth) read(b *bufio.Reader) (err error) {
 	return err
 }
 
+func (m *MarketDepth) write(b *bytes.Buffer) error {
+	return (writeMapSlice{
+		{fct: writeMaxInt, val: m.id},
+		{fct: writeMaxInt, val: m.Position},
+		{fct: writeMaxInt, val: m.Operation},
+		{fct: writeMaxInt, val: m.Side},
+		{fct: writeMaxFloat, val: m.Price},
+		{fct: writeMaxInt, val: m.Size},
+	}).Dump(b)
+}
+
 // MarketDepthL2 .
 type MarketDepthL2 struct {
 	id          int64
@@ -1415,6 +1944,7 @@ type MarketDepthL2 struct {
 
 // ID contains the TWS "tickerId", which was nominated at market data request time.
 func (m *MarketDepthL2) ID() int64               { return m.id }
+func (m *MarketDepthL2) SetID(id int64)          { m.id = id }
 func (m *MarketDepthL2) code() IncomingMessageID { return mMarketDepthL2 }
 func (m *MarketDepthL2) read(b *bufio.Reader) (err error) {
 	if m.id, err = readInt(b); err != nil {
@@ -1439,6 +1969,18 @@ func (m *MarketDepthL2) read(b *bufio.Reader) (err error) {
 	return err
 }
 
+func (m *MarketDepthL2) write(b *bytes.Buffer) error {
+	return (writeMapSlice{
+		{fct: writeMaxInt, val: m.id},
+		{fct: writeMaxInt, val: m.Position},
+		{fct: writeString, val: m.MarketMaker},
+		{fct: writeMaxInt, val: m.Operation},
+		{fct: writeMaxInt, val: m.Side},
+		{fct: writeMaxFloat, val: m.Price},
+		{fct: writeMaxInt, val: m.Size},
+	}).Dump(b)
+}
+
 // NewsBulletins .
 type NewsBulletins struct {
 	NewsMsgID int64
@@ -1462,6 +2004,15 @@ func (n *NewsBulletins) read(b *bufio.Reader) (err error) {
 	return err
 }
 
+func (n *NewsBulletins) write(b *bytes.Buffer) error {
+	return (writeMapSlice{
+		{fct: writeMaxInt, val: n.NewsMsgID},
+		{fct: writeMaxInt, val: n.Type},
+		{fct: writeString, val: n.Message},
+		{fct: writeString, val: n.Exchange},
+	}).Dump(b)
+}
+
 // ManagedAccounts .
 type ManagedAccounts struct {
 	AccountsList []string
@@ -1473,6 +2024,10 @@ func (m *ManagedAccounts) read(b *bufio.Reader) (err error) {
 	return err
 }
 
+func (m *ManagedAccounts) write(b *bytes.Buffer) error {
+	return writeString(b, strings.Join(m.AccountsList, ","))
+}
+
 // ReceiveFA .
 type ReceiveFA struct {
 	Type int64
@@ -1488,6 +2043,13 @@ func (r *ReceiveFA) read(b *bufio.Reader) (err error) {
 	return err
 }
 
+func (r *ReceiveFA) write(b *bytes.Buffer) error {
+	return (writeMapSlice{
+		{fct: writeMaxInt, val: r.Type},
+		{fct: writeString, val: r.XML},
+	}).Dump(b)
+}
+
 // HistoricalData .
 type HistoricalData struct {
 	id        int64
@@ -1498,6 +2060,7 @@ type HistoricalData struct {
 
 // ID contains the TWS "reqId", which is used for reply correlation.
 func (h *HistoricalData) ID() int64               { return h.id }
+func (h *HistoricalData) SetID(id int64)          { h.id = id }
 func (h *HistoricalData) code() IncomingMessageID { return mHistoricalData }
 func (h *HistoricalData) read(b *bufio.Reader) (err error) {
 	if h.id, err = readInt(b); err != nil {
@@ -1549,6 +2112,33 @@ func (h *HistoricalData) read(b *bufio.Reader) (err error) {
 	return err
 }
 
+func (h *HistoricalData) write(b *bytes.Buffer) error {
+	if err := (writeMapSlice{
+		{fct: writeMaxInt, val: h.id},
+		{fct: writeString, val: h.StartDate},
+		{fct: writeString, val: h.EndDate},
+		{fct: writeMaxInt, val: int64(len(h.Data))},
+	}).Dump(b); err != nil {
+		return err
+	}
+	for _, d := range h.Data {
+		if err := (writeMapSlice{
+			{fct: writeMaxInt, val: d.Date.Unix()},
+			{fct: writeMaxFloat, val: d.Open},
+			{fct: writeMaxFloat, val: d.High},
+			{fct: writeMaxFloat, val: d.Low},
+			{fct: writeMaxFloat, val: d.Close},
+			{fct: writeMaxInt, val: d.Volume},
+			{fct: writeMaxFloat, val: d.WAP},
+			{fct: writeString, val: strconv.FormatBool(d.HasGaps)},
+			{fct: writeMaxInt, val: d.BarCount},
+		}).Dump(b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // ScannerParameters .
 type ScannerParameters struct {
 	XML string
@@ -1560,6 +2150,8 @@ func (s *ScannerParameters) read(b *bufio.Reader) (err error) {
 	return err
 }
 
+func (s *ScannerParameters) write(b *bytes.Buffer) error { return writeString(b, s.XML) }
+
 // CurrentTime .
 type CurrentTime struct {
 	Time time.Time
@@ -1571,6 +2163,8 @@ func (c *CurrentTime) read(b *bufio.Reader) (err error) {
 	return err
 }
 
+func (c *CurrentTime) write(b *bytes.Buffer) error { return writeMaxInt(b, c.Time.Unix()) }
+
 // RealtimeBars .
 type RealtimeBars struct {
 	id     int64
@@ -1586,6 +2180,7 @@ type RealtimeBars struct {
 
 // ID contains the TWS "reqId", which is used for reply correlation.
 func (r *RealtimeBars) ID() int64               { return r.id }
+func (r *RealtimeBars) SetID(id int64)          { r.id = id }
 func (r *RealtimeBars) code() IncomingMessageID { return mRealtimeBars }
 func (r *RealtimeBars) read(b *bufio.Reader) (err error) {
 	if r.id, err = readInt(b); err != nil {
@@ -1616,6 +2211,20 @@ func (r *RealtimeBars) read(b *bufio.Reader) (err error) {
 	return err
 }
 
+func (r *RealtimeBars) write(b *bytes.Buffer) error {
+	return (writeMapSlice{
+		{fct: writeMaxInt, val: r.id},
+		{fct: writeMaxInt, val: r.Time},
+		{fct: writeMaxFloat, val: r.Open},
+		{fct: writeMaxFloat, val: r.High},
+		{fct: writeMaxFloat, val: r.Low},
+		{fct: writeMaxFloat, val: r.Close},
+		{fct: writeMaxFloat, val: r.Volume},
+		{fct: writeMaxFloat, val: r.WAP},
+		{fct: writeMaxInt, val: r.Count},
+	}).Dump(b)
+}
+
 // FundamentalData .
 type FundamentalData struct {
 	id   int64
@@ -1624,6 +2233,7 @@ type FundamentalData struct {
 
 // ID contains the TWS "reqId", which is used for reply correlation.
 func (f *FundamentalData) ID() int64               { return f.id }
+func (f *FundamentalData) SetID(id int64)          { f.id = id }
 func (f *FundamentalData) code() IncomingMessageID { return mFundamentalData }
 func (f *FundamentalData) read(b *bufio.Reader) (err error) {
 	if f.id, err = readInt(b); err != nil {
@@ -1633,6 +2243,13 @@ func (f *FundamentalData) read(b *bufio.Reader) (err error) {
 	return err
 }
 
+func (f *FundamentalData) write(b *bytes.Buffer) error {
+	return (writeMapSlice{
+		{fct: writeMaxInt, val: f.id},
+		{fct: writeString, val: f.Data},
+	}).Dump(b)
+}
+
 // ContractDataEnd .
 type ContractDataEnd struct {
 	id int64
@@ -1640,15 +2257,20 @@ type ContractDataEnd struct {
 
 // ID contains the TWS "reqId", which is used for reply correlation.
 func (c *ContractDataEnd) ID() int64                        { return c.id }
+func (c *ContractDataEnd) SetID(id int64)                   { c.id = id }
 func (c *ContractDataEnd) code() IncomingMessageID          { return mContractDataEnd }
 func (c *ContractDataEnd) read(b *bufio.Reader) (err error) { c.id, err = readInt(b); return err }
 
+func (c *ContractDataEnd) write(b *bytes.Buffer) error { return writeMaxInt(b, c.id) }
+
 // OpenOrderEnd .
 type OpenOrderEnd struct{}
 
 func (o *OpenOrderEnd) code() IncomingMessageID    { return mOpenOrderEnd }
 func (o *OpenOrderEnd) read(b *bufio.Reader) error { return nil }
 
+func (o *OpenOrderEnd) write(b *bytes.Buffer) error { return nil }
+
 // AccountDownloadEnd .
 type AccountDownloadEnd struct {
 	Account string
@@ -1660,6 +2282,8 @@ func (a *AccountDownloadEnd) read(b *bufio.Reader) (err error) {
 	return err
 }
 
+func (a *AccountDownloadEnd) write(b *bytes.Buffer) error { return writeString(b, a.Account) }
+
 // ExecutionDataEnd .
 type ExecutionDataEnd struct {
 	id int64
@@ -1667,9 +2291,12 @@ type ExecutionDataEnd struct {
 
 // ID contains the TWS "reqId", which is used for reply correlation.
 func (e *ExecutionDataEnd) ID() int64                        { return e.id }
+func (e *ExecutionDataEnd) SetID(id int64)                   { e.id = id }
 func (e *ExecutionDataEnd) code() IncomingMessageID          { return mExecutionDataEnd }
 func (e *ExecutionDataEnd) read(b *bufio.Reader) (err error) { e.id, err = readInt(b); return err }
 
+func (e *ExecutionDataEnd) write(b *bytes.Buffer) error { return writeMaxInt(b, e.id) }
+
 // DeltaNeutralValidation .
 type DeltaNeutralValidation struct {
 	id        int64
@@ -1678,6 +2305,7 @@ type DeltaNeutralValidation struct {
 
 // ID .
 func (d *DeltaNeutralValidation) ID() int64               { return d.id }
+func (d *DeltaNeutralValidation) SetID(id int64)          { d.id = id }
 func (d *DeltaNeutralValidation) code() IncomingMessageID { return mDeltaNeutralValidation }
 func (d *DeltaNeutralValidation) read(b *bufio.Reader) (err error) {
 	if d.id, err = readInt(b); err != nil {
@@ -1693,6 +2321,15 @@ func (d *DeltaNeutralValidation) read(b *bufio.Reader) (err error) {
 	return err
 }
 
+func (d *DeltaNeutralValidation) write(b *bytes.Buffer) error {
+	return (writeMapSlice{
+		{fct: writeMaxInt, val: d.id},
+		{fct: writeMaxInt, val: d.UnderComp.ContractID},
+		{fct: writeMaxFloat, val: d.UnderComp.Delta},
+		{fct: writeMaxFloat, val: d.UnderComp.Price},
+	}).Dump(b)
+}
+
 // TickSnapshotEnd .
 type TickSnapshotEnd struct {
 	id int64
@@ -1700,9 +2337,12 @@ type TickSnapshotEnd struct {
 
 // ID contains the TWS "reqId", which is used for reply correlation.
 func (t *TickSnapshotEnd) ID() int64                        { return t.id }
+func (t *TickSnapshotEnd) SetID(id int64)                   { t.id = id }
 func (t *TickSnapshotEnd) code() IncomingMessageID          { return mTickSnapshotEnd }
 func (t *TickSnapshotEnd) read(b *bufio.Reader) (err error) { t.id, err = readInt(b); return err }
 
+func (t *TickSnapshotEnd) write(b *bytes.Buffer) error { return writeMaxInt(b, t.id) }
+
 // MarketDataType .
 type MarketDataType struct {
 	id   int64
@@ -1711,6 +2351,7 @@ type MarketDataType struct {
 
 // ID contains the TWS "reqId", which is used for reply correlation.
 func (m *MarketDataType) ID() int64               { return m.id }
+func (m *MarketDataType) SetID(id int64)          { m.id = id }
 func (m *MarketDataType) code() IncomingMessageID { return mMarketDataType }
 func (m *MarketDataType) read(b *bufio.Reader) (err error) {
 	if m.id, err = readInt(b); err != nil {
@@ -1720,6 +2361,13 @@ func (m *MarketDataType) read(b *bufio.Reader) (err error) {
 	return err
 }
 
+func (m *MarketDataType) write(b *bytes.Buffer) error {
+	return (writeMapSlice{
+		{fct: writeMaxInt, val: m.id},
+		{fct: writeMaxInt, val: m.Type},
+	}).Dump(b)
+}
+
 // Position .
 type Position struct {
 	Key         PositionKey
@@ -1780,12 +2428,33 @@ func (p *Position) read(b *bufio.Reader) (err error) {
 	return err
 }
 
+func (p *Position) write(b *bytes.Buffer) error {
+	return (writeMapSlice{
+		{fct: writeString, val: p.Key.AccountCode},
+		{fct: writeMaxInt, val: p.Contract.ContractID},
+		{fct: writeString, val: p.Contract.Symbol},
+		{fct: writeString, val: p.Contract.SecurityType},
+		{fct: writeString, val: p.Contract.Expiry},
+		{fct: writeMaxFloat, val: p.Contract.Strike},
+		{fct: writeString, val: p.Contract.Right},
+		{fct: writeString, val: p.Contract.Multiplier},
+		{fct: writeString, val: p.Contract.Exchange},
+		{fct: writeString, val: p.Contract.Currency},
+		{fct: writeString, val: p.Contract.LocalSymbol},
+		{fct: writeString, val: p.Contract.TradingClass},
+		{fct: writeMaxFloat, val: p.Position},
+		{fct: writeMaxFloat, val: p.AverageCost},
+	}).Dump(b)
+}
+
 // PositionEnd .
 type PositionEnd struct{}
 
 func (p *PositionEnd) code() IncomingMessageID    { return mPositionEnd }
 func (p *PositionEnd) read(b *bufio.Reader) error { return nil }
 
+func (p *PositionEnd) write(b *bytes.Buffer) error { return nil }
+
 // AccountSummary .
 type AccountSummary struct {
 	id       int64
@@ -1802,6 +2471,7 @@ type AccountSummaryKey struct {
 
 // ID contains the TWS "reqId", which is used for reply correlation.
 func (a *AccountSummary) ID() int64               { return a.id }
+func (a *AccountSummary) SetID(id int64)          { a.id = id }
 func (a *AccountSummary) code() IncomingMessageID { return mAccountSummary }
 func (a *AccountSummary) read(b *bufio.Reader) error {
 	var err error
@@ -1824,6 +2494,16 @@ func (a *AccountSummary) read(b *bufio.Reader) error {
 	return nil
 }
 
+func (a *AccountSummary) write(b *bytes.Buffer) error {
+	return (writeMapSlice{
+		{fct: writeMaxInt, val: a.id},
+		{fct: writeString, val: a.Key.AccountCode},
+		{fct: writeString, val: a.Key.Key},
+		{fct: writeString, val: a.Value},
+		{fct: writeString, val: a.Currency},
+	}).Dump(b)
+}
+
 // AccountSummaryEnd .
 type AccountSummaryEnd struct {
 	id int64
@@ -1831,9 +2511,12 @@ type AccountSummaryEnd struct {
 
 // ID contains tha TWS "reqId", which is used for reply correlation.
 func (a *AccountSummaryEnd) ID() int64                        { return a.id }
+func (a *AccountSummaryEnd) SetID(id int64)                   { a.id = id }
 func (a *AccountSummaryEnd) code() IncomingMessageID          { return mAccountSummaryEnd }
 func (a *AccountSummaryEnd) read(b *bufio.Reader) (err error) { a.id, err = readInt(b); return err }
 
+func (a *AccountSummaryEnd) write(b *bytes.Buffer) error { return writeMaxInt(b, a.id) }
+
 // VerifyMessageAPI .
 type VerifyMessageAPI struct {
 	APIData string
@@ -1845,6 +2528,8 @@ func (v *VerifyMessageAPI) read(b *bufio.Reader) (err error) {
 	return err
 }
 
+func (v *VerifyMessageAPI) write(b *bytes.Buffer) error { return writeString(b, v.APIData) }
+
 // VerifyCompleted .
 type VerifyCompleted struct {
 	Successful bool
@@ -1865,6 +2550,13 @@ func (v *VerifyCompleted) read(b *bufio.Reader) (err error) {
 	return fmt.Errorf("Verification complete received; GoIB already started")
 }
 
+func (v *VerifyCompleted) write(b *bytes.Buffer) error {
+	return (writeMapSlice{
+		{fct: writeString, val: strconv.FormatBool(v.Successful)},
+		{fct: writeString, val: v.ErrorText},
+	}).Dump(b)
+}
+
 // DisplayGroupList .
 type DisplayGroupList struct {
 	id     int64
@@ -1873,6 +2565,7 @@ type DisplayGroupList struct {
 
 // ID contains tha TWS "reqId", which is used for reply correlation.
 func (d *DisplayGroupList) ID() int64               { return d.id }
+func (d *DisplayGroupList) SetID(id int64)          { d.id = id }
 func (d *DisplayGroupList) code() IncomingMessageID { return mDisplayGroupList }
 func (d *DisplayGroupList) read(b *bufio.Reader) (err error) {
 	if d.id, err = readInt(b); err != nil {
@@ -1882,6 +2575,17 @@ func (d *DisplayGroupList) read(b *bufio.Reader) (err error) {
 	return err
 }
 
+func (d *DisplayGroupList) write(b *bytes.Buffer) error {
+	groups := make([]string, len(d.Groups))
+	for i, g := range d.Groups {
+		groups[i] = strconv.Itoa(g)
+	}
+	return (writeMapSlice{
+		{fct: writeMaxInt, val: d.id},
+		{fct: writeString, val: strings.Join(groups, "|")},
+	}).Dump(b)
+}
+
 // DisplayGroupUpdated .
 type DisplayGroupUpdated struct {
 	id           int64
@@ -1890,6 +2594,7 @@ type DisplayGroupUpdated struct {
 
 // ID contains tha TWS "reqId", which is used for reply correlation.
 func (d *DisplayGroupUpdated) ID() int64               { return d.id }
+func (d *DisplayGroupUpdated) SetID(id int64)          { d.id = id }
 func (d *DisplayGroupUpdated) code() IncomingMessageID { return mDisplayGroupUpdated }
 func (d *DisplayGroupUpdated) read(b *bufio.Reader) (err error) {
 	if d.id, err = readInt(b); err != nil {
@@ -1898,3 +2603,10 @@ func (d *DisplayGroupUpdated) read(b *bufio.Reader) (err error) {
 	d.ContractInfo, err = readString(b)
 	return err
 }
+
+func (d *DisplayGroupUpdated) write(b *bytes.Buffer) error {
+	return (writeMapSlice{
+		{fct: writeMaxInt, val: d.id},
+		{fct: writeString, val: d.ContractInfo},
+	}).Dump(b)
+}
@@ -0,0 +1,411 @@
+package ib
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"math"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const roundTrips = 100
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// randomize fills the exported fields of v with random, wire-safe values.
+// Unless maxFloat is set, math.MaxFloat64 is not used (requests write most
+// floats with writeFloat, which cannot encode it).
+func randomize(r *rand.Rand, v reflect.Value, maxFloat bool) {
+	if v.Type() == timeType {
+		// epoch seconds must not be mistaken for dates by timeReadAutoDetect
+		v.Set(reflect.ValueOf(time.Unix(1e9+r.Int63n(1e9), r.Int63n(1e9))))
+		return
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		v.SetBool(r.Intn(2) == 1)
+	case reflect.Int, reflect.Int64:
+		switch r.Intn(8) {
+		case 0:
+			v.SetInt(math.MaxInt64)
+		case 1:
+			v.SetInt(0)
+		default:
+			v.SetInt(r.Int63n(2e6) - 1e6)
+		}
+	case reflect.Float64:
+		switch r.Intn(8) {
+		case 0:
+			if maxFloat {
+				v.SetFloat(math.MaxFloat64)
+			}
+		case 1:
+			v.SetFloat(0)
+		default:
+			v.SetFloat(r.NormFloat64() * 1e3)
+		}
+	case reflect.String:
+		const chars = "abcXYZ019 .,:|-_" // no tag=value; separators
+		s := make([]byte, r.Intn(8))
+		for i := range s {
+			s[i] = chars[r.Intn(len(chars))]
+		}
+		v.SetString(string(s))
+	case reflect.Ptr:
+		if r.Intn(2) == 0 {
+			v.Set(reflect.Zero(v.Type()))
+			return
+		}
+		v.Set(reflect.New(v.Type().Elem()))
+		randomize(r, v.Elem(), maxFloat)
+	case reflect.Slice:
+		s := reflect.MakeSlice(v.Type(), r.Intn(4), 4)
+		for i := 0; i < s.Len(); i++ {
+			e := s.Index(i)
+			if e.Kind() == reflect.Ptr {
+				e.Set(reflect.New(e.Type().Elem()))
+				e = e.Elem()
+			}
+			randomize(r, e, maxFloat)
+		}
+		v.Set(s)
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).PkgPath == "" {
+				randomize(r, v.Field(i), maxFloat)
+			}
+		}
+	}
+}
+
+// roundTrip writes x, reads the result into a new value of the same type and
+// returns the decoded value and the bytes written.
+func roundTrip(t *testing.T, x interface {
+	readable
+	writable
+}) (interface{}, []byte) {
+	b := makebuf()
+	if err := x.write(b); err != nil {
+		t.Fatalf("%T: failed to write: %v", x, err)
+	}
+	encoded := append([]byte(nil), b.Bytes()...)
+
+	y := reflect.New(reflect.TypeOf(x).Elem()).Interface().(readable)
+	r := bufio.NewReader(bytes.NewReader(encoded))
+	if err := y.read(r); err != nil {
+		t.Fatalf("%T: failed to read %q: %v", x, encoded, err)
+	}
+	if r.Buffered() > 0 {
+		t.Fatalf("%T: %d bytes left unread in %q", x, r.Buffered(), encoded)
+	}
+	return y, encoded
+}
+
+// contractOnly lists the Contract fields sent separately from, or not at all
+// by, messages which identify a single contract.
+var contractOnly = []string{"ComboLegs", "ComboLegsDescription", "IncludeExpired", "SecIDType", "SecID", "UnderComp"}
+
+func fieldPaths(prefix string, names ...string) []string {
+	paths := make([]string, len(names))
+	for i, n := range names {
+		paths[i] = prefix + "." + n
+	}
+	return paths
+}
+
+func concat(lists ...[]string) []string {
+	var all []string
+	for _, l := range lists {
+		all = append(all, l...)
+	}
+	return all
+}
+
+// wireExceptions lists, by type, the fields the wire format cannot carry (as
+// field paths without slice indexes). The IB protocol version goib speaks does
+// not send them for that message, so testRoundTrip does not compare them.
+var wireExceptions = map[string][]string{
+	// only the hours and minutes are sent
+	"AccountUpdateTime": {".Time"},
+	// bonds are described by their CUSIP, coupon and maturity instead
+	"BondContractData": fieldPaths(".Contract.Summary", append(contractOnly,
+		"Expiry", "Strike", "Right", "Multiplier", "PrimaryExchange", "LocalSymbol", "TradingClass")...),
+	// contract details send identifiers in SecIDList instead
+	"ContractData":    fieldPaths(".Contract.Summary", contractOnly...),
+	"ExecutionData":   fieldPaths(".Contract", append(contractOnly, "PrimaryExchange")...),
+	"ExerciseOptions": fieldPaths(".Contract", append(contractOnly, "PrimaryExchange")...),
+	"OpenOrder": concat(
+		fieldPaths(".Contract", "IncludeExpired", "PrimaryExchange", "SecIDType", "SecID"),
+		fieldPaths(".Order", "ActiveStartTime", "ActiveStopTime", "OrderMiscOptions",
+			"OverridePercentageConstraints", "ScaleTable", "Transmit"),
+	),
+	// the order ID is sent as the request ID, and IB assigns the PermID
+	"PlaceOrder": concat(
+		fieldPaths(".Contract", "ComboLegsDescription", "IncludeExpired"),
+		fieldPaths(".Order", "OrderID", "ClientID", "PermID", "BasisPoints", "BasisPointsType"),
+	),
+	"PortfolioValue":         fieldPaths(".Contract", append(contractOnly, "Exchange")...),
+	"Position":               fieldPaths(".Contract", append(contractOnly, "PrimaryExchange")...),
+	"RequestCalcImpliedVol":  fieldPaths(".Contract", contractOnly...),
+	"RequestCalcOptionPrice": fieldPaths(".Contract", contractOnly...),
+	"RequestContractData":    fieldPaths(".Contract", "ComboLegs", "ComboLegsDescription", "UnderComp", "PrimaryExchange"),
+	"RequestFundamentalData": fieldPaths(".Contract", append(contractOnly,
+		"Expiry", "Strike", "Right", "Multiplier", "TradingClass")...),
+	"RequestHistoricalData": fieldPaths(".Contract", contractOnly...),
+	// market data requests send their combo legs (briefly) and UnderComp
+	// separately
+	"RequestMarketData": concat(
+		fieldPaths(".Contract", contractOnly...),
+		fieldPaths(".ComboLegs", "OpenClose", "ShortSaleSlot", "DesignatedLocation", "ExemptCode"),
+	),
+	"RequestMarketDepth":  fieldPaths(".Contract", append(contractOnly, "PrimaryExchange")...),
+	"RequestRealTimeBars": fieldPaths(".Contract", contractOnly...),
+	// scanner rows only describe the contract briefly (its ContractID is sent
+	// in ScannerDetail.ContractID)
+	"ScannerData": concat(
+		fieldPaths(".Detail.Contract", "MinTick", "PriceMagnifier", "OrderTypes", "ValidExchanges",
+			"UnderContractID", "LongName", "ContractMonth", "Industry", "Category", "Subcategory",
+			"TimezoneID", "TradingHours", "LiquidHours", "EVRule", "EVMultiplier", "SecIDList"),
+		fieldPaths(".Detail.Contract.Summary", append(contractOnly,
+			"ContractID", "Multiplier", "PrimaryExchange")...),
+	),
+}
+
+// wireForm reduces x to what the wire carries where that depends on other
+// fields of x: fields the writer only sends under a condition x does not meet
+// are cleared, and fields the reader derives from others are set from them.
+func wireForm(x interface{}) {
+	order := func(o *Order) {
+		if o.DeltaNeutralOrderType == "" {
+			o.DeltaNeutral = DeltaNeutralData{}
+		}
+		if !(o.ScalePriceIncrement > 0 && o.ScalePriceIncrement < math.MaxFloat64) {
+			o.ScalePriceAdjustValue = 0
+			o.ScalePriceAdjustInterval = 0
+			o.ScaleProfitOffset = 0
+			o.ScaleAutoReset = false
+			o.ScaleInitPosition = 0
+			o.ScaleInitFillQty = 0
+			o.ScaleRandomPercent = false
+		}
+		if o.HedgeType == "" {
+			o.HedgeParam = ""
+		}
+		if o.AlgoStrategy == "" {
+			o.AlgoParams.Params = nil
+		}
+	}
+
+	switch x := x.(type) {
+	case *OpenOrder:
+		order(&x.Order)
+	case *PlaceOrder:
+		order(&x.Order)
+		if x.Contract.SecurityType != bagSecType {
+			x.Contract.ComboLegs = nil
+			x.Order.OrderComboLegs = nil
+			x.Order.SmartComboRoutingParams = nil
+		}
+	case *RequestMarketData:
+		if x.Contract.SecurityType != bagSecType {
+			x.ComboLegs = nil
+		}
+	case *PortfolioValue:
+		x.Key.ContractID = x.Contract.ContractID
+	case *Position:
+		x.Key.ContractID = x.Contract.ContractID
+	case *ManagedAccounts:
+		// accounts are sent comma separated, so an empty list reads back
+		// as one empty account
+		for i, a := range x.AccountsList {
+			x.AccountsList[i] = strings.Replace(a, ",", "", -1)
+		}
+		if len(x.AccountsList) == 0 {
+			x.AccountsList = []string{""}
+		}
+	}
+}
+
+// floatDigits is the number of significant digits writeFloat sends.
+const floatDigits = 10
+
+// wireDiff appends the differences between want and got to diffs, skipping the
+// fields in skip (keyed by path without slice indexes). Floats are compared to
+// the precision writeFloat sends and times to the second (the finest
+// resolution of every IB time format), as the wire rounds them.
+func wireDiff(diffs []string, skip map[string]bool, key string, path string, want reflect.Value, got reflect.Value) []string {
+	if skip[key] {
+		return diffs
+	}
+	mismatch := func() []string {
+		return append(diffs, fmt.Sprintf("%s: want %#v, got %#v", path, want.Interface(), got.Interface()))
+	}
+	if want.Type() == timeType {
+		w, g := want.Interface().(time.Time), got.Interface().(time.Time)
+		if !w.Truncate(time.Second).Equal(g) {
+			return mismatch()
+		}
+		return diffs
+	}
+	switch want.Kind() {
+	case reflect.Float64:
+		w, g := want.Float(), got.Float()
+		if w != g && math.Abs(w-g) > math.Abs(w)*math.Pow10(1-floatDigits)/2 {
+			return mismatch()
+		}
+	case reflect.Ptr:
+		if want.IsNil() || got.IsNil() {
+			if want.IsNil() != got.IsNil() {
+				return mismatch()
+			}
+			return diffs
+		}
+		return wireDiff(diffs, skip, key, path, want.Elem(), got.Elem())
+	case reflect.Slice:
+		if want.Len() != got.Len() {
+			return mismatch()
+		}
+		for i := 0; i < want.Len(); i++ {
+			diffs = wireDiff(diffs, skip, key, fmt.Sprintf("%s[%d]", path, i), want.Index(i), got.Index(i))
+		}
+	case reflect.Struct:
+		for i := 0; i < want.NumField(); i++ {
+			if f := want.Type().Field(i); f.PkgPath == "" {
+				diffs = wireDiff(diffs, skip, key+"."+f.Name, path+"."+f.Name, want.Field(i), got.Field(i))
+			}
+		}
+	default:
+		if want.Interface() != got.Interface() {
+			return mismatch()
+		}
+	}
+	return diffs
+}
+
+// testRoundTrip checks read(write(x)) == x for a random value of x, except
+// for the fields listed in wireExceptions. Combo contracts are chosen for a
+// quarter of the values, so the combo fields are sent.
+func testRoundTrip(t *testing.T, r *rand.Rand, x interface {
+	readable
+	writable
+}, maxFloat bool) {
+	randomize(r, reflect.ValueOf(x).Elem(), maxFloat)
+	switch m := x.(type) {
+	case MatchedReply:
+		m.SetID(r.Int63n(1e6))
+	case MatchedRequest:
+		m.SetID(r.Int63n(1e6))
+	}
+	if d, ok := x.(*DisplayGroupList); ok && len(d.Groups) == 0 {
+		d.Groups = []int{r.Intn(10)}
+	}
+	if r.Intn(4) == 0 {
+		switch x := x.(type) {
+		case *PlaceOrder:
+			x.Contract.SecurityType = bagSecType
+		case *RequestMarketData:
+			x.Contract.SecurityType = bagSecType
+		}
+	}
+	wireForm(x)
+
+	y, encoded := roundTrip(t, x)
+	if mx, ok := x.(MatchedReply); ok && mx.ID() != y.(MatchedReply).ID() {
+		t.Fatalf("%T: ID %d read as %d", x, mx.ID(), y.(MatchedReply).ID())
+	}
+	if mx, ok := x.(MatchedRequest); ok && mx.ID() != y.(MatchedRequest).ID() {
+		t.Fatalf("%T: ID %d read as %d", x, mx.ID(), y.(MatchedRequest).ID())
+	}
+	typ := reflect.TypeOf(x).Elem().Name()
+	skip := map[string]bool{}
+	for _, f := range wireExceptions[typ] {
+		skip[f] = true
+	}
+	if diffs := wireDiff(nil, skip, "", "", reflect.ValueOf(x).Elem(), reflect.ValueOf(y).Elem()); len(diffs) > 0 {
+		t.Fatalf("%T: read(write(x)) != x, from %q\n%s", x, encoded, strings.Join(diffs, "\n"))
+	}
+}
+
+func TestReplyRoundTrip(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for code := int64(0); code < 100; code++ {
+		if _, err := code2Msg(code); err != nil {
+			continue
+		}
+		for i := 0; i < roundTrips; i++ {
+			x, _ := code2Msg(code)
+			if _, ok := x.(*VerifyCompleted); ok {
+				break // read always fails (see TestVerifyCompleted)
+			}
+			testRoundTrip(t, r, x, true)
+		}
+	}
+}
+
+func TestVerifyCompleted(t *testing.T) {
+	x := &VerifyCompleted{Successful: true, ErrorText: "none"}
+	b := makebuf()
+	if err := x.write(b); err != nil {
+		t.Fatal(err)
+	}
+	y := &VerifyCompleted{}
+	// goib does not support API verification, so reading reports an error
+	if err := y.read(bufio.NewReader(bytes.NewReader(b.Bytes()))); err == nil {
+		t.Fatal("expected verification error")
+	}
+	if *y != *x {
+		t.Fatalf("expected %+v, got %+v", x, y)
+	}
+}
+
+func TestRequestRoundTrip(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for code := int64(0); code < 100; code++ {
+		if _, err := code2Req(code); err != nil {
+			continue
+		}
+		for i := 0; i < roundTrips; i++ {
+			x, _ := code2Req(code)
+			testRoundTrip(t, r, x, false)
+		}
+	}
+}
+
+func TestOpenOrderComboLegs(t *testing.T) {
+	x := &OpenOrder{}
+	x.Contract.ComboLegs = []ComboLeg{{ContractID: 1, Ratio: 2, Action: "BUY", Exchange: "SMART"}}
+	x.Order.OrderComboLegs = []OrderComboLeg{{Price: 1.5}}
+	x.Order.SmartComboRoutingParams = []TagValue{{Tag: "NonGuaranteed", Value: "1"}}
+	x.Order.AlgoStrategy = "Adaptive"
+	x.Order.AlgoParams.Params = []*TagValue{{Tag: "adaptivePriority", Value: "Normal"}}
+
+	y, _ := roundTrip(t, x)
+	o := y.(*OpenOrder)
+	if o.Contract.ComboLegs[0].Exchange != "SMART" || o.Contract.ComboLegs[0].Ratio != 2 {
+		t.Fatalf("combo leg not decoded: %+v", o.Contract.ComboLegs)
+	}
+	if o.Order.OrderComboLegs[0].Price != 1.5 {
+		t.Fatalf("order combo leg not decoded: %+v", o.Order.OrderComboLegs)
+	}
+	if o.Order.SmartComboRoutingParams[0].Value != "1" {
+		t.Fatalf("smart combo routing params not decoded: %+v", o.Order.SmartComboRoutingParams)
+	}
+	if o.Order.AlgoParams.Params[0].Value != "Normal" {
+		t.Fatalf("algo params not decoded: %+v", o.Order.AlgoParams.Params[0])
+	}
+}
+
+func TestScannerDataDetail(t *testing.T) {
+	x := &ScannerData{Detail: []ScannerDetail{{Rank: 1, ContractID: 8314, Distance: "d"}, {Rank: 2, ContractID: 265598}}}
+	x.Detail[0].Contract.Summary.Symbol = "IBM"
+
+	y, _ := roundTrip(t, x)
+	d := y.(*ScannerData).Detail
+	if len(d) != 2 || d[0].ContractID != 8314 || d[0].Contract.Summary.Symbol != "IBM" || d[1].Rank != 2 {
+		t.Fatalf("scanner rows not decoded: %+v", d)
+	}
+}
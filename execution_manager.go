@@ -20,7 +20,7 @@ func NewExecutionManager(e *Engine, filter ExecutionFilter) (*ExecutionManager,
 	}
 
 	em := &ExecutionManager{AbstractManager: *am,
-		id:     UnmatchedReplyID,
+		id:     e.NextRequestID(),
 		filter: filter,
 	}
 
@@ -29,7 +29,10 @@ func NewExecutionManager(e *Engine, filter ExecutionFilter) (*ExecutionManager,
 }
 
 func (e *ExecutionManager) preLoop() error {
-	e.id = e.eng.NextRequestID()
+	e.rwm.Lock()
+	e.values = nil
+	e.rwm.Unlock()
+
 	e.eng.Subscribe(e.rc, e.id)
 	req := &RequestExecutions{Filter: e.filter}
 	req.SetID(e.id)
@@ -0,0 +1,442 @@
+package ib
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Fundamental data report types, as used in RequestFundamentalData.ReportType.
+const (
+	ReportTypeSnapshot       = "ReportSnapshot"
+	ReportTypeFinSummary     = "ReportsFinSummary"
+	ReportTypeRatios         = "ReportRatios"
+	ReportTypeFinStatements  = "ReportsFinStatements"
+	ReportTypeEstimates      = "RESC"
+	ReportTypeCalendarReport = "CalendarReport"
+)
+
+// ReportDate is a date (and optionally time) in a fundamental data report.
+type ReportDate struct {
+	time.Time
+}
+
+var reportDateLayouts = []string{"2006-01-02", "2006-01-02T15:04:05", "01/02/2006"}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (d *ReportDate) UnmarshalText(text []byte) (err error) {
+	if len(text) == 0 {
+		d.Time = time.Time{}
+		return nil
+	}
+	for _, layout := range reportDateLayouts {
+		if d.Time, err = time.Parse(layout, string(text)); err == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("goib: unknown report date format %q", text)
+}
+
+// ReportRatio is a named value, such as a ratio or forecast.
+type ReportRatio struct {
+	Group string  `xml:"-"`
+	Field string  `xml:"FieldName,attr"`
+	Type  string  `xml:"Type,attr"` // N (number), D (date) or S (string)
+	Value string  `xml:",chardata"`
+	Float float64 `xml:"-"`
+}
+
+type reportRatioGroup struct {
+	ID     string        `xml:"ID,attr"`
+	Ratios []ReportRatio `xml:"Ratio"`
+}
+
+// flattenRatios returns the ratios of the groups, parsing numeric values.
+func flattenRatios(groups []reportRatioGroup) (ratios []ReportRatio) {
+	for _, g := range groups {
+		for _, r := range g.Ratios {
+			r.Group = g.ID
+			if r.Type == "N" {
+				r.Float, _ = strconv.ParseFloat(strings.TrimSpace(r.Value), 64)
+			}
+			ratios = append(ratios, r)
+		}
+	}
+	return ratios
+}
+
+// findRatio returns the ratio with the given field name.
+func findRatio(ratios []ReportRatio, field string) (ReportRatio, bool) {
+	for _, r := range ratios {
+		if r.Field == field {
+			return r, true
+		}
+	}
+	return ReportRatio{}, false
+}
+
+// CompanySnapshot is a ReportSnapshot report.
+type CompanySnapshot struct {
+	IDs []struct {
+		Type  string `xml:"Type,attr"`
+		Value string `xml:",chardata"`
+	} `xml:"CoIDs>CoID"`
+	Employees         int64      `xml:"CoGeneralInfo>Employees"`
+	SharesOut         float64    `xml:"CoGeneralInfo>SharesOut"`
+	ReportingCurrency string     `xml:"CoGeneralInfo>ReportingCurrency"`
+	LatestAnnual      ReportDate `xml:"CoGeneralInfo>LatestAvailableAnnual"`
+	LatestInterim     ReportDate `xml:"CoGeneralInfo>LatestAvailableInterim"`
+	Texts             []struct {
+		Type  string `xml:"Type,attr"`
+		Value string `xml:",chardata"`
+	} `xml:"TextInfo>Text"`
+	Industries []struct {
+		Type  string `xml:"type,attr"`
+		Code  string `xml:"code,attr"`
+		Value string `xml:",chardata"`
+	} `xml:"peerInfo>IndustryInfo>Industry"`
+	Ratios    []ReportRatio `xml:"-"`
+	Forecasts []ReportRatio `xml:"-"` // grouped by period type (eg "CURR")
+}
+
+// ParseCompanySnapshot decodes a ReportSnapshot report.
+func ParseCompanySnapshot(data string) (*CompanySnapshot, error) {
+	var x struct {
+		CompanySnapshot
+		Groups    []reportRatioGroup `xml:"Ratios>Group"`
+		Forecasts []struct {
+			Field  string `xml:"FieldName,attr"`
+			Type   string `xml:"Type,attr"`
+			Values []struct {
+				PeriodType string `xml:"PeriodType,attr"`
+				Value      string `xml:",chardata"`
+			} `xml:"Value"`
+		} `xml:"ForecastData>Ratio"`
+	}
+	if err := xml.Unmarshal([]byte(data), &x); err != nil {
+		return nil, err
+	}
+	s := &x.CompanySnapshot
+	s.Ratios = flattenRatios(x.Groups)
+	var forecasts []reportRatioGroup
+	for _, f := range x.Forecasts {
+		for _, v := range f.Values {
+			forecasts = append(forecasts, reportRatioGroup{
+				ID:     v.PeriodType,
+				Ratios: []ReportRatio{{Field: f.Field, Type: f.Type, Value: v.Value}},
+			})
+		}
+	}
+	s.Forecasts = flattenRatios(forecasts)
+	return s, nil
+}
+
+// ID returns the company identifier of the given type (eg "CompanyName").
+func (s *CompanySnapshot) ID(typ string) string {
+	for _, id := range s.IDs {
+		if id.Type == typ {
+			return id.Value
+		}
+	}
+	return ""
+}
+
+// Text returns the text of the given type (eg "Business Summary").
+func (s *CompanySnapshot) Text(typ string) string {
+	for _, t := range s.Texts {
+		if t.Type == typ {
+			return t.Value
+		}
+	}
+	return ""
+}
+
+// Ratio returns the ratio with the given field name (eg "NPRICE").
+func (s *CompanySnapshot) Ratio(field string) (ReportRatio, bool) {
+	return findRatio(s.Ratios, field)
+}
+
+// Forecast returns the current forecast with the given field name (eg "TargetPrice").
+func (s *CompanySnapshot) Forecast(field string) (ReportRatio, bool) {
+	for _, f := range s.Forecasts {
+		if f.Field == field && f.Group == "CURR" {
+			return f, true
+		}
+	}
+	return ReportRatio{}, false
+}
+
+// RatioReport is a ReportRatios report.
+type RatioReport struct {
+	PriceCurrency     string        `xml:"PriceCurrency,attr"`
+	ReportingCurrency string        `xml:"ReportingCurrency,attr"`
+	LatestDate        ReportDate    `xml:"LatestAvailableDate,attr"`
+	Ratios            []ReportRatio `xml:"-"`
+}
+
+// ParseRatioReport decodes a ReportRatios report.
+func ParseRatioReport(data string) (*RatioReport, error) {
+	var x struct {
+		Ratios struct {
+			RatioReport
+			Groups []reportRatioGroup `xml:"Group"`
+		} `xml:"Ratios"`
+	}
+	if err := xml.Unmarshal([]byte(data), &x); err != nil {
+		return nil, err
+	}
+	r := &x.Ratios.RatioReport
+	r.Ratios = flattenRatios(x.Ratios.Groups)
+	return r, nil
+}
+
+// Ratio returns the ratio with the given field name.
+func (r *RatioReport) Ratio(field string) (ReportRatio, bool) {
+	return findRatio(r.Ratios, field)
+}
+
+// FinancialSummaryValue is a value for a period in a ReportsFinSummary report.
+type FinancialSummaryValue struct {
+	AsOf       ReportDate `xml:"asofDate,attr"`
+	ReportType string     `xml:"reportType,attr"` // A (actual), R (restated), P (preliminary)...
+	Period     string     `xml:"period,attr"`     // eg 3M, 12M
+	Value      float64    `xml:",chardata"`
+}
+
+// DividendEvent is a dividend in a ReportsFinSummary report.
+type DividendEvent struct {
+	Type            string     `xml:"type,attr"`
+	ExDate          ReportDate `xml:"exDate,attr"`
+	RecordDate      ReportDate `xml:"recordDate,attr"`
+	PayDate         ReportDate `xml:"payDate,attr"`
+	DeclarationDate ReportDate `xml:"declarationDate,attr"`
+	Value           float64    `xml:",chardata"`
+}
+
+// FinancialSummary is a ReportsFinSummary report.
+type FinancialSummary struct {
+	EPS       []FinancialSummaryValue `xml:"EPSs>EPS"`
+	DPS       []FinancialSummaryValue `xml:"DPSs>DPS"`
+	Revenue   []FinancialSummaryValue `xml:"TotalRevenues>TotalRevenue"`
+	Dividends []DividendEvent         `xml:"Dividends>Dividend"`
+}
+
+// ParseFinancialSummary decodes a ReportsFinSummary report.
+func ParseFinancialSummary(data string) (*FinancialSummary, error) {
+	s := &FinancialSummary{}
+	if err := xml.Unmarshal([]byte(data), s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// StatementLineItem is one line of a financial statement.
+type StatementLineItem struct {
+	Code  string  `xml:"coaCode,attr"`
+	Value float64 `xml:",chardata"`
+}
+
+// Statement is an income (INC), balance sheet (BAL) or cash flow (CAS) statement.
+type Statement struct {
+	Type          string              `xml:"Type,attr"`
+	PeriodLength  int64               `xml:"FPHeader>PeriodLength"`
+	PeriodType    string              `xml:"FPHeader>periodType"`
+	StatementDate ReportDate          `xml:"FPHeader>StatementDate"`
+	Source        string              `xml:"FPHeader>Source"`
+	LineItems     []StatementLineItem `xml:"lineItem"`
+}
+
+// Value returns the value of the line item with the given code (eg "SREV").
+func (s Statement) Value(code string) (float64, bool) {
+	for _, l := range s.LineItems {
+		if l.Code == code {
+			return l.Value, true
+		}
+	}
+	return 0, false
+}
+
+// FiscalPeriod holds the statements of an annual or interim period.
+type FiscalPeriod struct {
+	Type       string      `xml:"Type,attr"`
+	EndDate    ReportDate  `xml:"EndDate,attr"`
+	FiscalYear int64       `xml:"FiscalYear,attr"`
+	Number     int64       `xml:"FiscalPeriodNumber,attr"` // interim periods only
+	Statements []Statement `xml:"Statement"`
+}
+
+// Statement returns the statement of the given type.
+func (p FiscalPeriod) Statement(typ string) (Statement, bool) {
+	for _, s := range p.Statements {
+		if s.Type == typ {
+			return s, true
+		}
+	}
+	return Statement{}, false
+}
+
+// StatementItem describes a line item code.
+type StatementItem struct {
+	Code          string `xml:"coaItem,attr"`
+	StatementType string `xml:"statementType,attr"`
+	Name          string `xml:",chardata"`
+}
+
+// FinancialStatements is a ReportsFinStatements report.
+type FinancialStatements struct {
+	Items   []StatementItem `xml:"FinancialStatements>COAMap>mapItem"`
+	Annual  []FiscalPeriod  `xml:"FinancialStatements>AnnualPeriods>FiscalPeriod"`
+	Interim []FiscalPeriod  `xml:"FinancialStatements>InterimPeriods>FiscalPeriod"`
+}
+
+// ParseFinancialStatements decodes a ReportsFinStatements report.
+func ParseFinancialStatements(data string) (*FinancialStatements, error) {
+	s := &FinancialStatements{}
+	if err := xml.Unmarshal([]byte(data), s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// EstimatePeriod is a fiscal period of actual or estimated values of one item
+// (eg "EPS") in a RESC report. Values are keyed by consensus type (eg "Mean",
+// "High", "NumOfEst") and hold current values; Actual is set for actuals only.
+type EstimatePeriod struct {
+	Item       string
+	Unit       string
+	PeriodType string // A (annual) or Q (quarterly); empty for non-periodic
+	FiscalYear int64
+	EndMonth   int64
+	EndCalYear int64
+	Actual     float64
+	Values     map[string]float64
+}
+
+type xmlEstimatePeriod struct {
+	PeriodType string  `xml:"periodType,attr"`
+	FiscalYear int64   `xml:"fYear,attr"`
+	EndMonth   int64   `xml:"endMonth,attr"`
+	EndCalYear int64   `xml:"endCalYear,attr"`
+	Actual     float64 `xml:"ActValue"`
+	Estimates  []struct {
+		Type   string `xml:"type,attr"`
+		Values []struct {
+			DateType string  `xml:"dateType,attr"`
+			Value    float64 `xml:",chardata"`
+		} `xml:"ConsValue"`
+	} `xml:"ConsEstimate"`
+}
+
+type xmlEstimateItem struct {
+	Type    string              `xml:"type,attr"`
+	Unit    string              `xml:"unit,attr"`
+	Periods []xmlEstimatePeriod `xml:"FYPeriod"`
+	xmlEstimatePeriod
+}
+
+func (x xmlEstimatePeriod) toPeriod(item xmlEstimateItem) EstimatePeriod {
+	p := EstimatePeriod{
+		Item:       item.Type,
+		Unit:       item.Unit,
+		PeriodType: x.PeriodType,
+		FiscalYear: x.FiscalYear,
+		EndMonth:   x.EndMonth,
+		EndCalYear: x.EndCalYear,
+		Actual:     x.Actual,
+	}
+	for _, e := range x.Estimates {
+		for _, v := range e.Values {
+			if v.DateType == "CURR" {
+				if p.Values == nil {
+					p.Values = map[string]float64{}
+				}
+				p.Values[e.Type] = v.Value
+			}
+		}
+	}
+	return p
+}
+
+func estimatePeriods(items []xmlEstimateItem) (periods []EstimatePeriod) {
+	for _, i := range items {
+		if len(i.Periods) == 0 {
+			periods = append(periods, i.xmlEstimatePeriod.toPeriod(i))
+		}
+		for _, p := range i.Periods {
+			periods = append(periods, p.toPeriod(i))
+		}
+	}
+	return periods
+}
+
+// Estimates is a RESC (analyst estimates) report.
+type Estimates struct {
+	Actuals   []EstimatePeriod
+	Estimates []EstimatePeriod // fiscal year and quarter estimates
+	Targets   []EstimatePeriod // non-periodic estimates (eg "TargetPrice")
+}
+
+// ParseEstimates decodes a RESC report.
+func ParseEstimates(data string) (*Estimates, error) {
+	var x struct {
+		FYActuals   []xmlEstimateItem `xml:"Actuals>FYActuals>FYActual"`
+		QActuals    []xmlEstimateItem `xml:"Actuals>QActuals>QActual"`
+		FYEstimates []xmlEstimateItem `xml:"ConsEstimates>FYEstimates>FYEstimate"`
+		QEstimates  []xmlEstimateItem `xml:"ConsEstimates>QEstimates>QEstimate"`
+		NPEstimates []xmlEstimateItem `xml:"ConsEstimates>NPEstimates>NPEstimate"`
+	}
+	if err := xml.Unmarshal([]byte(data), &x); err != nil {
+		return nil, err
+	}
+	return &Estimates{
+		Actuals:   estimatePeriods(append(x.FYActuals, x.QActuals...)),
+		Estimates: estimatePeriods(append(x.FYEstimates, x.QEstimates...)),
+		Targets:   estimatePeriods(x.NPEstimates),
+	}, nil
+}
+
+// Actual returns the actual values of the item for the given fiscal period.
+func (e *Estimates) Actual(item string, periodType string, fiscalYear int64) (EstimatePeriod, bool) {
+	return findEstimate(e.Actuals, item, periodType, fiscalYear)
+}
+
+// Estimate returns the consensus estimates of the item for the given fiscal period.
+func (e *Estimates) Estimate(item string, periodType string, fiscalYear int64) (EstimatePeriod, bool) {
+	return findEstimate(e.Estimates, item, periodType, fiscalYear)
+}
+
+func findEstimate(periods []EstimatePeriod, item string, periodType string, fiscalYear int64) (EstimatePeriod, bool) {
+	for _, p := range periods {
+		if p.Item == item && p.PeriodType == periodType && p.FiscalYear == fiscalYear {
+			return p, true
+		}
+	}
+	return EstimatePeriod{}, false
+}
+
+// CalendarEvent is a scheduled corporate event in a CalendarReport report.
+type CalendarEvent struct {
+	Type   string     `xml:"type,attr"`
+	Period string     `xml:"Period"`
+	Date   ReportDate `xml:"Date"`
+	Time   string     `xml:"Time"`
+}
+
+// CompanyCalendar is a CalendarReport report.
+type CompanyCalendar struct {
+	Ticker   string          `xml:"Company>Ticker"`
+	Name     string          `xml:"Company>CompanyName"`
+	Earnings []CalendarEvent `xml:"EarningsList>Earnings"`
+	Events   []CalendarEvent `xml:"EventList>Event"`
+}
+
+// ParseCompanyCalendar decodes a CalendarReport report.
+func ParseCompanyCalendar(data string) (*CompanyCalendar, error) {
+	c := &CompanyCalendar{}
+	if err := xml.Unmarshal([]byte(data), c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
@@ -0,0 +1,95 @@
+package ib
+
+// FundamentalDataManager fetches a fundamental data report (see the
+// ReportType constants) for a contract. The report is decoded with the
+// corresponding Parse function.
+type FundamentalDataManager struct {
+	AbstractManager
+	request RequestFundamentalData
+	data    string
+	done    bool
+}
+
+// NewFundamentalDataManager .
+func NewFundamentalDataManager(e *Engine, c Contract, reportType string) (*FundamentalDataManager, error) {
+	am, err := NewAbstractManager(e)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &FundamentalDataManager{
+		AbstractManager: *am,
+		request:         RequestFundamentalData{Contract: c, ReportType: reportType},
+	}
+	m.request.SetID(e.NextRequestID())
+
+	go m.startMainLoop(m.preLoop, m.receive, m.preDestroy)
+	return m, nil
+}
+
+func (m *FundamentalDataManager) preLoop() error {
+	m.eng.Subscribe(m.rc, m.request.id)
+	return m.eng.Send(&m.request)
+}
+
+func (m *FundamentalDataManager) receive(r Reply) (UpdateStatus, error) {
+	switch r.(type) {
+	case *ErrorMessage:
+		r := r.(*ErrorMessage)
+		if r.ID() != m.request.id || r.SeverityWarning() {
+			return UpdateFalse, nil
+		}
+		return UpdateFalse, r.Error()
+	case *FundamentalData:
+		m.data = r.(*FundamentalData).Data
+		m.done = true
+		return UpdateFinish, nil
+	}
+	return UpdateFalse, nil
+}
+
+func (m *FundamentalDataManager) preDestroy() {
+	m.eng.Unsubscribe(m.rc, m.request.id)
+	if !m.done {
+		req := &CancelFundamentalData{}
+		req.SetID(m.request.id)
+		m.eng.Send(req)
+	}
+}
+
+// Data returns the raw XML report.
+func (m *FundamentalDataManager) Data() string {
+	m.rwm.RLock()
+	defer m.rwm.RUnlock()
+	return m.data
+}
+
+// Snapshot decodes a ReportSnapshot report.
+func (m *FundamentalDataManager) Snapshot() (*CompanySnapshot, error) {
+	return ParseCompanySnapshot(m.Data())
+}
+
+// FinancialSummary decodes a ReportsFinSummary report.
+func (m *FundamentalDataManager) FinancialSummary() (*FinancialSummary, error) {
+	return ParseFinancialSummary(m.Data())
+}
+
+// Ratios decodes a ReportRatios report.
+func (m *FundamentalDataManager) Ratios() (*RatioReport, error) {
+	return ParseRatioReport(m.Data())
+}
+
+// FinancialStatements decodes a ReportsFinStatements report.
+func (m *FundamentalDataManager) FinancialStatements() (*FinancialStatements, error) {
+	return ParseFinancialStatements(m.Data())
+}
+
+// Estimates decodes a RESC report.
+func (m *FundamentalDataManager) Estimates() (*Estimates, error) {
+	return ParseEstimates(m.Data())
+}
+
+// Calendar decodes a CalendarReport report.
+func (m *FundamentalDataManager) Calendar() (*CompanyCalendar, error) {
+	return ParseCompanyCalendar(m.Data())
+}
@@ -0,0 +1,258 @@
+package ib
+
+import (
+	"testing"
+	"time"
+)
+
+func date(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestParseCompanySnapshot(t *testing.T) {
+	s, err := ParseCompanySnapshot(`<?xml version="1.0" encoding="UTF-8"?>
+<ReportSnapshot Major="1" Minor="0" Revision="1">
+	<CoIDs>
+		<CoID Type="RepNo">AC317</CoID>
+		<CoID Type="CompanyName">International Business Machines Corp.</CoID>
+	</CoIDs>
+	<CoGeneralInfo>
+		<LatestAvailableAnnual>2016-12-31</LatestAvailableAnnual>
+		<Employees LastUpdated="2017-02-28">380300</Employees>
+		<SharesOut Date="2017-06-30" TotalFloat="930000000.0">932000000.0</SharesOut>
+		<ReportingCurrency Code="USD">U.S. Dollars</ReportingCurrency>
+	</CoGeneralInfo>
+	<TextInfo>
+		<Text Type="Business Summary" lastModified="2017-01-20">IT services.</Text>
+	</TextInfo>
+	<peerInfo>
+		<IndustryInfo>
+			<Industry type="TRBC" order="1" code="5720102010">IT Services &amp; Consulting</Industry>
+		</IndustryInfo>
+	</peerInfo>
+	<Ratios PriceCurrency="USD" ReportingCurrency="USD" ExchangeRate="1.00000" LatestAvailableDate="2016-12-31">
+		<Group ID="Price and Volume">
+			<Ratio FieldName="NPRICE" Type="N">153.83000</Ratio>
+			<Ratio FieldName="NLOW" Type="N">139.13000</Ratio>
+		</Group>
+		<Group ID="Income Statement">
+			<Ratio FieldName="TTMREV" Type="N">78539.00000</Ratio>
+		</Group>
+	</Ratios>
+	<ForecastData ConsensusType="Mean" CurFiscalYear="2017" CurFiscalYearEndMonth="12">
+		<Ratio FieldName="ConsRecom" Type="N">
+			<Value PeriodType="CURR">2.3</Value>
+			<Value PeriodType="PREV">2.1</Value>
+		</Ratio>
+		<Ratio FieldName="TargetPrice" Type="N">
+			<Value PeriodType="CURR">160.5</Value>
+		</Ratio>
+	</ForecastData>
+</ReportSnapshot>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.ID("CompanyName") != "International Business Machines Corp." || s.Employees != 380300 || s.SharesOut != 932e6 {
+		t.Fatalf("unexpected snapshot %+v", s)
+	}
+	if !s.LatestAnnual.Equal(date(2016, 12, 31)) || s.Text("Business Summary") != "IT services." || s.Industries[0].Code != "5720102010" {
+		t.Fatalf("unexpected snapshot %+v", s)
+	}
+	if r, ok := s.Ratio("TTMREV"); !ok || r.Float != 78539 || r.Group != "Income Statement" || len(s.Ratios) != 3 {
+		t.Fatalf("unexpected ratios %+v", s.Ratios)
+	}
+	if f, ok := s.Forecast("TargetPrice"); !ok || f.Float != 160.5 {
+		t.Fatalf("unexpected forecasts %+v", s.Forecasts)
+	}
+	if f, ok := s.Forecast("ConsRecom"); !ok || f.Float != 2.3 {
+		t.Fatalf("unexpected forecasts %+v", s.Forecasts)
+	}
+	if len(s.Forecasts) != 3 || s.Forecasts[1].Group != "PREV" || s.Forecasts[1].Float != 2.1 {
+		t.Fatalf("unexpected forecasts %+v", s.Forecasts)
+	}
+}
+
+func TestParseRatioReport(t *testing.T) {
+	r, err := ParseRatioReport(`<XMLNTC>
+	<Ratios PriceCurrency="USD" ReportingCurrency="USD" ExchangeRate="1.00000" LatestAvailableDate="2016-12-31">
+		<Group ID="Price and Volume">
+			<Ratio FieldName="NPRICE" Type="N">153.83000</Ratio>
+			<Ratio FieldName="PDATE" Type="D">2017-07-03T00:00:00</Ratio>
+		</Group>
+	</Ratios>
+</XMLNTC>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.PriceCurrency != "USD" || !r.LatestDate.Equal(date(2016, 12, 31)) {
+		t.Fatalf("unexpected report %+v", r)
+	}
+	if p, ok := r.Ratio("NPRICE"); !ok || p.Float != 153.83 {
+		t.Fatalf("unexpected ratio %+v", p)
+	}
+	if d, ok := r.Ratio("PDATE"); !ok || d.Value != "2017-07-03T00:00:00" {
+		t.Fatalf("unexpected ratio %+v", d)
+	}
+}
+
+func TestParseFinancialSummary(t *testing.T) {
+	s, err := ParseFinancialSummary(`<FinancialSummary>
+	<EPSs currency="USD">
+		<EPS asofDate="2016-12-31" reportType="A" period="12M">12.38</EPS>
+		<EPS asofDate="2017-06-30" reportType="P" period="3M">2.48</EPS>
+	</EPSs>
+	<DPSs currency="USD">
+		<DPS asofDate="2017-06-30" reportType="A" period="3M">1.5</DPS>
+	</DPSs>
+	<TotalRevenues currency="USD">
+		<TotalRevenue asofDate="2016-12-31" reportType="A" period="12M">79919000000.0</TotalRevenue>
+	</TotalRevenues>
+	<Dividends currency="USD">
+		<Dividend type="CD" exDate="2017-08-08" recordDate="2017-08-10" payDate="2017-09-09" declarationDate="2017-07-25">1.5</Dividend>
+	</Dividends>
+</FinancialSummary>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.EPS) != 2 || s.EPS[1].Value != 2.48 || s.EPS[1].Period != "3M" || !s.EPS[0].AsOf.Equal(date(2016, 12, 31)) {
+		t.Fatalf("unexpected EPS %+v", s.EPS)
+	}
+	if len(s.DPS) != 1 || len(s.Revenue) != 1 || s.Revenue[0].Value != 79919e6 {
+		t.Fatalf("unexpected summary %+v", s)
+	}
+	if d := s.Dividends[0]; !d.ExDate.Equal(date(2017, 8, 8)) || !d.PayDate.Equal(date(2017, 9, 9)) || d.Value != 1.5 {
+		t.Fatalf("unexpected dividend %+v", d)
+	}
+}
+
+func TestParseFinancialStatements(t *testing.T) {
+	s, err := ParseFinancialStatements(`<ReportFinancialStatements Major="1" Minor="0" Revision="1">
+	<FinancialStatements>
+		<COAMap>
+			<mapItem coaItem="SREV" statementType="INC" lineID="10" precision="1">Revenue</mapItem>
+			<mapItem coaItem="ATOT" statementType="BAL" lineID="20" precision="1">Total Assets</mapItem>
+		</COAMap>
+		<AnnualPeriods>
+			<FiscalPeriod Type="Annual" EndDate="2016-12-31" FiscalYear="2016">
+				<Statement Type="INC">
+					<FPHeader>
+						<PeriodLength>12</PeriodLength>
+						<periodType Code="M">Months</periodType>
+						<StatementDate>2016-12-31</StatementDate>
+						<Source Date="2017-02-28">10-K</Source>
+					</FPHeader>
+					<lineItem coaCode="SREV">79919.0</lineItem>
+				</Statement>
+				<Statement Type="BAL">
+					<FPHeader><PeriodLength>0</PeriodLength></FPHeader>
+					<lineItem coaCode="ATOT">117470.0</lineItem>
+				</Statement>
+			</FiscalPeriod>
+		</AnnualPeriods>
+		<InterimPeriods>
+			<FiscalPeriod Type="Interim" EndDate="2017-06-30" FiscalYear="2017" FiscalPeriodNumber="2">
+				<Statement Type="INC">
+					<lineItem coaCode="SREV">19289.0</lineItem>
+				</Statement>
+			</FiscalPeriod>
+		</InterimPeriods>
+	</FinancialStatements>
+</ReportFinancialStatements>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Items) != 2 || s.Items[1].Name != "Total Assets" {
+		t.Fatalf("unexpected items %+v", s.Items)
+	}
+	if len(s.Annual) != 1 || s.Annual[0].FiscalYear != 2016 || !s.Annual[0].EndDate.Equal(date(2016, 12, 31)) {
+		t.Fatalf("unexpected annual periods %+v", s.Annual)
+	}
+	inc, ok := s.Annual[0].Statement("INC")
+	if !ok || inc.PeriodLength != 12 || inc.PeriodType != "Months" || inc.Source != "10-K" {
+		t.Fatalf("unexpected statement %+v", inc)
+	}
+	if v, ok := inc.Value("SREV"); !ok || v != 79919 {
+		t.Fatalf("unexpected revenue %v", v)
+	}
+	if p := s.Interim[0]; p.Number != 2 || len(p.Statements) != 1 {
+		t.Fatalf("unexpected interim period %+v", p)
+	}
+}
+
+func TestParseEstimates(t *testing.T) {
+	e, err := ParseEstimates(`<REarnEstCons Version="1">
+	<Actuals>
+		<FYActuals>
+			<FYActual type="EPS" unit="U">
+				<FYPeriod periodType="A" fYear="2016" endMonth="12" endCalYear="2016">
+					<ActValue updated="2017-01-19T21:53:53">13.59</ActValue>
+				</FYPeriod>
+			</FYActual>
+		</FYActuals>
+	</Actuals>
+	<ConsEstimates>
+		<FYEstimates>
+			<FYEstimate type="EPS" unit="U">
+				<FYPeriod periodType="A" fYear="2017" endMonth="12" endCalYear="2017">
+					<ConsEstimate type="Mean">
+						<ConsValue dateType="CURR">13.91</ConsValue>
+						<ConsValue dateType="1WA">13.9</ConsValue>
+					</ConsEstimate>
+					<ConsEstimate type="NumOfEst">
+						<ConsValue dateType="CURR">19</ConsValue>
+					</ConsEstimate>
+				</FYPeriod>
+				<FYPeriod periodType="Q" fYear="2017" endMonth="9" endCalYear="2017">
+					<ConsEstimate type="Mean">
+						<ConsValue dateType="CURR">3.28</ConsValue>
+					</ConsEstimate>
+				</FYPeriod>
+			</FYEstimate>
+		</FYEstimates>
+		<NPEstimates>
+			<NPEstimate type="TargetPrice" unit="U">
+				<ConsEstimate type="Mean">
+					<ConsValue dateType="CURR">160.5</ConsValue>
+				</ConsEstimate>
+			</NPEstimate>
+		</NPEstimates>
+	</ConsEstimates>
+</REarnEstCons>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a, ok := e.Actual("EPS", "A", 2016); !ok || a.Actual != 13.59 || a.EndMonth != 12 {
+		t.Fatalf("unexpected actuals %+v", e.Actuals)
+	}
+	if p, ok := e.Estimate("EPS", "A", 2017); !ok || p.Values["Mean"] != 13.91 || p.Values["NumOfEst"] != 19 {
+		t.Fatalf("unexpected estimates %+v", e.Estimates)
+	}
+	if p, ok := e.Estimate("EPS", "Q", 2017); !ok || p.Values["Mean"] != 3.28 {
+		t.Fatalf("unexpected estimates %+v", e.Estimates)
+	}
+	if len(e.Targets) != 1 || e.Targets[0].Item != "TargetPrice" || e.Targets[0].Values["Mean"] != 160.5 {
+		t.Fatalf("unexpected targets %+v", e.Targets)
+	}
+}
+
+func TestParseCompanyCalendar(t *testing.T) {
+	c, err := ParseCompanyCalendar(`<CalendarReport>
+	<Company>
+		<Ticker>IBM</Ticker>
+		<CompanyName>International Business Machines Corp.</CompanyName>
+	</Company>
+	<EarningsList>
+		<Earnings>
+			<Period>Q2 2017</Period>
+			<Date>07/18/2017</Date>
+			<Time>After Market</Time>
+		</Earnings>
+	</EarningsList>
+</CalendarReport>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Ticker != "IBM" || len(c.Earnings) != 1 || !c.Earnings[0].Date.Equal(date(2017, 7, 18)) || c.Earnings[0].Period != "Q2 2017" {
+		t.Fatalf("unexpected calendar %+v", c)
+	}
+}
@@ -0,0 +1,321 @@
+package ib
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// FuturesChainManager lists the futures contracts of an underlying, in order
+// of expiry, and works out which contract to hold at a given time.
+type FuturesChainManager struct {
+	AbstractManager
+	id        int64
+	c         Contract
+	pending   []ContractDetails
+	contracts []ContractDetails
+}
+
+// NewFuturesChainManager requests every listed futures contract for the
+// underlying (eg Symbol "ES" on Exchange "GLOBEX").
+func NewFuturesChainManager(e *Engine, c Contract) (*FuturesChainManager, error) {
+	am, err := NewAbstractManager(e)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &FuturesChainManager{
+		AbstractManager: *am,
+		id:              e.NextRequestID(),
+		c:               c,
+	}
+
+	go m.startMainLoop(m.preLoop, m.receive, m.preDestroy)
+	return m, nil
+}
+
+func (m *FuturesChainManager) preLoop() error {
+	m.rwm.Lock()
+	m.pending = nil
+	m.rwm.Unlock()
+
+	req := &RequestContractData{Contract: m.c}
+	req.Contract.SecurityType = "FUT"
+	req.Contract.ContractID = 0
+	req.Contract.Expiry = ""
+	req.Contract.LocalSymbol = ""
+	req.SetID(m.id)
+	m.eng.Subscribe(m.rc, m.id)
+	return m.eng.Send(req)
+}
+
+func (m *FuturesChainManager) preDestroy() {
+	m.eng.Unsubscribe(m.rc, m.id)
+}
+
+func (m *FuturesChainManager) receive(r Reply) (UpdateStatus, error) {
+	switch r.(type) {
+	case *ErrorMessage:
+		r := r.(*ErrorMessage)
+		if r.ID() != m.id || r.SeverityWarning() {
+			return UpdateFalse, nil
+		}
+		return UpdateFalse, r.Error()
+	case *ContractData:
+		r := r.(*ContractData)
+		if _, err := FuturesExpiry(r.Contract); err != nil {
+			return UpdateFalse, err
+		}
+		m.pending = append(m.pending, r.Contract)
+		return UpdateFalse, nil
+	case *ContractDataEnd:
+		sort.SliceStable(m.pending, func(i, j int) bool { return futuresLess(m.pending[i], m.pending[j]) })
+		m.contracts = m.pending
+		m.pending = nil
+		return UpdateFinish, nil
+	}
+	return UpdateFalse, fmt.Errorf("Unexpected type %v", r)
+}
+
+// FuturesExpiry is the end of the last trading day of the futures contract,
+// in the exchange's time zone (UTC if the details have none). The day is taken
+// from the contract's Expiry or, failing that, the last day of its
+// ContractMonth.
+func FuturesExpiry(d ContractDetails) (time.Time, error) {
+	loc := time.UTC
+	if d.TimezoneID != "" {
+		l, err := LoadIBLocation(d.TimezoneID)
+		if err != nil {
+			return time.Time{}, err
+		}
+		loc = l
+	}
+	if e := d.Summary.Expiry; len(e) >= 8 {
+		day, err := time.ParseInLocation("20060102", e[:8], loc)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("goib: malformed futures expiry %q", e)
+		}
+		return day.AddDate(0, 0, 1), nil
+	}
+	month, err := time.ParseInLocation("200601", d.ContractMonth, loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("goib: futures contract %d has no expiry or contract month", d.Summary.ContractID)
+	}
+	return month.AddDate(0, 1, 0), nil
+}
+
+// futuresLess orders futures contracts by contract month, then expiry.
+func futuresLess(a ContractDetails, b ContractDetails) bool {
+	if a.ContractMonth != b.ContractMonth && a.ContractMonth != "" && b.ContractMonth != "" {
+		return a.ContractMonth < b.ContractMonth
+	}
+	ea, _ := FuturesExpiry(a)
+	eb, _ := FuturesExpiry(b)
+	return ea.Before(eb)
+}
+
+// Contracts returns the futures contracts in order of expiry.
+func (m *FuturesChainManager) Contracts() []ContractDetails {
+	m.rwm.RLock()
+	defer m.rwm.RUnlock()
+	return append([]ContractDetails(nil), m.contracts...)
+}
+
+// Expiries returns the expiry of each contract, as given by FuturesExpiry.
+func (m *FuturesChainManager) Expiries() []time.Time {
+	m.rwm.RLock()
+	defer m.rwm.RUnlock()
+	expiries := make([]time.Time, len(m.contracts))
+	for i, d := range m.contracts {
+		expiries[i], _ = FuturesExpiry(d)
+	}
+	return expiries
+}
+
+// frontAt returns the index of the first contract not expired at t.
+func (m *FuturesChainManager) frontAt(t time.Time) int {
+	return sort.Search(len(m.contracts), func(i int) bool {
+		e, _ := FuturesExpiry(m.contracts[i])
+		return e.After(t)
+	})
+}
+
+// Front returns the front month contract at t, which is the first contract
+// not expired. It returns false if every contract has expired.
+func (m *FuturesChainManager) Front(t time.Time) (ContractDetails, bool) {
+	m.rwm.RLock()
+	defer m.rwm.RUnlock()
+	if i := m.frontAt(t); i < len(m.contracts) {
+		return m.contracts[i], true
+	}
+	return ContractDetails{}, false
+}
+
+// Next returns the contract after the front month contract at t.
+func (m *FuturesChainManager) Next(t time.Time) (ContractDetails, bool) {
+	m.rwm.RLock()
+	defer m.rwm.RUnlock()
+	if i := m.frontAt(t) + 1; i < len(m.contracts) {
+		return m.contracts[i], true
+	}
+	return ContractDetails{}, false
+}
+
+// Active returns the contract to hold at t under the roll policy: the next
+// contract once the policy's roll from the front contract is due, otherwise
+// the front contract.
+func (m *FuturesChainManager) Active(t time.Time, p RollPolicy) (ContractDetails, bool) {
+	m.rwm.RLock()
+	defer m.rwm.RUnlock()
+	i := m.frontAt(t)
+	if i >= len(m.contracts) {
+		return ContractDetails{}, false
+	}
+	if i+1 < len(m.contracts) {
+		if roll, ok := p(m.contracts[i], m.contracts[i+1]); ok && !t.Before(roll) {
+			return m.contracts[i+1], true
+		}
+	}
+	return m.contracts[i], true
+}
+
+// Rolls returns the roll from each contract into the one after it under the
+// policy. Contracts the policy gives no roll date for are left out.
+func (m *FuturesChainManager) Rolls(p RollPolicy) []Roll {
+	m.rwm.RLock()
+	defer m.rwm.RUnlock()
+	var rolls []Roll
+	for i := 0; i+1 < len(m.contracts); i++ {
+		if date, ok := p(m.contracts[i], m.contracts[i+1]); ok {
+			rolls = append(rolls, Roll{From: m.contracts[i], To: m.contracts[i+1], Date: date})
+		}
+	}
+	return rolls
+}
+
+// RollPolicy returns when to roll a position from the front contract into the
+// next one, or false if it cannot tell.
+type RollPolicy func(front ContractDetails, next ContractDetails) (time.Time, bool)
+
+// DaysBeforeExpiry rolls at the start of the day n calendar days before the
+// front contract's last trading day.
+func DaysBeforeExpiry(n int) RollPolicy {
+	return func(front ContractDetails, next ContractDetails) (time.Time, bool) {
+		e, err := FuturesExpiry(front)
+		if err != nil {
+			return time.Time{}, false
+		}
+		return e.AddDate(0, 0, -n-1), true
+	}
+}
+
+// VolumeCrossover rolls on the first bar the next contract trades more than
+// the front contract, given (usually daily) bars for each contract keyed by
+// ContractID. If the volumes do not cross before the front contract expires,
+// it uses the fallback policy (if not nil).
+func VolumeCrossover(bars map[int64][]HistoricalDataItem, fallback RollPolicy) RollPolicy {
+	return func(front ContractDetails, next ContractDetails) (time.Time, bool) {
+		e, err := FuturesExpiry(front)
+		if err == nil {
+			nextBars := bars[next.Summary.ContractID]
+			for _, f := range bars[front.Summary.ContractID] {
+				if !f.Date.Before(e) {
+					break
+				}
+				if n, ok := barAt(nextBars, f.Date); ok && n.Volume > f.Volume {
+					return f.Date, true
+				}
+			}
+		}
+		if fallback != nil {
+			return fallback(front, next)
+		}
+		return time.Time{}, false
+	}
+}
+
+// barAt returns the bar dated t from bars in order of date.
+func barAt(bars []HistoricalDataItem, t time.Time) (HistoricalDataItem, bool) {
+	i := sort.Search(len(bars), func(i int) bool { return !bars[i].Date.Before(t) })
+	if i < len(bars) && bars[i].Date.Equal(t) {
+		return bars[i], true
+	}
+	return HistoricalDataItem{}, false
+}
+
+// Roll is a roll from one futures contract into the next.
+type Roll struct {
+	From ContractDetails
+	To   ContractDetails
+	Date time.Time
+}
+
+// BackAdjust stitches bars for each contract, keyed by ContractID and in order
+// of date, into one continuous series across the rolls, which must follow on
+// from one another. Bars before a roll come from the contract rolled from and
+// bars from the roll on from the contract rolled into. Prices before each roll
+// are shifted by the difference between the two contracts' closes on the last
+// date both have a bar for (at or before the roll), removing the jump a roll
+// would otherwise put in the series. Volumes are not adjusted.
+func BackAdjust(bars map[int64][]HistoricalDataItem, rolls []Roll) ([]HistoricalDataItem, error) {
+	if len(rolls) == 0 {
+		return nil, errors.New("goib: back adjusting requires rolls")
+	}
+
+	gaps := make([]float64, len(rolls))
+	for i, r := range rolls {
+		if i > 0 && (r.From.Summary.ContractID != rolls[i-1].To.Summary.ContractID || !r.Date.After(rolls[i-1].Date)) {
+			return nil, fmt.Errorf("goib: roll %d does not follow on from roll %d", i, i-1)
+		}
+		gap, ok := rollGap(bars[r.From.Summary.ContractID], bars[r.To.Summary.ContractID], r.Date)
+		if !ok {
+			return nil, fmt.Errorf("goib: contracts %d and %d have no bars in common before their roll on %s",
+				r.From.Summary.ContractID, r.To.Summary.ContractID, r.Date.Format("20060102"))
+		}
+		gaps[i] = gap
+	}
+
+	var series []HistoricalDataItem
+	for i := 0; i <= len(rolls); i++ {
+		var id int64
+		if i < len(rolls) {
+			id = rolls[i].From.Summary.ContractID
+		} else {
+			id = rolls[i-1].To.Summary.ContractID
+		}
+		adjust := float64(0)
+		for _, g := range gaps[i:] {
+			adjust += g
+		}
+		for _, b := range bars[id] {
+			if i > 0 && b.Date.Before(rolls[i-1].Date) {
+				continue
+			}
+			if i < len(rolls) && !b.Date.Before(rolls[i].Date) {
+				break
+			}
+			b.Open += adjust
+			b.High += adjust
+			b.Low += adjust
+			b.Close += adjust
+			b.WAP += adjust
+			series = append(series, b)
+		}
+	}
+	return series, nil
+}
+
+// rollGap returns the difference between the closes of the contracts rolled
+// into and from, on the last date at or before the roll both have a bar for.
+func rollGap(from []HistoricalDataItem, to []HistoricalDataItem, roll time.Time) (float64, bool) {
+	for i := len(from) - 1; i >= 0; i-- {
+		if from[i].Date.After(roll) {
+			continue
+		}
+		if t, ok := barAt(to, from[i].Date); ok {
+			return t.Close - from[i].Close, true
+		}
+	}
+	return 0, false
+}
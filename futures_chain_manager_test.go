@@ -0,0 +1,124 @@
+package ib
+
+import (
+	"testing"
+	"time"
+)
+
+func future(id int64, month string, expiry string) ContractDetails {
+	return ContractDetails{
+		Summary:       Contract{ContractID: id, Symbol: "ES", SecurityType: "FUT", Expiry: expiry},
+		ContractMonth: month,
+		TimezoneID:    "CST",
+	}
+}
+
+func bar(day time.Time, close float64, volume int64) HistoricalDataItem {
+	return HistoricalDataItem{Date: day, Open: close, High: close + 1, Low: close - 1, Close: close, Volume: volume}
+}
+
+func TestFuturesExpiry(t *testing.T) {
+	chicago, err := LoadIBLocation("CST")
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := FuturesExpiry(future(1, "202403", "20240315"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2024, 3, 16, 0, 0, 0, 0, chicago); !e.Equal(want) {
+		t.Fatalf("expected expiry %v, got %v", want, e)
+	}
+
+	// without an expiry, the contract month is used
+	e, err = FuturesExpiry(future(1, "202403", ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2024, 4, 1, 0, 0, 0, 0, chicago); !e.Equal(want) {
+		t.Fatalf("expected expiry %v, got %v", want, e)
+	}
+
+	if _, err := FuturesExpiry(future(1, "", "")); err == nil {
+		t.Fatal("expected error for contract without expiry")
+	}
+}
+
+func TestDaysBeforeExpiry(t *testing.T) {
+	front, next := future(1, "202403", "20240315"), future(2, "202406", "20240621")
+	roll, ok := DaysBeforeExpiry(8)(front, next)
+	if !ok {
+		t.Fatal("expected a roll date")
+	}
+	if roll.Format("20060102 15:04") != "20240307 00:00" {
+		t.Fatalf("unexpected roll date %v", roll)
+	}
+}
+
+func TestVolumeCrossover(t *testing.T) {
+	front, next := future(1, "202403", "20240315"), future(2, "202406", "20240621")
+	bars := map[int64][]HistoricalDataItem{
+		1: {bar(date(2024, 3, 11), 5000, 900), bar(date(2024, 3, 12), 5010, 800), bar(date(2024, 3, 13), 5020, 400)},
+		2: {bar(date(2024, 3, 11), 5050, 300), bar(date(2024, 3, 12), 5060, 900), bar(date(2024, 3, 13), 5070, 1200)},
+	}
+	roll, ok := VolumeCrossover(bars, nil)(front, next)
+	if !ok || !roll.Equal(date(2024, 3, 12)) {
+		t.Fatalf("expected roll on 2024-03-12, got %v (%v)", roll, ok)
+	}
+
+	// no crossover falls back
+	bars[2][1].Volume = 100
+	bars[2][2].Volume = 100
+	if _, ok := VolumeCrossover(bars, nil)(front, next); ok {
+		t.Fatal("expected no roll without a crossover")
+	}
+	roll, ok = VolumeCrossover(bars, DaysBeforeExpiry(8))(front, next)
+	if !ok || roll.Format("20060102") != "20240307" {
+		t.Fatalf("expected fallback roll on 2024-03-07, got %v (%v)", roll, ok)
+	}
+}
+
+func TestBackAdjust(t *testing.T) {
+	h, m, u := future(1, "202403", "20240315"), future(2, "202406", "20240621"), future(3, "202409", "20240920")
+	bars := map[int64][]HistoricalDataItem{
+		1: {bar(date(2024, 3, 7), 100, 10), bar(date(2024, 3, 8), 101, 10), bar(date(2024, 3, 11), 102, 10)},
+		2: {bar(date(2024, 3, 8), 105, 10), bar(date(2024, 3, 11), 106, 10), bar(date(2024, 6, 13), 110, 10), bar(date(2024, 6, 14), 111, 10)},
+		3: {bar(date(2024, 6, 13), 112, 10), bar(date(2024, 6, 14), 113, 10)},
+	}
+	rolls := []Roll{
+		{From: h, To: m, Date: date(2024, 3, 11)},
+		{From: m, To: u, Date: date(2024, 6, 14)},
+	}
+
+	series, err := BackAdjust(bars, rolls)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// gaps: 106-102=4 on 2024-03-11 and 113-111=2 on 2024-06-14
+	want := []struct {
+		day   time.Time
+		close float64
+	}{
+		{date(2024, 3, 7), 106},
+		{date(2024, 3, 8), 107},
+		{date(2024, 3, 11), 108},
+		{date(2024, 6, 13), 112},
+		{date(2024, 6, 14), 113},
+	}
+	if len(series) != len(want) {
+		t.Fatalf("expected %d bars, got %+v", len(want), series)
+	}
+	for i, w := range want {
+		b := series[i]
+		if !b.Date.Equal(w.day) || b.Close != w.close || b.High != w.close+1 || b.Volume != 10 {
+			t.Fatalf("bar %d: expected close %v on %v, got %+v", i, w.close, w.day, b)
+		}
+	}
+
+	if _, err := BackAdjust(bars, []Roll{rolls[1], rolls[0]}); err == nil {
+		t.Fatal("expected error for rolls out of order")
+	}
+	if _, err := BackAdjust(map[int64][]HistoricalDataItem{1: bars[1]}, rolls[:1]); err == nil {
+		t.Fatal("expected error for contracts without bars in common")
+	}
+}
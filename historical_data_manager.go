@@ -28,14 +28,17 @@ func NewHistoricalDataManager(e *Engine, request RequestHistoricalData) (*Histor
 
 func (m *HistoricalDataManager) preLoop() error {
 	m.eng.Subscribe(m.rc, m.request.id)
-	return m.eng.Send(&m.request)
+	return m.eng.HistoricalScheduler().Send(&m.request)
 }
 
 func (m *HistoricalDataManager) receive(r Reply) (UpdateStatus, error) {
 	switch r.(type) {
 	case *ErrorMessage:
 		r := r.(*ErrorMessage)
-		if r.SeverityWarning() {
+		if r.ID() != m.request.id || r.SeverityWarning() {
+			return UpdateFalse, nil
+		}
+		if IsPacingViolation(r) && m.eng.HistoricalScheduler().Retry(r.ID()) {
 			return UpdateFalse, nil
 		}
 		return UpdateFalse, r.Error()
@@ -48,6 +51,7 @@ func (m *HistoricalDataManager) receive(r Reply) (UpdateStatus, error) {
 }
 
 func (m *HistoricalDataManager) preDestroy() {
+	m.eng.HistoricalScheduler().Cancel(m.request.id)
 	m.eng.Unsubscribe(m.rc, m.request.id)
 }
 
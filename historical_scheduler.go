@@ -0,0 +1,263 @@
+package ib
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// HistoricalPacing configures the limits enforced by a HistoricalScheduler.
+// Zero values use IB's documented limits.
+type HistoricalPacing struct {
+	Identical   time.Duration // minimum gap between identical requests (15s)
+	BurstCount  int           // max requests for one contract, exchange and tick type... (5)
+	BurstWindow time.Duration // ...within this period (2s)
+	MaxRequests int           // max requests overall... (60)
+	Window      time.Duration // ...within this period (10m)
+	Backoff     Backoff       // delay before retrying a pacing violation
+}
+
+func (p HistoricalPacing) withDefaults() HistoricalPacing {
+	if p.Identical == 0 {
+		p.Identical = 15 * time.Second
+	}
+	if p.BurstCount == 0 {
+		p.BurstCount = 5
+	}
+	if p.BurstWindow == 0 {
+		p.BurstWindow = 2 * time.Second
+	}
+	if p.MaxRequests == 0 {
+		p.MaxRequests = 60
+	}
+	if p.Window == 0 {
+		p.Window = 10 * time.Minute
+	}
+	if p.Backoff == nil {
+		p.Backoff = ExponentialBackoff(10*time.Second, 10*time.Minute)
+	}
+	return p
+}
+
+// IsPacingViolation reports whether the ErrorMessage is a historical data
+// pacing violation.
+func IsPacingViolation(e *ErrorMessage) bool {
+	return e.Code == errorHistoricalData && strings.Contains(strings.ToLower(e.Message), "pacing violation")
+}
+
+// histSchedEntry is a queued request.
+type histSchedEntry struct {
+	req       RequestHistoricalData
+	identical string
+	burst     string
+	notBefore time.Time
+}
+
+// histSent records a request sent.
+type histSent struct {
+	at        time.Time
+	identical string
+	burst     string
+}
+
+// HistoricalScheduler queues RequestHistoricalData sends so they stay within
+// IB's pacing limits, retrying requests rejected for pacing violations with
+// backoff. Requests of different IDs (ie different managers) are sent in turn,
+// and a request replaces any still queued with the same ID (as sent again
+// after a reconnect). Each Engine has one scheduler, shared by its historical
+// data managers.
+type HistoricalScheduler struct {
+	eng      *Engine
+	pacing   HistoricalPacing
+	mu       sync.Mutex
+	queued   map[int64]*histSchedEntry
+	order    []int64 // IDs with queued requests, in turn order
+	last     map[int64]*histSchedEntry
+	attempts map[int64]int
+	sent     []histSent
+	wake     chan struct{}
+}
+
+func newHistoricalScheduler(e *Engine, p HistoricalPacing) *HistoricalScheduler {
+	return &HistoricalScheduler{
+		eng:      e,
+		pacing:   p.withDefaults(),
+		queued:   map[int64]*histSchedEntry{},
+		last:     map[int64]*histSchedEntry{},
+		attempts: map[int64]int{},
+		wake:     make(chan struct{}, 1),
+	}
+}
+
+// Send queues the request. It does not block.
+func (s *HistoricalScheduler) Send(r *RequestHistoricalData) error {
+	anon := *r
+	anon.id = 0
+	b := &bytes.Buffer{}
+	if err := anon.write(b); err != nil {
+		return err
+	}
+	c := r.Contract
+	entry := &histSchedEntry{
+		req:       *r,
+		identical: b.String(),
+		burst:     fmt.Sprintf("%d|%s|%s|%s|%s|%s|%s", c.ContractID, c.Symbol, c.SecurityType, c.Expiry, c.LocalSymbol, c.Exchange, r.WhatToShow),
+	}
+
+	s.mu.Lock()
+	delete(s.attempts, r.id)
+	s.enqueue(entry)
+	s.mu.Unlock()
+	return nil
+}
+
+// Retry queues the request most recently sent for the ID again, after a
+// backoff which grows with each retry. It returns false if there is none.
+func (s *HistoricalScheduler) Retry(id int64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	last, ok := s.last[id]
+	if !ok {
+		return false
+	}
+	s.attempts[id]++
+	entry := *last
+	entry.notBefore = time.Now().Add(s.pacing.Backoff(s.attempts[id]))
+	s.enqueue(&entry)
+	return true
+}
+
+// Cancel drops any queued request for the ID.
+func (s *HistoricalScheduler) Cancel(id int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.queued, id)
+	delete(s.last, id)
+	delete(s.attempts, id)
+	s.removeTurn(id)
+}
+
+// enqueue queues the entry in place of any for its ID. Callers must hold the lock.
+func (s *HistoricalScheduler) enqueue(entry *histSchedEntry) {
+	id := entry.req.id
+	if _, ok := s.queued[id]; !ok {
+		s.order = append(s.order, id)
+	}
+	s.queued[id] = entry
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (s *HistoricalScheduler) removeTurn(id int64) {
+	for i, o := range s.order {
+		if o == id {
+			s.order = append(s.order[:i], s.order[i+1:]...)
+			return
+		}
+	}
+}
+
+// requeue queues an entry which failed to send again, unless its request has
+// since been cancelled or replaced.
+func (s *HistoricalScheduler) requeue(entry *histSchedEntry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := entry.req.id
+	if _, queued := s.queued[id]; queued || s.last[id] != entry {
+		return
+	}
+	s.enqueue(entry)
+}
+
+// next returns the next request which may be sent now, or how long to wait
+// before one may be (0 if none is queued). Callers must hold the lock.
+func (s *HistoricalScheduler) next(now time.Time) (*histSchedEntry, time.Duration) {
+	for len(s.sent) > 0 && now.Sub(s.sent[0].at) >= s.pacing.Window {
+		s.sent = s.sent[1:]
+	}
+
+	var wait time.Duration
+	for i, id := range s.order {
+		entry := s.queued[id]
+		at := s.earliest(entry)
+		if at.After(now) {
+			if d := at.Sub(now); wait == 0 || d < wait {
+				wait = d
+			}
+			continue
+		}
+
+		delete(s.queued, id)
+		s.order = append(s.order[:i], s.order[i+1:]...)
+		s.last[id] = entry
+		s.sent = append(s.sent, histSent{at: now, identical: entry.identical, burst: entry.burst})
+		return entry, 0
+	}
+	return nil, wait
+}
+
+// earliest returns when the entry may be sent. Callers must hold the lock.
+func (s *HistoricalScheduler) earliest(entry *histSchedEntry) time.Time {
+	at := entry.notBefore
+	later := func(t time.Time) {
+		if t.After(at) {
+			at = t
+		}
+	}
+	if len(s.sent) >= s.pacing.MaxRequests {
+		later(s.sent[len(s.sent)-s.pacing.MaxRequests].at.Add(s.pacing.Window))
+	}
+	var burst []time.Time
+	for _, sent := range s.sent {
+		if sent.identical == entry.identical {
+			later(sent.at.Add(s.pacing.Identical))
+		}
+		if sent.burst == entry.burst {
+			burst = append(burst, sent.at)
+		}
+	}
+	if len(burst) >= s.pacing.BurstCount {
+		later(burst[len(burst)-s.pacing.BurstCount].Add(s.pacing.BurstWindow))
+	}
+	return at
+}
+
+// run sends queued requests until the engine exits.
+func (s *HistoricalScheduler) run() {
+	for {
+		s.mu.Lock()
+		entry, wait := s.next(time.Now())
+		s.mu.Unlock()
+
+		if entry != nil {
+			// a request lost to a network error after it was sent is sent
+			// again by its manager's preLoop once reconnected, but one which
+			// failed to send must be queued again here
+			req := entry.req
+			if err := s.eng.Send(&req); err != nil {
+				select {
+				case <-s.eng.terminated:
+					return
+				default:
+				}
+				s.requeue(entry)
+			}
+			continue
+		}
+
+		var timer <-chan time.Time
+		if wait > 0 {
+			timer = time.After(wait)
+		}
+		select {
+		case <-s.wake:
+		case <-timer:
+		case <-s.eng.terminated:
+			return
+		}
+	}
+}
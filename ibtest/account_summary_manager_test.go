@@ -0,0 +1,83 @@
+package ibtest
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofinance/ib"
+)
+
+func summary(account string, tag string, value string, currency string) *ib.AccountSummary {
+	return &ib.AccountSummary{Key: ib.AccountSummaryKey{AccountCode: account, Key: tag}, Value: value, Currency: currency}
+}
+
+func TestAccountSummaryManager(t *testing.T) {
+	gw := newGateway(t)
+	defer gw.Close()
+
+	gw.Reply(&ib.RequestAccountSummary{}, AnyID,
+		summary("DU1", ib.AccountSummaryTagAccountType, "INDIVIDUAL", ""),
+		summary("DU1", ib.AccountSummaryTagNetLiquidation, "100000.50", "USD"),
+		summary("DU1", ib.AccountSummaryTagDayTradesRemaining, "-1", ""),
+		summary("DU2", ib.AccountSummaryTagNetLiquidation, "2500", "EUR"),
+		&ib.AccountSummaryEnd{},
+	)
+
+	engine := newEngine(t, gw, ib.EngineOptions{})
+	defer engine.Stop()
+
+	tags := []string{ib.AccountSummaryTagAccountType, ib.AccountSummaryTagNetLiquidation, ib.AccountSummaryTagDayTradesRemaining}
+	m, err := ib.NewAccountSummaryManager(engine, "All", tags...)
+	if err != nil {
+		t.Fatalf("error creating manager: %s", err)
+	}
+	defer m.Close()
+
+	refresh := func() {
+		select {
+		case <-m.Refresh():
+		case <-time.After(timeout):
+			t.Fatal("no refresh")
+		}
+	}
+	refresh()
+
+	req, err := gw.Await(&ib.RequestAccountSummary{}, AnyID, timeout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r := req.(*ib.RequestAccountSummary); r.Group != "All" || r.Tags != strings.Join(tags, ",") {
+		t.Fatalf("unexpected request %+v", r)
+	}
+
+	if a := m.Accounts(); len(a) != 2 || a[0] != "DU1" || a[1] != "DU2" {
+		t.Fatalf("unexpected accounts %v", a)
+	}
+	if v, err := m.NetLiquidation("DU1"); err != nil || v.Value != 100000.5 || v.Currency != "USD" {
+		t.Fatalf("unexpected net liquidation %+v (%v)", v, err)
+	}
+	if v, err := m.DayTradesRemaining("DU1"); err != nil || v != -1 {
+		t.Fatalf("unexpected day trades remaining %v (%v)", v, err)
+	}
+	if v, err := m.AccountType("DU1"); err != nil || v != "INDIVIDUAL" {
+		t.Fatalf("unexpected account type %v (%v)", v, err)
+	}
+	if _, err := m.ExcessLiquidity("DU1"); err == nil {
+		t.Fatal("expected error for a tag not requested")
+	}
+	if _, err := m.Amount("DU1", ib.AccountSummaryTagAccountType); err == nil {
+		t.Fatal("expected error for a non-numeric tag")
+	}
+
+	// updates keep streaming after the end of the initial summary
+	update := summary("DU2", ib.AccountSummaryTagNetLiquidation, "2600", "EUR")
+	update.SetID(req.(ib.MatchedRequest).ID())
+	if err := gw.Send(update); err != nil {
+		t.Fatal(err)
+	}
+	refresh()
+	if v, err := m.NetLiquidation("DU2"); err != nil || v.Value != 2600 {
+		t.Fatalf("unexpected updated net liquidation %+v (%v)", v, err)
+	}
+}
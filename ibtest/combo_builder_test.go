@@ -0,0 +1,44 @@
+package ibtest
+
+import (
+	"testing"
+
+	"github.com/gofinance/ib"
+)
+
+func TestBuildComboResolvesLegs(t *testing.T) {
+	gw := newGateway(t)
+	defer gw.Close()
+
+	call := ib.Contract{Symbol: "SPY", SecurityType: "OPT", Right: "C", Expiry: "20240621", Exchange: "SMART", Currency: "USD"}
+	lower, upper := call, call
+	lower.Strike, upper.Strike = 400, 410
+	resolved := func(c ib.Contract, id int64) *ib.ContractData {
+		c.ContractID = id
+		return &ib.ContractData{Contract: ib.ContractDetails{Summary: c}}
+	}
+	gw.Reply(&ib.RequestContractData{}, AnyID, resolved(lower, 101), &ib.ContractDataEnd{})
+	gw.Reply(&ib.RequestContractData{}, AnyID, resolved(upper, 102), &ib.ContractDataEnd{})
+
+	engine := newEngine(t, gw, ib.EngineOptions{})
+	defer engine.Stop()
+
+	r, err := ib.NewContractResolver(engine, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	legs, err := ib.VerticalSpread(lower, upper)
+	if err != nil {
+		t.Fatal(err)
+	}
+	combo, err := ib.BuildCombo(r, "SMART", legs...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(combo.ComboLegs) != 2 || combo.ComboLegs[0].ContractID != 101 || combo.ComboLegs[1].ContractID != 102 {
+		t.Fatalf("unexpected combo legs %+v", combo.ComboLegs)
+	}
+	if combo.ComboLegs[1].Action != ib.ActionSell {
+		t.Fatalf("expected the upper strike sold, got %+v", combo.ComboLegs[1])
+	}
+}
@@ -0,0 +1,138 @@
+package ibtest
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gofinance/ib"
+)
+
+var partial = ib.Contract{Symbol: "AAPL", SecurityType: "STK", Exchange: "SMART", Currency: "USD"}
+
+func details(id int64, primary string) *ib.ContractData {
+	c := partial
+	c.ContractID = id
+	c.PrimaryExchange = primary
+	return &ib.ContractData{Contract: ib.ContractDetails{Summary: c, MinTick: 0.01, LongName: "APPLE INC"}}
+}
+
+func contractRequests(gw *Gateway) int {
+	n := 0
+	for _, r := range gw.Requests() {
+		if _, ok := r.(*ib.RequestContractData); ok {
+			n++
+		}
+	}
+	return n
+}
+
+func TestContractResolver(t *testing.T) {
+	gw := newGateway(t)
+	defer gw.Close()
+	gw.Reply(&ib.RequestContractData{}, AnyID, details(265598, "NASDAQ"), &ib.ContractDataEnd{})
+
+	engine := newEngine(t, gw, ib.EngineOptions{})
+	defer engine.Stop()
+
+	dir, err := ioutil.TempDir("", "goib")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "contracts.json")
+
+	r, err := ib.NewContractResolver(engine, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := r.Resolve(partial)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.ContractID != 265598 || c.PrimaryExchange != "NASDAQ" {
+		t.Fatalf("unexpected contract %+v", c)
+	}
+	req, err := gw.Await(&ib.RequestContractData{}, AnyID, timeout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := req.(*ib.RequestContractData).Contract; got.Symbol != "AAPL" || got.ContractID != 0 {
+		t.Fatalf("unexpected request for %+v", got)
+	}
+
+	// repeat lookups, by query or by ContractID, come from the cache
+	if _, err := r.Resolve(partial); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Resolve(ib.Contract{ContractID: 265598}); err != nil {
+		t.Fatal(err)
+	}
+	if n := contractRequests(gw); n != 1 {
+		t.Fatalf("expected 1 contract data request, got %d", n)
+	}
+
+	// as do lookups by a new resolver loading the cache file
+	r2, err := ib.NewContractResolver(engine, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err := r2.ResolveDetails(partial)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Summary.ContractID != 265598 || d.LongName != "APPLE INC" || d.MinTick != 0.01 {
+		t.Fatalf("unexpected cached details %+v", d)
+	}
+	if _, ok := r2.Cached(265598); !ok {
+		t.Fatal("expected contract 265598 cached")
+	}
+	if n := contractRequests(gw); n != 1 {
+		t.Fatalf("expected 1 contract data request, got %d", n)
+	}
+}
+
+func TestContractResolverAmbiguous(t *testing.T) {
+	gw := newGateway(t)
+	defer gw.Close()
+	gw.Reply(&ib.RequestContractData{}, AnyID, details(265598, "NASDAQ"), details(38708077, "MEXI"), &ib.ContractDataEnd{})
+
+	engine := newEngine(t, gw, ib.EngineOptions{})
+	defer engine.Stop()
+
+	r, err := ib.NewContractResolver(engine, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = r.Resolve(partial)
+	ambiguous, ok := err.(*ib.AmbiguousContractError)
+	if !ok {
+		t.Fatalf("expected an ambiguous contract error, got %v", err)
+	}
+	if len(ambiguous.Candidates) != 2 || ambiguous.Candidates[1].Summary.ContractID != 38708077 {
+		t.Fatalf("unexpected candidates %+v", ambiguous.Candidates)
+	}
+	if _, ok := r.Cached(265598); ok {
+		t.Fatal("expected ambiguous candidates not cached")
+	}
+}
+
+func TestContractResolverUnknown(t *testing.T) {
+	gw := newGateway(t)
+	defer gw.Close()
+	gw.Reply(&ib.RequestContractData{}, AnyID, &ib.ErrorMessage{Code: 200, Message: "No security definition has been found for the request"})
+
+	engine := newEngine(t, gw, ib.EngineOptions{})
+	defer engine.Stop()
+
+	r, err := ib.NewContractResolver(engine, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Resolve(ib.Contract{SecIDType: "ISIN", SecID: "XX0000000000"}); err == nil {
+		t.Fatal("expected unknown contract error")
+	} else if _, ok := err.(*ib.UnknownContractError); !ok {
+		t.Fatalf("expected an unknown contract error, got %v", err)
+	}
+}
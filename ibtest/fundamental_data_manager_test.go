@@ -0,0 +1,46 @@
+package ibtest
+
+import (
+	"testing"
+
+	"github.com/gofinance/ib"
+)
+
+func TestFundamentalDataManager(t *testing.T) {
+	gw := newGateway(t)
+	defer gw.Close()
+
+	gw.Reply(&ib.RequestFundamentalData{}, AnyID, &ib.FundamentalData{Data: `<XMLNTC>
+	<Ratios PriceCurrency="USD" ReportingCurrency="USD">
+		<Group ID="Price and Volume"><Ratio FieldName="NPRICE" Type="N">153.83</Ratio></Group>
+	</Ratios>
+</XMLNTC>`})
+
+	engine := newEngine(t, gw, ib.EngineOptions{})
+	defer engine.Stop()
+
+	stock := ib.Contract{Symbol: "IBM", SecurityType: "STK", Exchange: "SMART", Currency: "USD"}
+	m, err := ib.NewFundamentalDataManager(engine, stock, ib.ReportTypeRatios)
+	if err != nil {
+		t.Fatalf("error creating manager: %s", err)
+	}
+	if _, err := ib.SinkManager(m, timeout, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := m.Ratios()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p, ok := r.Ratio("NPRICE"); !ok || p.Float != 153.83 {
+		t.Fatalf("unexpected ratios %+v", r.Ratios)
+	}
+
+	req, err := gw.Await(&ib.RequestFundamentalData{}, AnyID, timeout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if typ := req.(*ib.RequestFundamentalData).ReportType; typ != ib.ReportTypeRatios {
+		t.Fatalf("unexpected report type %q", typ)
+	}
+}
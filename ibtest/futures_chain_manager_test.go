@@ -0,0 +1,94 @@
+package ibtest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gofinance/ib"
+)
+
+func futureData(id int64, month string, expiry string) *ib.ContractData {
+	return &ib.ContractData{Contract: ib.ContractDetails{
+		Summary:       ib.Contract{ContractID: id, Symbol: "ES", SecurityType: "FUT", Exchange: "GLOBEX", Expiry: expiry},
+		ContractMonth: month,
+		TimezoneID:    "CST",
+	}}
+}
+
+func TestFuturesChainManager(t *testing.T) {
+	gw := newGateway(t)
+	defer gw.Close()
+
+	gw.Reply(&ib.RequestContractData{}, AnyID,
+		futureData(3, "202409", "20240920"),
+		futureData(1, "202403", "20240315"),
+		futureData(2, "202406", "20240621"),
+		&ib.ContractDataEnd{},
+	)
+
+	engine := newEngine(t, gw, ib.EngineOptions{})
+	defer engine.Stop()
+
+	m, err := ib.NewFuturesChainManager(engine, ib.Contract{Symbol: "ES", Exchange: "GLOBEX", Currency: "USD"})
+	if err != nil {
+		t.Fatalf("error creating manager: %s", err)
+	}
+	defer m.Close()
+	if _, err := ib.SinkManager(m, timeout, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	req, err := gw.Await(&ib.RequestContractData{}, AnyID, timeout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if typ := req.(*ib.RequestContractData).Contract.SecurityType; typ != "FUT" {
+		t.Fatalf("unexpected security type %q", typ)
+	}
+
+	contracts := m.Contracts()
+	if len(contracts) != 3 {
+		t.Fatalf("expected 3 contracts, got %d", len(contracts))
+	}
+	for i, d := range contracts {
+		if d.Summary.ContractID != int64(i+1) {
+			t.Fatalf("contracts out of order: %+v", contracts)
+		}
+	}
+
+	chicago, err := ib.LoadIBLocation("CST")
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, chicago)
+	if front, ok := m.Front(now); !ok || front.Summary.ContractID != 1 {
+		t.Fatalf("unexpected front contract %+v", front)
+	}
+	if next, ok := m.Next(now); !ok || next.Summary.ContractID != 2 {
+		t.Fatalf("unexpected next contract %+v", next)
+	}
+	// after the March contract's last trading day
+	if front, ok := m.Front(now.AddDate(0, 0, 6)); !ok || front.Summary.ContractID != 2 {
+		t.Fatalf("unexpected front contract %+v", front)
+	}
+	if _, ok := m.Front(now.AddDate(1, 0, 0)); ok {
+		t.Fatal("expected no front contract once all expired")
+	}
+
+	// rolling 8 days before expiry, the June contract is held from 7 March
+	policy := ib.DaysBeforeExpiry(8)
+	if active, ok := m.Active(now, policy); !ok || active.Summary.ContractID != 2 {
+		t.Fatalf("unexpected active contract %+v", active)
+	}
+	if active, ok := m.Active(now.AddDate(0, 0, -5), policy); !ok || active.Summary.ContractID != 1 {
+		t.Fatalf("unexpected active contract %+v", active)
+	}
+
+	rolls := m.Rolls(policy)
+	if len(rolls) != 2 || rolls[0].To.Summary.ContractID != 2 || rolls[1].From.Summary.ContractID != 2 {
+		t.Fatalf("unexpected rolls %+v", rolls)
+	}
+	if d := rolls[1].Date.Format("20060102"); d != "20240613" {
+		t.Fatalf("unexpected roll date %s", d)
+	}
+}
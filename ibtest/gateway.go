@@ -0,0 +1,280 @@
+// Package ibtest provides a scriptable fake IB Gateway, so engines and
+// managers can be tested without a running IB Gateway.
+//
+// A Gateway listens on a local TCP port, performs the server side of the
+// handshake, decodes every Request sent by clients and answers with the
+// Reply values queued by the test:
+//
+//	gw, _ := ibtest.NewGateway()
+//	defer gw.Close()
+//	gw.Reply(&ib.RequestMarketData{}, ibtest.AnyID, &ib.TickPrice{Type: ib.TickLast, Price: 1.5})
+//	engine, _ := ib.NewEngine(ib.EngineOptions{Gateway: gw.Addr()})
+package ibtest
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"net"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/gofinance/ib"
+)
+
+// AnyID matches a request regardless of its ID.
+const AnyID = ib.UnmatchedReplyID
+
+// DefaultAccount is the account reported by the default greeting.
+const DefaultAccount = "DU000001"
+
+type script struct {
+	typ reflect.Type
+	id  int64
+}
+
+type conn struct {
+	mu sync.Mutex // serializes writes
+	c  net.Conn
+}
+
+func (c *conn) send(replies []ib.Reply) error {
+	b := &bytes.Buffer{}
+	for _, r := range replies {
+		if err := ib.WriteReply(b, r); err != nil {
+			return err
+		}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, err := c.c.Write(b.Bytes())
+	return err
+}
+
+// Gateway is a fake IB Gateway.
+type Gateway struct {
+	l        net.Listener
+	wg       sync.WaitGroup
+	mu       sync.Mutex
+	greeting []ib.Reply
+	scripts  map[script][][]ib.Reply
+	requests []ib.Request
+	awaited  []bool
+	arrived  chan struct{} // closed (and replaced) on every new request
+	conns    map[*conn]struct{}
+	closed   bool
+}
+
+// NewGateway starts a Gateway listening on a random local port.
+func NewGateway() (*Gateway, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	g := &Gateway{
+		l: l,
+		greeting: []ib.Reply{
+			&ib.NextValidID{OrderID: 1},
+			&ib.ManagedAccounts{AccountsList: []string{DefaultAccount}},
+		},
+		scripts: map[script][][]ib.Reply{},
+		arrived: make(chan struct{}),
+		conns:   map[*conn]struct{}{},
+	}
+	g.wg.Add(1)
+	go g.accept()
+	return g, nil
+}
+
+// Addr returns the address to use as ib.EngineOptions.Gateway.
+func (g *Gateway) Addr() string {
+	return g.l.Addr().String()
+}
+
+// Close stops listening, drops all connections and waits for them to exit.
+func (g *Gateway) Close() error {
+	g.mu.Lock()
+	g.closed = true
+	g.mu.Unlock()
+	err := g.l.Close()
+	g.Disconnect()
+	g.wg.Wait()
+	return err
+}
+
+// Disconnect drops all client connections (eg to test reconnection).
+func (g *Gateway) Disconnect() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for c := range g.conns {
+		c.c.Close()
+		delete(g.conns, c)
+	}
+}
+
+// Greet sets the replies sent after each StartAPI request. By default these
+// are NextValidID 1 and ManagedAccounts DefaultAccount.
+func (g *Gateway) Greet(replies ...ib.Reply) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.greeting = replies
+}
+
+// Reply queues a batch of replies, to be sent once when a request of the same
+// type as req arrives with the given ID (or any ID, if AnyID). Batches for an
+// exact ID take precedence over AnyID batches. The ID of a request which is
+// not an ib.MatchedRequest is ignored.
+//
+// Any ib.MatchedReply with a zero ID is sent with the ID of the request.
+func (g *Gateway) Reply(req ib.Request, id int64, replies ...ib.Reply) {
+	if _, ok := req.(ib.MatchedRequest); !ok {
+		id = AnyID
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	k := script{typ: reflect.TypeOf(req), id: id}
+	g.scripts[k] = append(g.scripts[k], replies)
+}
+
+// Send sends the given replies to all connected clients.
+func (g *Gateway) Send(replies ...ib.Reply) error {
+	g.mu.Lock()
+	conns := make([]*conn, 0, len(g.conns))
+	for c := range g.conns {
+		conns = append(conns, c)
+	}
+	g.mu.Unlock()
+
+	for _, c := range conns {
+		if err := c.send(replies); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Requests returns all requests received so far, in order of arrival.
+func (g *Gateway) Requests() []ib.Request {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return append([]ib.Request(nil), g.requests...)
+}
+
+// Await blocks until a request of the same type as req and with the given ID
+// (or any ID, if AnyID) arrives, returning it. Each request is only returned
+// once, so repeated calls return repeated requests.
+func (g *Gateway) Await(req ib.Request, id int64, timeout time.Duration) (ib.Request, error) {
+	typ := reflect.TypeOf(req)
+	deadline := time.After(timeout)
+	for {
+		g.mu.Lock()
+		for i, r := range g.requests {
+			if !g.awaited[i] && reflect.TypeOf(r) == typ && matches(r, id) {
+				g.awaited[i] = true
+				g.mu.Unlock()
+				return r, nil
+			}
+		}
+		arrived := g.arrived
+		g.mu.Unlock()
+
+		select {
+		case <-arrived:
+		case <-deadline:
+			return nil, fmt.Errorf("ibtest: no %v (id %d) within %v", typ, id, timeout)
+		}
+	}
+}
+
+func matches(r ib.Request, id int64) bool {
+	if id == AnyID {
+		return true
+	}
+	if mr, ok := r.(ib.MatchedRequest); ok {
+		return mr.ID() == id
+	}
+	return true
+}
+
+func (g *Gateway) accept() {
+	defer g.wg.Done()
+	for {
+		c, err := g.l.Accept()
+		if err != nil {
+			return
+		}
+		cn := &conn{c: c}
+		g.mu.Lock()
+		if g.closed {
+			g.mu.Unlock()
+			c.Close()
+			return
+		}
+		g.conns[cn] = struct{}{}
+		g.mu.Unlock()
+
+		g.wg.Add(1)
+		go g.serve(cn)
+	}
+}
+
+func (g *Gateway) serve(c *conn) {
+	defer g.wg.Done()
+	defer func() {
+		g.mu.Lock()
+		delete(g.conns, c)
+		g.mu.Unlock()
+		c.c.Close()
+	}()
+
+	r := bufio.NewReader(c.c)
+	if _, err := ib.ServerHandshake(r, c.c, time.Now()); err != nil {
+		return
+	}
+	for {
+		req, err := ib.ReadRequest(r)
+		if err != nil {
+			return
+		}
+		if err := c.send(g.receive(req)); err != nil {
+			return
+		}
+	}
+}
+
+// receive records the request and returns the replies to send for it.
+func (g *Gateway) receive(req ib.Request) []ib.Reply {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.requests = append(g.requests, req)
+	g.awaited = append(g.awaited, false)
+	close(g.arrived)
+	g.arrived = make(chan struct{})
+
+	if _, ok := req.(*ib.StartAPI); ok {
+		return g.greeting
+	}
+
+	id := AnyID
+	if mr, ok := req.(ib.MatchedRequest); ok {
+		id = mr.ID()
+	}
+	typ := reflect.TypeOf(req)
+	for _, k := range []script{{typ: typ, id: id}, {typ: typ, id: AnyID}} {
+		batches := g.scripts[k]
+		if len(batches) == 0 {
+			continue
+		}
+		replies := batches[0]
+		g.scripts[k] = batches[1:]
+		for _, r := range replies {
+			if mr, ok := r.(ib.MatchedReply); ok && mr.ID() == 0 && id != AnyID {
+				mr.SetID(id)
+			}
+		}
+		return replies
+	}
+	return nil
+}
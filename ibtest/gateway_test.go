@@ -0,0 +1,264 @@
+package ibtest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gofinance/ib"
+)
+
+const timeout = 5 * time.Second
+
+func newGateway(t *testing.T) *Gateway {
+	gw, err := NewGateway()
+	if err != nil {
+		t.Fatalf("cannot start gateway: %s", err)
+	}
+	return gw
+}
+
+func newEngine(t *testing.T, gw *Gateway, opt ib.EngineOptions) *ib.Engine {
+	opt.Gateway = gw.Addr()
+	engine, err := ib.NewEngine(opt)
+	if err != nil {
+		t.Fatalf("cannot connect engine: %s", err)
+	}
+	return engine
+}
+
+var contract = ib.Contract{
+	Symbol:       "USD",
+	SecurityType: "CASH",
+	Exchange:     "IDEALPRO",
+	Currency:     "JPY",
+}
+
+func TestGatewayStartAPI(t *testing.T) {
+	gw := newGateway(t)
+	defer gw.Close()
+
+	engine := newEngine(t, gw, ib.EngineOptions{Client: 42})
+	defer engine.Stop()
+
+	req, err := gw.Await(&ib.StartAPI{}, AnyID, timeout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c := req.(*ib.StartAPI).Client; c != 42 {
+		t.Fatalf("expected client 42, got %d", c)
+	}
+}
+
+func TestGatewayInstrumentManager(t *testing.T) {
+	gw := newGateway(t)
+	defer gw.Close()
+
+	gw.Reply(&ib.RequestMarketData{}, AnyID,
+		&ib.TickPrice{Type: ib.TickBid, Price: 101.25, Size: 1},
+		&ib.TickPrice{Type: ib.TickAsk, Price: 101.5, Size: 1},
+	)
+
+	engine := newEngine(t, gw, ib.EngineOptions{})
+	defer engine.Stop()
+
+	i, err := ib.NewInstrumentManager(engine, contract)
+	if err != nil {
+		t.Fatalf("error creating manager: %s", err)
+	}
+
+	if _, err := ib.SinkManager(i, timeout, 1); err != nil {
+		t.Fatal(err)
+	}
+	if i.Bid() != 101.25 || i.Ask() != 101.5 {
+		t.Fatalf("expected bid/ask 101.25/101.5, got %v/%v", i.Bid(), i.Ask())
+	}
+
+	req, err := gw.Await(&ib.RequestMarketData{}, AnyID, timeout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c := req.(*ib.RequestMarketData).Contract; c.Symbol != contract.Symbol || c.Currency != contract.Currency {
+		t.Fatalf("unexpected contract %v", c)
+	}
+	if _, err := gw.Await(&ib.CancelMarketData{}, req.(ib.MatchedRequest).ID(), timeout); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGatewayHistoricalDataManager(t *testing.T) {
+	gw := newGateway(t)
+	defer gw.Close()
+
+	start := time.Date(2014, 3, 3, 10, 0, 0, 0, time.Local)
+	gw.Reply(&ib.RequestHistoricalData{}, AnyID, &ib.HistoricalData{
+		StartDate: "20140303  10:00:00",
+		EndDate:   "20140303  11:00:00",
+		Data: []ib.HistoricalDataItem{
+			{Date: start, Open: 1, High: 2, Low: 0.5, Close: 1.5, Volume: -1, WAP: -1, BarCount: -1},
+			{Date: start.Add(30 * time.Minute), Open: 1.5, High: 2, Low: 1, Close: 2, Volume: -1, WAP: -1, BarCount: -1, HasGaps: true},
+		},
+	})
+
+	engine := newEngine(t, gw, ib.EngineOptions{})
+	defer engine.Stop()
+
+	hdm, err := ib.NewHistoricalDataManager(engine, ib.RequestHistoricalData{
+		Contract:    contract,
+		EndDateTime: start.Add(time.Hour),
+		Duration:    "3600 S",
+		BarSize:     ib.HistBarSize30Min,
+		WhatToShow:  ib.HistBid,
+		UseRTH:      true,
+	})
+	if err != nil {
+		t.Fatalf("error creating manager: %s", err)
+	}
+
+	if _, err := ib.SinkManager(hdm, timeout, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	items := hdm.Items()
+	if len(items) != 2 {
+		t.Fatalf("expected 2 items, got %d", len(items))
+	}
+	if !items[1].Date.Equal(start.Add(30*time.Minute)) || items[1].Close != 2 || !items[1].HasGaps {
+		t.Fatalf("unexpected item %+v", items[1])
+	}
+
+	req, err := gw.Await(&ib.RequestHistoricalData{}, AnyID, timeout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r := req.(*ib.RequestHistoricalData); !r.EndDateTime.Equal(start.Add(time.Hour)) || r.BarSize != ib.HistBarSize30Min {
+		t.Fatalf("unexpected request %+v", r)
+	}
+}
+
+func TestGatewayErrorMessage(t *testing.T) {
+	gw := newGateway(t)
+	defer gw.Close()
+
+	gw.Reply(&ib.RequestMarketData{}, AnyID, &ib.ErrorMessage{Code: 200, Message: "No security definition has been found for the request"})
+
+	engine := newEngine(t, gw, ib.EngineOptions{})
+	defer engine.Stop()
+
+	i, err := ib.NewInstrumentManager(engine, contract)
+	if err != nil {
+		t.Fatalf("error creating manager: %s", err)
+	}
+
+	if _, err := ib.SinkManager(i, timeout, 1); err == nil {
+		t.Fatal("expected a fatal error")
+	}
+}
+
+func TestGatewayReconnect(t *testing.T) {
+	gw := newGateway(t)
+	defer gw.Close()
+
+	engine := newEngine(t, gw, ib.EngineOptions{
+		Reconnect: true,
+		Backoff:   func(int) time.Duration { return 10 * time.Millisecond },
+	})
+	defer engine.Stop()
+
+	i, err := ib.NewInstrumentManager(engine, contract)
+	if err != nil {
+		t.Fatalf("error creating manager: %s", err)
+	}
+	defer i.Close()
+
+	req, err := gw.Await(&ib.RequestMarketData{}, AnyID, timeout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := req.(ib.MatchedRequest).ID()
+
+	if _, err := gw.Await(&ib.StartAPI{}, AnyID, timeout); err != nil {
+		t.Fatal(err)
+	}
+
+	gw.Disconnect()
+
+	if _, err := gw.Await(&ib.StartAPI{}, AnyID, timeout); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := gw.Await(&ib.RequestMarketData{}, id, timeout); err != nil {
+		t.Fatal(err)
+	}
+
+	last := &ib.TickPrice{Type: ib.TickLast, Price: 99}
+	last.SetID(id)
+	if err := gw.Send(last); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-i.Refresh():
+	case <-time.After(timeout):
+		t.Fatal("no refresh after reconnect")
+	}
+	if i.Last() != 99 {
+		t.Fatalf("expected last 99, got %v", i.Last())
+	}
+	if engine.State() != ib.EngineReady {
+		t.Fatalf("expected engine ready, got %v", engine.State())
+	}
+}
+
+func TestGatewayReconnectDuringReceive(t *testing.T) {
+	gw := newGateway(t)
+	defer gw.Close()
+
+	engine := newEngine(t, gw, ib.EngineOptions{
+		Reconnect: true,
+		Backoff:   func(int) time.Duration { return 10 * time.Millisecond },
+	})
+	defer engine.Stop()
+
+	// a manager busy in receive does not read its state channel...
+	states := make(chan ib.EngineState)
+	engine.SubscribeState(states)
+	defer engine.UnsubscribeState(states)
+
+	rc := make(chan ib.Reply)
+	id := engine.NextRequestID()
+	engine.Subscribe(rc, id)
+
+	if _, err := gw.Await(&ib.StartAPI{}, AnyID, timeout); err != nil {
+		t.Fatal(err)
+	}
+	gw.Disconnect()
+	if _, err := gw.Await(&ib.StartAPI{}, AnyID, timeout); err != nil {
+		t.Fatal(err)
+	}
+
+	// ...while it calls back into the engine, as MetadataManager.request does
+	done := make(chan error, 1)
+	go func() {
+		engine.Unsubscribe(rc, id)
+		done <- engine.Send(&ib.RequestCurrentTime{})
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(timeout):
+		t.Fatal("engine blocked delivering state to a busy manager")
+	}
+
+	// once done, the manager catches up with the reconnection
+	for _, want := range []ib.EngineState{ib.EngineReconnecting, ib.EngineReconnected} {
+		select {
+		case s := <-states:
+			if s != want {
+				t.Fatalf("expected state %v, got %v", want, s)
+			}
+		case <-time.After(timeout):
+			t.Fatalf("no state %v", want)
+		}
+	}
+}
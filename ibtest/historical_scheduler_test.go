@@ -0,0 +1,77 @@
+package ibtest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gofinance/ib"
+)
+
+func TestHistoricalSchedulerPacing(t *testing.T) {
+	gw := newGateway(t)
+	defer gw.Close()
+
+	end := time.Date(2015, 6, 1, 16, 0, 0, 0, time.UTC)
+	data := func() *ib.HistoricalData {
+		return &ib.HistoricalData{
+			StartDate: "20150601  15:00:00",
+			EndDate:   "20150601  16:00:00",
+			Data:      []ib.HistoricalDataItem{{Date: end.Add(-time.Hour), Close: 1, Volume: -1, WAP: -1, BarCount: -1}},
+		}
+	}
+	gw.Reply(&ib.RequestHistoricalData{}, AnyID, &ib.ErrorMessage{Code: 162, Message: "Historical Market Data Service error message:Historical data request pacing violation"})
+	gw.Reply(&ib.RequestHistoricalData{}, AnyID, data())
+	gw.Reply(&ib.RequestHistoricalData{}, AnyID, data())
+
+	const identical = 200 * time.Millisecond
+	engine := newEngine(t, gw, ib.EngineOptions{HistoricalPacing: ib.HistoricalPacing{
+		Identical: identical,
+		Backoff:   ib.ExponentialBackoff(10*time.Millisecond, time.Second),
+	}})
+	defer engine.Stop()
+
+	request := ib.RequestHistoricalData{
+		Contract:    contract,
+		EndDateTime: end,
+		Duration:    "3600 S",
+		BarSize:     ib.HistBarSize1Hour,
+		WhatToShow:  ib.HistMidpoint,
+	}
+	started := time.Now()
+	var managers []*ib.HistoricalDataManager
+	for i := 0; i < 2; i++ {
+		m, err := ib.NewHistoricalDataManager(engine, request)
+		if err != nil {
+			t.Fatalf("error creating manager: %s", err)
+		}
+		managers = append(managers, m)
+	}
+
+	// sink concurrently, as error messages are delivered to every manager
+	errs := make(chan error)
+	for _, m := range managers {
+		go func(m *ib.HistoricalDataManager) {
+			_, err := ib.SinkManager(m, timeout, 1)
+			errs <- err
+		}(m)
+	}
+	for range managers {
+		if err := <-errs; err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, m := range managers {
+		if n := len(m.Items()); n != 1 {
+			t.Fatalf("expected 1 item, got %d", n)
+		}
+	}
+	// three identical requests (one retried) must be spaced out
+	if elapsed := time.Since(started); elapsed < 2*identical {
+		t.Fatalf("identical requests sent within %s", elapsed)
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := gw.Await(&ib.RequestHistoricalData{}, AnyID, timeout); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
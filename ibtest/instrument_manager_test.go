@@ -0,0 +1,74 @@
+package ibtest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gofinance/ib"
+)
+
+func TestInstrumentManagerQuote(t *testing.T) {
+	gw := newGateway(t)
+	defer gw.Close()
+
+	gw.Reply(&ib.RequestMarketData{}, AnyID,
+		&ib.TickPrice{Type: ib.TickBid, Price: 101.25, Size: 1},
+		&ib.TickPrice{Type: ib.TickAsk, Price: 101.5, Size: 2},
+		&ib.TickSize{Type: int64(ib.TickBidSize), Size: 300},
+		&ib.TickPrice{Type: ib.TickLast, Price: 101.4, Size: 200},
+		&ib.TickSize{Type: ib.TickVolume, Size: 12345},
+		&ib.TickPrice{Type: ib.TickHigh52Week, Price: 140},
+		&ib.TickPrice{Type: ib.TickClose, Price: 100},
+		&ib.TickGeneric{Type: ib.TickHalted, Value: 1},
+		&ib.TickGeneric{Type: ib.TickShortable, Value: 3},
+		&ib.TickString{Type: ib.TickLastTimestamp, Value: "1400000000"},
+		&ib.TickString{Type: ib.TickRTVolume, Value: "101.4;200;1400000000123;12345;101.38;true"},
+		&ib.TickOptionComputation{Type: ib.TickModelOption, ImpliedVol: 0.2, Delta: 0.5},
+		&ib.TickEFP{Type: ib.TickLastEFPComputation, BasisPoints: 1.5},
+	)
+
+	engine := newEngine(t, gw, ib.EngineOptions{})
+	defer engine.Stop()
+
+	i, err := ib.NewInstrumentManager(engine, contract, "233", "236")
+	if err != nil {
+		t.Fatalf("error creating manager: %s", err)
+	}
+
+	if _, err := ib.SinkManager(i, timeout, 12); err != nil {
+		t.Fatal(err)
+	}
+
+	q := i.Quote()
+	if q.Bid != 101.25 || q.Ask != 101.5 || q.BidSize != 300 || q.Volume != 12345 {
+		t.Fatalf("unexpected quote %+v", q)
+	}
+	// sizes carried by price ticks
+	if q.AskSize != 2 || q.Last != 101.4 || q.LastSize != 200 {
+		t.Fatalf("unexpected quote %+v", q)
+	}
+	if q.High52Week != 140 || q.Close != 100 || !q.Halted || q.Shortable != 3 {
+		t.Fatalf("unexpected quote %+v", q)
+	}
+	if !q.LastTimestamp.Equal(time.Unix(1400000000, 0)) {
+		t.Fatalf("unexpected last timestamp %v", q.LastTimestamp)
+	}
+	v := q.RTVolume
+	if v.Price != 101.4 || v.Size != 200 || v.TotalVolume != 12345 || v.VWAP != 101.38 || !v.SingleTrade {
+		t.Fatalf("unexpected RT volume %+v", v)
+	}
+	if !v.Time.Equal(time.Unix(1400000000, 123*int64(time.Millisecond))) {
+		t.Fatalf("unexpected RT volume time %v", v.Time)
+	}
+	if q.ModelOption.Delta != 0.5 || q.EFP.BasisPoints != 1.5 {
+		t.Fatalf("unexpected option/EFP computations %+v %+v", q.ModelOption, q.EFP)
+	}
+
+	req, err := gw.Await(&ib.RequestMarketData{}, AnyID, timeout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l := req.(*ib.RequestMarketData).GenericTickList; l != "233,236" {
+		t.Fatalf("expected generic tick list 233,236, got %q", l)
+	}
+}
@@ -0,0 +1,134 @@
+package ibtest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gofinance/ib"
+)
+
+var chainExpiry = time.Date(2017, time.June, 16, 0, 0, 0, 0, time.UTC)
+
+func optionChains(strikes ...float64) ib.OptionChains {
+	chain := &ib.OptionChain{Expiry: chainExpiry, Strikes: map[float64]*ib.OptionStrike{}}
+	for _, k := range strikes {
+		leg := func(right string) *ib.ContractData {
+			return &ib.ContractData{Contract: ib.ContractDetails{Summary: ib.Contract{
+				Symbol: "SPY", SecurityType: "OPT", Expiry: "20170616", Strike: k, Right: right, Exchange: "SMART", Currency: "USD",
+			}}}
+		}
+		chain.Strikes[k] = &ib.OptionStrike{Price: k, Call: leg("C"), Put: leg("P")}
+	}
+	return ib.OptionChains{chainExpiry: chain}
+}
+
+func TestLiveChainManager(t *testing.T) {
+	gw := newGateway(t)
+	defer gw.Close()
+
+	for i := 0; i < 6; i++ {
+		gw.Reply(&ib.RequestMarketData{}, AnyID,
+			&ib.TickPrice{Type: ib.TickBid, Price: 1.5, Size: 1},
+			&ib.TickPrice{Type: ib.TickAsk, Price: 1.6, Size: 1},
+			&ib.TickOptionComputation{Type: ib.TickModelOption, ImpliedVol: 0.2, Delta: 0.5, OptionPrice: 1.55, PvDividend: -1, Gamma: 0.05, Vega: 0.1, Theta: -0.02, SpotPrice: 100},
+		)
+	}
+
+	engine := newEngine(t, gw, ib.EngineOptions{})
+	defer engine.Stop()
+
+	filter := ib.ChainFilter{Spot: 100, StrikePercent: 6}
+	m, err := ib.NewLiveChainManager(engine, optionChains(90, 95, 100, 105, 110), filter, ib.LiveChainOptions{})
+	if err != nil {
+		t.Fatalf("error creating manager: %s", err)
+	}
+	defer m.Close()
+
+	// 3 strikes, 2 rights and 3 ticks each
+	if _, err := ib.SinkManager(m, timeout, 18); err != nil {
+		t.Fatal(err)
+	}
+
+	legs := m.Legs()
+	if len(legs) != 6 || legs[0].Strike != 95 || legs[0].Right != "C" || legs[5].Strike != 105 || legs[5].Right != "P" {
+		t.Fatalf("unexpected legs %+v", legs)
+	}
+	for _, l := range legs {
+		if !l.Streaming || l.Bid != 1.5 || l.Ask != 1.6 || l.Greeks.Delta != 0.5 || l.Greeks.PvDividend != ib.GreekNotComputed {
+			t.Fatalf("unexpected leg %+v", l)
+		}
+	}
+	if l, ok := m.Leg(chainExpiry, 100, "P"); !ok || l.Contract.Right != "P" || l.Contract.Strike != 100 {
+		t.Fatalf("unexpected leg %+v", l)
+	}
+
+	if e := m.Expiries(); len(e) != 1 || !e[0].Equal(chainExpiry) {
+		t.Fatalf("unexpected expiries %v", e)
+	}
+	smile := m.Smile(chainExpiry)
+	if len(smile) != 3 || smile[1].Strike != 100 || smile[1].CallIV != 0.2 || smile[1].PutIV != 0.2 {
+		t.Fatalf("unexpected smile %+v", smile)
+	}
+}
+
+func TestLiveChainManagerRotation(t *testing.T) {
+	gw := newGateway(t)
+	defer gw.Close()
+
+	for i := 0; i < 100; i++ {
+		gw.Reply(&ib.RequestMarketData{}, AnyID, &ib.TickPrice{Type: ib.TickLast, Price: 2, Size: 1})
+	}
+
+	engine := newEngine(t, gw, ib.EngineOptions{})
+	defer engine.Stop()
+
+	opt := ib.LiveChainOptions{Lines: 2, Rotation: 20 * time.Millisecond}
+	m, err := ib.NewLiveChainManager(engine, optionChains(90, 95, 100, 105, 110), ib.ChainFilter{Right: "C"}, opt)
+	if err != nil {
+		t.Fatalf("error creating manager: %s", err)
+	}
+	defer m.Close()
+
+	deadline := time.After(timeout)
+	for updated := 0; updated < 5; {
+		select {
+		case <-m.Refresh():
+		case <-deadline:
+			t.Fatalf("only %d legs updated", updated)
+		}
+		updated = 0
+		streaming := 0
+		for _, l := range m.Legs() {
+			if !l.Updated.IsZero() {
+				updated++
+			}
+			if l.Streaming {
+				streaming++
+			}
+		}
+		if streaming != 2 {
+			t.Fatalf("expected 2 legs streaming, got %d", streaming)
+		}
+	}
+
+	if _, err := gw.Await(&ib.CancelMarketData{}, AnyID, timeout); err != nil {
+		t.Fatal(err)
+	}
+
+	// rotation keeps the updates coming, so drain them while closing
+	go m.Close()
+	for range m.Refresh() {
+	}
+}
+
+func TestLiveChainManagerNoMatch(t *testing.T) {
+	gw := newGateway(t)
+	defer gw.Close()
+
+	engine := newEngine(t, gw, ib.EngineOptions{})
+	defer engine.Stop()
+
+	if _, err := ib.NewLiveChainManager(engine, optionChains(90), ib.ChainFilter{Spot: 100, StrikePercent: 5}, ib.LiveChainOptions{}); err == nil {
+		t.Fatal("expected error for a filter matching no options")
+	}
+}
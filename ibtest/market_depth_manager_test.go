@@ -0,0 +1,103 @@
+package ibtest
+
+import (
+	"testing"
+
+	"github.com/gofinance/ib"
+)
+
+func TestMarketDepthManager(t *testing.T) {
+	gw := newGateway(t)
+	defer gw.Close()
+
+	gw.Reply(&ib.RequestMarketDepth{}, AnyID,
+		&ib.MarketDepth{Position: 0, Operation: ib.DepthInsert, Side: ib.DepthBid, Price: 101, Size: 10},
+		&ib.MarketDepth{Position: 0, Operation: ib.DepthInsert, Side: ib.DepthBid, Price: 101.25, Size: 5},
+		&ib.MarketDepth{Position: 2, Operation: ib.DepthInsert, Side: ib.DepthBid, Price: 100.75, Size: 20},
+		&ib.MarketDepth{Position: 1, Operation: ib.DepthDelete, Side: ib.DepthBid},
+		&ib.MarketDepthL2{Position: 0, Operation: ib.DepthInsert, Side: ib.DepthAsk, Price: 101.5, Size: 7, MarketMaker: "MM1"},
+		&ib.MarketDepthL2{Position: 1, Operation: ib.DepthInsert, Side: ib.DepthAsk, Price: 101.75, Size: 3, MarketMaker: "MM2"},
+		&ib.MarketDepthL2{Position: 0, Operation: ib.DepthUpdate, Side: ib.DepthAsk, Price: 101.5, Size: 9, MarketMaker: "MM1"},
+	)
+
+	engine := newEngine(t, gw, ib.EngineOptions{})
+	defer engine.Stop()
+
+	m, err := ib.NewMarketDepthManager(engine, contract, 5)
+	if err != nil {
+		t.Fatalf("error creating manager: %s", err)
+	}
+
+	if _, err := ib.SinkManager(m, timeout, 7); err != nil {
+		t.Fatal(err)
+	}
+
+	bids, asks := m.Book()
+	if len(bids) != 2 || bids[0].Price != 101.25 || bids[1].Price != 100.75 {
+		t.Fatalf("unexpected bids %+v", bids)
+	}
+	if len(asks) != 2 || asks[0].Size != 9 || asks[1].MarketMaker != "MM2" {
+		t.Fatalf("unexpected asks %+v", asks)
+	}
+	if s, ok := m.Spread(); !ok || s != 0.25 {
+		t.Fatalf("expected spread 0.25, got %v", s)
+	}
+	if d := m.BidDepth(0); d != 25 {
+		t.Fatalf("expected bid depth 25, got %d", d)
+	}
+	if d := m.AskDepth(1); d != 9 {
+		t.Fatalf("expected ask depth 9, got %d", d)
+	}
+
+	req, err := gw.Await(&ib.RequestMarketDepth{}, AnyID, timeout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := req.(*ib.RequestMarketDepth).ID()
+	if n := req.(*ib.RequestMarketDepth).NumRows; n != 5 {
+		t.Fatalf("expected 5 rows, got %d", n)
+	}
+	if _, err := gw.Await(&ib.CancelMarketDepth{}, id, timeout); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMarketDepthManagerOutOfRange(t *testing.T) {
+	gw := newGateway(t)
+	defer gw.Close()
+
+	gw.Reply(&ib.RequestMarketDepth{}, AnyID,
+		&ib.MarketDepth{Position: 2, Operation: ib.DepthUpdate, Side: ib.DepthBid, Price: 99, Size: 30},
+		&ib.MarketDepth{Position: 2, Operation: ib.DepthInsert, Side: ib.DepthBid, Price: 99, Size: 30},
+		&ib.MarketDepth{Position: 0, Operation: ib.DepthInsert, Side: ib.DepthBid, Price: 101, Size: 10},
+		&ib.MarketDepth{Position: 1, Operation: ib.DepthInsert, Side: ib.DepthBid, Price: 100, Size: 20},
+		&ib.MarketDepth{Position: 3, Operation: ib.DepthUpdate, Side: ib.DepthBid, Price: 98, Size: 1},
+		&ib.MarketDepth{Position: 5, Operation: ib.DepthInsert, Side: ib.DepthBid, Price: 95, Size: 1},
+		&ib.MarketDepth{Position: 0, Operation: ib.DepthInsert, Side: ib.DepthAsk, Price: 102, Size: 5},
+	)
+
+	engine := newEngine(t, gw, ib.EngineOptions{})
+	defer engine.Stop()
+
+	m, err := ib.NewMarketDepthManager(engine, contract, 3)
+	if err != nil {
+		t.Fatalf("error creating manager: %s", err)
+	}
+
+	// inserts and updates beyond the end of the book are ignored, so only the
+	// in-range inserts refresh
+	if _, err := ib.SinkManager(m, timeout, 3); err != nil {
+		t.Fatal(err)
+	}
+
+	bids := m.Bids()
+	if len(bids) != 2 || bids[0].Price != 101 || bids[1].Price != 100 || bids[1].Size != 20 {
+		t.Fatalf("unexpected bids %+v", bids)
+	}
+	if b, ok := m.BestBid(); !ok || b.Price != 101 {
+		t.Fatalf("unexpected best bid %+v", b)
+	}
+	if s, ok := m.Spread(); !ok || s != 1 {
+		t.Fatalf("expected spread 1, got %v", s)
+	}
+}
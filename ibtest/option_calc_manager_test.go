@@ -0,0 +1,98 @@
+package ibtest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gofinance/ib"
+)
+
+func awaitGreeks(t *testing.T, m *ib.OptionCalcManager) ib.Greeks {
+	select {
+	case <-m.Refresh():
+	case <-time.After(timeout):
+		t.Fatal("no refresh")
+	}
+	return m.Greeks()
+}
+
+func TestOptionCalcManager(t *testing.T) {
+	gw := newGateway(t)
+	defer gw.Close()
+
+	gw.Reply(&ib.RequestCalcImpliedVol{}, AnyID,
+		&ib.TickOptionComputation{Type: ib.TickCustOptionComputation, ImpliedVol: 0.25, Delta: 0.55, OptionPrice: 3.2,
+			PvDividend: 0, Gamma: 0.04, Vega: 0.12, Theta: -0.03, SpotPrice: 100})
+	gw.Reply(&ib.RequestCalcOptionPrice{}, AnyID,
+		&ib.TickOptionComputation{Type: ib.TickCustOptionComputation, ImpliedVol: 0.3, Delta: -2, OptionPrice: 4.1,
+			PvDividend: -0.5, Gamma: 1.5, Vega: -2, Theta: 3, SpotPrice: 100})
+
+	engine := newEngine(t, gw, ib.EngineOptions{})
+	defer engine.Stop()
+
+	m, err := ib.NewOptionCalcManager(engine, contract)
+	if err != nil {
+		t.Fatalf("error creating manager: %s", err)
+	}
+	defer m.Close()
+
+	g, err := m.ImpliedVol(3.2, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.IV != 0.25 || g.Delta != 0.55 || g.OptPrice != 3.2 || g.Gamma != 0.04 || g.Theta != -0.03 || g.UndPrice != 100 {
+		t.Fatalf("unexpected implied vol greeks %+v", g)
+	}
+	if last := awaitGreeks(t, m); last != g {
+		t.Fatalf("unexpected last greeks %+v", last)
+	}
+	req, err := gw.Await(&ib.RequestCalcImpliedVol{}, AnyID, timeout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := gw.Await(&ib.CancelCalcImpliedVol{}, req.(ib.MatchedRequest).ID(), timeout); err != nil {
+		t.Fatal(err)
+	}
+
+	g, err = m.Price(0.3, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.OptPrice != 4.1 || g.IV != 0.3 {
+		t.Fatalf("unexpected option price greeks %+v", g)
+	}
+	if last := awaitGreeks(t, m); last != g {
+		t.Fatalf("unexpected last greeks %+v", last)
+	}
+	if req, err = gw.Await(&ib.RequestCalcOptionPrice{}, AnyID, timeout); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := gw.Await(&ib.CancelCalcOptionPrice{}, req.(ib.MatchedRequest).ID(), timeout); err != nil {
+		t.Fatal(err)
+	}
+	for name, v := range map[string]float64{"delta": g.Delta, "gamma": g.Gamma, "vega": g.Vega, "theta": g.Theta, "pv dividend": g.PvDividend} {
+		if v != ib.GreekNotComputed {
+			t.Fatalf("expected %s not computed, got %v", name, v)
+		}
+	}
+}
+
+func TestOptionCalcManagerError(t *testing.T) {
+	gw := newGateway(t)
+	defer gw.Close()
+
+	gw.Reply(&ib.RequestCalcImpliedVol{}, AnyID, &ib.ErrorMessage{Code: 200, Message: "No security definition has been found for the request"})
+
+	engine := newEngine(t, gw, ib.EngineOptions{})
+	defer engine.Stop()
+
+	m, err := ib.NewOptionCalcManager(engine, contract)
+	if err != nil {
+		t.Fatalf("error creating manager: %s", err)
+	}
+	defer m.Close()
+
+	if _, err := m.ImpliedVol(3.2, 100); err == nil {
+		t.Fatal("expected calculation error")
+	}
+}
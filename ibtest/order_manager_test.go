@@ -0,0 +1,253 @@
+package ibtest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gofinance/ib"
+)
+
+func newOrder(t *testing.T) ib.Order {
+	order, err := ib.NewOrder()
+	if err != nil {
+		t.Fatalf("cannot create order: %s", err)
+	}
+	order.Action = "BUY"
+	order.OrderType = "LMT"
+	order.TotalQty = 100
+	order.LimitPrice = 101.5
+	return order
+}
+
+func TestOrderManagerFill(t *testing.T) {
+	gw := newGateway(t)
+	defer gw.Close()
+	gw.Greet(&ib.NextValidID{OrderID: 7})
+
+	engine := newEngine(t, gw, ib.EngineOptions{})
+	defer engine.Stop()
+
+	exec := func(id string, shares int64, price float64) *ib.ExecutionData {
+		return &ib.ExecutionData{Exec: ib.Execution{OrderID: 7, ClientID: engine.ClientID(), ExecID: id, Shares: shares, Price: price}}
+	}
+
+	gw.Reply(&ib.PlaceOrder{}, 7,
+		&ib.OrderStatus{Status: ib.OrderPreSubmitted, Remaining: 100},
+		&ib.OrderStatus{Status: ib.OrderSubmitted, Remaining: 100},
+		&ib.OrderStatus{Status: ib.OrderFilled, Filled: 100, AverageFillPrice: 101.3},
+	)
+	gw.Reply(&ib.RequestExecutions{}, AnyID,
+		exec("e1", 40, 101),
+		&ib.CommissionReport{ExecutionID: "e1", Commission: 1},
+		exec("e2", 60, 101.5),
+		&ib.CommissionReport{ExecutionID: "e2", Commission: 1.5},
+		&ib.ExecutionDataEnd{},
+	)
+
+	m, err := ib.NewOrderManager(engine, contract, newOrder(t))
+	if err != nil {
+		t.Fatalf("error creating manager: %s", err)
+	}
+	defer m.Close()
+
+	if _, err := ib.SinkManager(m, timeout, 10); err != nil {
+		t.Fatal(err)
+	}
+	if s := m.Status(); s.Status != ib.OrderFilled || s.Filled != 100 {
+		t.Fatalf("unexpected status %+v", s)
+	}
+
+	req, err := gw.Await(&ib.PlaceOrder{}, 7, timeout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o := req.(*ib.PlaceOrder).Order; o.LimitPrice != 101.5 || o.TotalQty != 100 {
+		t.Fatalf("unexpected order %+v", o)
+	}
+
+	if m.OrderID() != 7 {
+		t.Fatalf("expected order ID 7, got %d", m.OrderID())
+	}
+	if n := len(m.Fills()); n != 2 {
+		t.Fatalf("expected 2 fills, got %d", n)
+	}
+	if n := len(m.Commissions()); n != 2 {
+		t.Fatalf("expected 2 commission reports, got %d", n)
+	}
+	if p := m.AvgPrice(); p != 101.3 {
+		t.Fatalf("expected average price 101.3, got %v", p)
+	}
+}
+
+func TestOrderManagerModifyCancel(t *testing.T) {
+	gw := newGateway(t)
+	defer gw.Close()
+	gw.Greet(&ib.NextValidID{OrderID: 8})
+
+	gw.Reply(&ib.PlaceOrder{}, 8, &ib.OrderStatus{Status: ib.OrderSubmitted, Remaining: 100})
+	gw.Reply(&ib.PlaceOrder{}, 8, &ib.OrderStatus{Status: ib.OrderSubmitted, Remaining: 100})
+	gw.Reply(&ib.CancelOrder{}, 8,
+		&ib.ErrorMessage{Code: 202, Message: "Order Canceled - reason:"},
+		&ib.OrderStatus{Status: ib.OrderCancelled, Remaining: 100},
+	)
+
+	engine := newEngine(t, gw, ib.EngineOptions{})
+	defer engine.Stop()
+
+	m, err := ib.NewOrderManager(engine, contract, newOrder(t))
+	if err != nil {
+		t.Fatalf("error creating manager: %s", err)
+	}
+	defer m.Close()
+
+	select {
+	case <-m.Refresh():
+	case <-time.After(timeout):
+		t.Fatal("no status received")
+	}
+
+	order := m.Order()
+	order.LimitPrice = 100
+	if err := m.Modify(order); err != nil {
+		t.Fatal(err)
+	}
+	<-m.Refresh()
+	if _, err := gw.Await(&ib.PlaceOrder{}, 8, timeout); err != nil {
+		t.Fatal(err)
+	}
+	req, err := gw.Await(&ib.PlaceOrder{}, 8, timeout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := req.(*ib.PlaceOrder).Order.LimitPrice; p != 100 {
+		t.Fatalf("expected modified limit price 100, got %v", p)
+	}
+
+	if err := m.Cancel(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ib.SinkManager(m, timeout, 10); err != nil {
+		t.Fatal(err)
+	}
+	if s := m.Status().Status; s != ib.OrderCancelled {
+		t.Fatalf("expected status %s, got %s", ib.OrderCancelled, s)
+	}
+}
+
+func TestOrderManagerInactive(t *testing.T) {
+	gw := newGateway(t)
+	defer gw.Close()
+	gw.Greet(&ib.NextValidID{OrderID: 9})
+
+	gw.Reply(&ib.PlaceOrder{}, 9,
+		&ib.OrderStatus{Status: ib.OrderPreSubmitted, Remaining: 100},
+		&ib.OrderStatus{Status: ib.OrderInactive, Remaining: 100},
+	)
+
+	engine := newEngine(t, gw, ib.EngineOptions{})
+	defer engine.Stop()
+
+	m, err := ib.NewOrderManager(engine, contract, newOrder(t))
+	if err != nil {
+		t.Fatalf("error creating manager: %s", err)
+	}
+	defer m.Close()
+
+	// an inactive order is final, so the manager finishes
+	if _, err := ib.SinkManager(m, timeout, 10); err != nil {
+		t.Fatal(err)
+	}
+	if s := m.Status().Status; s != ib.OrderInactive {
+		t.Fatalf("expected status %s, got %s", ib.OrderInactive, s)
+	}
+}
+
+func TestEngineNextOrderID(t *testing.T) {
+	gw := newGateway(t)
+	defer gw.Close()
+	gw.Greet(&ib.NextValidID{OrderID: 50})
+	gw.Reply(&ib.RequestIDs{}, AnyID, &ib.NextValidID{OrderID: 70})
+
+	engine := newEngine(t, gw, ib.EngineOptions{})
+	defer engine.Stop()
+
+	for _, expected := range []int64{50, 51} {
+		if id, err := engine.NextOrderID(); err != nil || id != expected {
+			t.Fatalf("expected order ID %d, got %d (%v)", expected, id, err)
+		}
+	}
+
+	if err := engine.SyncOrderID(); err != nil {
+		t.Fatal(err)
+	}
+	if id, err := engine.NextOrderID(); err != nil || id != 70 {
+		t.Fatalf("expected order ID 70 after sync, got %d (%v)", id, err)
+	}
+}
+
+func TestOrderManagerDuplicateOrderID(t *testing.T) {
+	gw := newGateway(t)
+	defer gw.Close()
+	gw.Greet(&ib.NextValidID{OrderID: 9})
+	gw.Reply(&ib.PlaceOrder{}, 9, &ib.ErrorMessage{Code: 103, Message: "Duplicate order id"})
+
+	engine := newEngine(t, gw, ib.EngineOptions{})
+	defer engine.Stop()
+
+	m, err := ib.NewOrderManager(engine, contract, newOrder(t))
+	if err != nil {
+		t.Fatalf("error creating manager: %s", err)
+	}
+	defer m.Close()
+
+	if _, err := ib.SinkManager(m, timeout, 10); err == nil {
+		t.Fatal("expected duplicate order ID error")
+	}
+	dup, ok := m.FatalError().(*ib.DuplicateOrderIDError)
+	if !ok || dup.OrderID != 9 {
+		t.Fatalf("expected duplicate order ID error for 9, got %v", m.FatalError())
+	}
+	if id, err := engine.NextOrderID(); err != nil || id <= 9 {
+		t.Fatalf("expected order ID sequence past 9, got %d (%v)", id, err)
+	}
+}
+
+func TestBracketPlaceOrders(t *testing.T) {
+	gw := newGateway(t)
+	defer gw.Close()
+	gw.Greet(&ib.NextValidID{OrderID: 7})
+
+	engine := newEngine(t, gw, ib.EngineOptions{})
+	defer engine.Stop()
+
+	b, err := ib.Bracket(newOrder(t), 105, 99)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reqs, err := b.PlaceOrders(engine, contract)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, req := range reqs {
+		if err := engine.Send(req); err != nil {
+			t.Fatal(err)
+		}
+		if req.ID() != int64(7+i) {
+			t.Fatalf("expected order ID %d, got %d", 7+i, req.ID())
+		}
+	}
+
+	for i, want := range []struct {
+		parent   int64
+		transmit bool
+	}{{0, false}, {7, false}, {7, true}} {
+		req, err := gw.Await(&ib.PlaceOrder{}, int64(7+i), timeout)
+		if err != nil {
+			t.Fatal(err)
+		}
+		o := req.(*ib.PlaceOrder).Order
+		if o.ParentID != want.parent || o.Transmit != want.transmit {
+			t.Fatalf("order %d: unexpected parent %d or transmit %t", 7+i, o.ParentID, o.Transmit)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package ibtest
+
+import (
+	"math"
+	"testing"
+
+	"github.com/gofinance/ib"
+)
+
+func TestPreviewOrder(t *testing.T) {
+	gw := newGateway(t)
+	defer gw.Close()
+	gw.Greet(&ib.NextValidID{OrderID: 9})
+
+	gw.Reply(&ib.PlaceOrder{}, 9, &ib.OpenOrder{
+		Contract: contract,
+		OrderState: ib.OrderState{
+			Status:             "PreSubmitted",
+			InitialMargin:      "2500.5",
+			MaintenanceMargin:  "2000",
+			CommissionCurrency: "USD",
+			Commission:         math.MaxFloat64,
+			MinCommission:      1,
+			MaxCommission:      2.5,
+		},
+	})
+
+	engine := newEngine(t, gw, ib.EngineOptions{})
+	defer engine.Stop()
+
+	impact, err := ib.PreviewOrder(engine, contract, newOrder(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if impact.InitialMargin != 2500.5 || impact.MaintenanceMargin != 2000 || impact.EquityWithLoan != math.MaxFloat64 {
+		t.Fatalf("unexpected margins %+v", impact)
+	}
+	if impact.MinCommission != 1 || impact.MaxCommission != 2.5 || impact.CommissionCurrency != "USD" {
+		t.Fatalf("unexpected commission %+v", impact)
+	}
+
+	req, err := gw.Await(&ib.PlaceOrder{}, 9, timeout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !req.(*ib.PlaceOrder).Order.WhatIf {
+		t.Fatal("expected a what-if order")
+	}
+}
+
+func TestPreviewOrderRejected(t *testing.T) {
+	gw := newGateway(t)
+	defer gw.Close()
+	gw.Greet(&ib.NextValidID{OrderID: 9})
+
+	gw.Reply(&ib.PlaceOrder{}, 9, &ib.ErrorMessage{Code: 201, Message: "Order rejected - reason: insufficient margin"})
+
+	engine := newEngine(t, gw, ib.EngineOptions{})
+	defer engine.Stop()
+
+	if _, err := ib.PreviewOrder(engine, contract, newOrder(t)); err == nil {
+		t.Fatal("expected rejection error")
+	}
+}
@@ -0,0 +1,71 @@
+package ibtest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gofinance/ib"
+)
+
+func TestPagedHistoricalDataManager(t *testing.T) {
+	gw := newGateway(t)
+	defer gw.Close()
+
+	day := func(month time.Month, d int) time.Time {
+		return time.Date(2015, month, d, 0, 0, 0, 0, time.UTC)
+	}
+	bars := func(dates ...time.Time) *ib.HistoricalData {
+		h := &ib.HistoricalData{StartDate: "20150101  00:00:00", EndDate: "20150311  00:00:00"}
+		for _, d := range dates {
+			h.Data = append(h.Data, ib.HistoricalDataItem{Date: d.Add(12 * time.Hour), Close: float64(d.YearDay()), Volume: -1, WAP: -1, BarCount: -1})
+		}
+		return h
+	}
+	gw.Reply(&ib.RequestHistoricalData{}, AnyID, bars(day(2, 10), day(3, 10)))
+	gw.Reply(&ib.RequestHistoricalData{}, AnyID, bars(day(1, 10), day(2, 8), day(2, 10)))
+	gw.Reply(&ib.RequestHistoricalData{}, AnyID, bars(time.Date(2014, 12, 31, 0, 0, 0, 0, time.UTC), day(1, 5), day(1, 10)))
+
+	engine := newEngine(t, gw, ib.EngineOptions{})
+	defer engine.Stop()
+
+	m, err := ib.NewPagedHistoricalDataManager(engine, ib.RequestHistoricalData{
+		Contract:    contract,
+		EndDateTime: day(3, 11),
+		BarSize:     ib.HistBarSize1Hour,
+		WhatToShow:  ib.HistMidpoint,
+	}, day(1, 1))
+	if err != nil {
+		t.Fatalf("error creating manager: %s", err)
+	}
+
+	if updates, err := ib.SinkManager(m, timeout, 10); err != nil || updates != 3 {
+		t.Fatalf("expected 3 pages, got %d (%v)", updates, err)
+	}
+	if p := m.Progress(); p != 1 {
+		t.Fatalf("expected progress 1, got %v", p)
+	}
+
+	items := m.Items()
+	expected := []time.Time{day(1, 5), day(1, 10), day(2, 8), day(2, 10), day(3, 10)}
+	if len(items) != len(expected) {
+		t.Fatalf("expected %d items, got %+v", len(expected), items)
+	}
+	for i, d := range expected {
+		if !items[i].Date.Equal(d.Add(12 * time.Hour)) {
+			t.Fatalf("item %d: expected %v, got %v", i, d, items[i].Date)
+		}
+	}
+
+	for i, w := range []struct {
+		end      time.Time
+		duration string
+	}{{day(3, 11), "30 D"}, {day(2, 9), "30 D"}, {day(1, 10), "9 D"}} {
+		req, err := gw.Await(&ib.RequestHistoricalData{}, AnyID, timeout)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r := req.(*ib.RequestHistoricalData); !r.EndDateTime.Equal(w.end) || r.Duration != w.duration {
+			t.Fatalf("window %d: expected %v/%s, got %v/%s", i, w.end, w.duration, r.EndDateTime, r.Duration)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package ibtest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gofinance/ib"
+)
+
+func position(conID int64, qty float64, cost float64) *ib.Position {
+	c := contract
+	c.ContractID = conID
+	return &ib.Position{Key: ib.PositionKey{AccountCode: "DU1", ContractID: conID}, Contract: c, Position: qty, AverageCost: cost}
+}
+
+func awaitChanges(t *testing.T, m *ib.PositionManager) []ib.PositionChange {
+	select {
+	case <-m.Refresh():
+	case <-time.After(timeout):
+		t.Fatal("no refresh")
+	}
+	return m.Changes()
+}
+
+func TestPositionManager(t *testing.T) {
+	gw := newGateway(t)
+	defer gw.Close()
+
+	gw.Reply(&ib.RequestPositions{}, AnyID, position(1, 100, 1.5), position(2, 0, 0), position(3, -5, 20), &ib.PositionEnd{})
+	gw.Reply(&ib.RequestPositions{}, AnyID, position(1, 100, 1.5), &ib.PositionEnd{})
+
+	engine := newEngine(t, gw, ib.EngineOptions{
+		Reconnect: true,
+		Backoff:   func(int) time.Duration { return 10 * time.Millisecond },
+	})
+	defer engine.Stop()
+
+	m, err := ib.NewPositionManager(engine)
+	if err != nil {
+		t.Fatalf("error creating manager: %s", err)
+	}
+	defer m.Close()
+
+	changes := awaitChanges(t, m)
+	if len(changes) != 2 || changes[0].Kind != ib.PositionOpened || changes[1].New.Position != -5 {
+		t.Fatalf("unexpected initial changes %+v", changes)
+	}
+	if p := m.Positions(); len(p) != 2 {
+		t.Fatalf("expected 2 positions (zero dropped), got %+v", p)
+	}
+
+	if err := gw.Send(position(1, 150, 1.6)); err != nil {
+		t.Fatal(err)
+	}
+	changes = awaitChanges(t, m)
+	if len(changes) != 1 || changes[0].Kind != ib.PositionChanged || changes[0].Old.Position != 100 || changes[0].New.Position != 150 {
+		t.Fatalf("unexpected change %+v", changes)
+	}
+
+	if err := gw.Send(position(3, 0, 0)); err != nil {
+		t.Fatal(err)
+	}
+	changes = awaitChanges(t, m)
+	if len(changes) != 1 || changes[0].Kind != ib.PositionClosed || changes[0].Old.Position != -5 {
+		t.Fatalf("unexpected close %+v", changes)
+	}
+	if _, ok := m.Position(ib.PositionKey{AccountCode: "DU1", ContractID: 3}); ok {
+		t.Fatal("closed position still held")
+	}
+
+	// after the reconnect position 1 is resent at 100, and position 4 is gone
+	if err := gw.Send(position(4, 10, 3)); err != nil {
+		t.Fatal(err)
+	}
+	if changes = awaitChanges(t, m); len(changes) != 1 || changes[0].Kind != ib.PositionOpened {
+		t.Fatalf("unexpected open %+v", changes)
+	}
+	gw.Disconnect()
+
+	changes = awaitChanges(t, m)
+	if len(changes) != 2 || changes[0].Kind != ib.PositionChanged || changes[1].Kind != ib.PositionClosed || changes[1].Old.Key.ContractID != 4 {
+		t.Fatalf("unexpected changes after reconnect %+v", changes)
+	}
+	if p := m.Positions(); len(p) != 1 || p[ib.PositionKey{AccountCode: "DU1", ContractID: 1}].Position != 100 {
+		t.Fatalf("unexpected positions after reconnect %+v", p)
+	}
+}
+
+func TestPositionManagerConnectivity(t *testing.T) {
+	gw := newGateway(t)
+	defer gw.Close()
+
+	gw.Reply(&ib.RequestPositions{}, AnyID, position(1, 100, 1.5), &ib.PositionEnd{})
+
+	engine := newEngine(t, gw, ib.EngineOptions{})
+	defer engine.Stop()
+
+	m, err := ib.NewPositionManager(engine)
+	if err != nil {
+		t.Fatalf("error creating manager: %s", err)
+	}
+	defer m.Close()
+
+	if changes := awaitChanges(t, m); len(changes) != 1 {
+		t.Fatalf("unexpected initial changes %+v", changes)
+	}
+
+	// neither the gateway losing and regaining IB's servers, nor an error for
+	// some other request without an ID, is a positions error
+	unrelated := &ib.ErrorMessage{Code: 10168, Message: "Requested market data is not subscribed. Delayed market data is not enabled."}
+	unrelated.SetID(-1)
+	lost := &ib.ErrorMessage{Code: 1100, Message: "Connectivity between IB and Trader Workstation has been lost."}
+	lost.SetID(-1)
+	restored := &ib.ErrorMessage{Code: 1102, Message: "Connectivity between IB and Trader Workstation has been restored - data maintained."}
+	restored.SetID(-1)
+	if err := gw.Send(unrelated, lost, restored, position(1, 150, 1.6)); err != nil {
+		t.Fatal(err)
+	}
+	if changes := awaitChanges(t, m); len(changes) != 1 || changes[0].New.Position != 150 {
+		t.Fatalf("unexpected change %+v", changes)
+	}
+	if err := m.FatalError(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPositionManagerError(t *testing.T) {
+	gw := newGateway(t)
+	defer gw.Close()
+
+	e := &ib.ErrorMessage{Code: 321, Message: "Error validating request:-'a0' : cause - Positions request is not allowed"}
+	e.SetID(-1)
+	gw.Reply(&ib.RequestPositions{}, AnyID, e)
+
+	engine := newEngine(t, gw, ib.EngineOptions{})
+	defer engine.Stop()
+
+	m, err := ib.NewPositionManager(engine)
+	if err != nil {
+		t.Fatalf("error creating manager: %s", err)
+	}
+	if _, err := ib.SinkManager(m, timeout, 1); err == nil {
+		t.Fatal("expected a fatal error")
+	}
+}
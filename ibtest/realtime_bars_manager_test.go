@@ -0,0 +1,76 @@
+package ibtest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gofinance/ib"
+)
+
+func TestRealTimeBarsManager(t *testing.T) {
+	gw := newGateway(t)
+	defer gw.Close()
+
+	start := time.Date(2015, 6, 1, 14, 0, 0, 0, time.UTC)
+	var bars []ib.Reply
+	for i := 0; i < 14; i++ {
+		bars = append(bars, &ib.RealtimeBars{
+			Time:   start.Add(time.Duration(i) * 5 * time.Second).Unix(),
+			Open:   float64(100 + i),
+			High:   float64(101 + i),
+			Low:    float64(99 + i),
+			Close:  float64(100 + i),
+			Volume: 10,
+			WAP:    float64(100 + i),
+			Count:  2,
+		})
+	}
+	gw.Reply(&ib.RequestRealTimeBars{}, AnyID, bars...)
+
+	engine := newEngine(t, gw, ib.EngineOptions{})
+	defer engine.Stop()
+
+	m, err := ib.NewRealTimeBarsManager(engine, ib.RequestRealTimeBars{
+		Contract:   contract,
+		WhatToShow: ib.RealTimeMidpoint,
+	}, 10, time.Minute)
+	if err != nil {
+		t.Fatalf("error creating manager: %s", err)
+	}
+
+	if _, err := ib.SinkManager(m, timeout, len(bars)); err != nil {
+		t.Fatal(err)
+	}
+
+	b := m.Bars()
+	if len(b) != 10 || !b[0].Time.Equal(start.Add(20*time.Second)) || b[9].Close != 113 {
+		t.Fatalf("unexpected bars %+v", b)
+	}
+
+	agg := m.Aggregated(time.Minute)
+	if len(agg) != 1 {
+		t.Fatalf("expected 1 aggregated bar, got %+v", agg)
+	}
+	if !agg[0].Time.Equal(start) {
+		t.Fatalf("expected aggregated bar at %v, got %v", start, agg[0].Time)
+	}
+	agg[0].Time = time.Time{}
+	expected := ib.RealTimeBar{Open: 100, High: 112, Low: 99, Close: 111, Volume: 120, WAP: 105.5, Count: 24}
+	if agg[0] != expected {
+		t.Fatalf("expected aggregated bar %+v, got %+v", expected, agg[0])
+	}
+	if cur, ok := m.Current(time.Minute); !ok || cur.Open != 112 || cur.Close != 113 || cur.Volume != 20 {
+		t.Fatalf("unexpected current bar %+v", cur)
+	}
+
+	req, err := gw.Await(&ib.RequestRealTimeBars{}, AnyID, timeout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := req.(*ib.RequestRealTimeBars).BarSize; s != 5 {
+		t.Fatalf("expected bar size 5, got %d", s)
+	}
+	if _, err := gw.Await(&ib.CancelRealTimeBars{}, req.(ib.MatchedRequest).ID(), timeout); err != nil {
+		t.Fatal(err)
+	}
+}
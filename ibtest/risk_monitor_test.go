@@ -0,0 +1,122 @@
+package ibtest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gofinance/ib"
+)
+
+func TestRiskMonitor(t *testing.T) {
+	gw := newGateway(t)
+	defer gw.Close()
+
+	gw.Reply(&ib.RequestAccountSummary{}, AnyID,
+		summary("DU1", ib.AccountSummaryTagCushion, "0.2", ""),
+		summary("DU1", ib.AccountSummaryTagExcessLiquidity, "1000", "USD"),
+		&ib.AccountSummaryEnd{},
+	)
+
+	engine := newEngine(t, gw, ib.EngineOptions{})
+	defer engine.Stop()
+
+	s, err := ib.NewAccountSummaryManager(engine, "All", ib.AccountSummaryTagCushion, ib.AccountSummaryTagExcessLiquidity)
+	if err != nil {
+		t.Fatalf("error creating manager: %s", err)
+	}
+	defer s.Close()
+	go func() {
+		for range s.Refresh() {
+		}
+	}()
+
+	if _, err := ib.NewRiskMonitor(s, ib.RiskRule{Tag: ib.AccountSummaryTagLeverage}); err == nil {
+		t.Fatal("expected error for a tag not streamed")
+	}
+	m, err := ib.NewRiskMonitor(s,
+		ib.RiskRule{Name: "cushion", Tag: ib.AccountSummaryTagCushion, Comparator: ib.RiskBelow, Threshold: 0.1, Hysteresis: 0.05},
+		ib.RiskRule{Name: "excess", Tag: ib.AccountSummaryTagExcessLiquidity, Account: "DU1", Comparator: ib.RiskBelow, KillSwitch: true},
+	)
+	if err != nil {
+		t.Fatalf("error creating monitor: %s", err)
+	}
+	defer m.Close()
+
+	req, err := gw.Await(&ib.RequestAccountSummary{}, AnyID, timeout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := req.(ib.MatchedRequest).ID()
+	value := func(tag string, value string) ib.Reply {
+		s := summary("DU1", tag, value, "")
+		s.SetID(id)
+		return s
+	}
+	send := func(replies ...ib.Reply) {
+		if err := gw.Send(replies...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	alert := func() ib.RiskAlert {
+		var a ib.RiskAlert
+		select {
+		case a = <-m.Alerts():
+		case <-time.After(timeout):
+			t.Fatal("no alert")
+		}
+		select {
+		case <-m.Refresh():
+		case <-time.After(timeout):
+			t.Fatal("no refresh")
+		}
+		return a
+	}
+
+	send(value(ib.AccountSummaryTagCushion, "0.08"))
+	if a := alert(); a.Kind != ib.RiskFired || a.Rule.Name != "cushion" || a.Account != "DU1" || a.Value.Value != 0.08 || a.GlobalCancel {
+		t.Fatalf("unexpected alert %+v", a)
+	}
+	if f := m.Firing(); len(f) != 1 || f[0].Rule.Name != "cushion" {
+		t.Fatalf("unexpected firing %+v", f)
+	}
+	if v, err := s.Amount("DU1", ib.AccountSummaryTagCushion); err != nil || v.Value != 0.08 {
+		t.Fatalf("unexpected cushion %+v (%v)", v, err)
+	}
+
+	// within the hysteresis, so the rule stays fired
+	send(value(ib.AccountSummaryTagCushion, "0.12"), value(ib.AccountSummaryTagCushion, "0.16"))
+	if a := alert(); a.Kind != ib.RiskCleared || a.Value.Value != 0.16 {
+		t.Fatalf("unexpected alert %+v", a)
+	}
+
+	// a value which crosses the threshold and returns straight away is still
+	// seen, and fires the kill switch
+	send(value(ib.AccountSummaryTagExcessLiquidity, "-5"), value(ib.AccountSummaryTagExcessLiquidity, "500"))
+	if a := alert(); a.Kind != ib.RiskFired || a.Rule.Name != "excess" || a.Value.Value != -5 || !a.GlobalCancel || a.CancelErr != nil {
+		t.Fatalf("unexpected alert %+v", a)
+	}
+	if a := alert(); a.Kind != ib.RiskCleared || a.Rule.Name != "excess" || a.Value.Value != 500 {
+		t.Fatalf("unexpected alert %+v", a)
+	}
+	if _, err := gw.Await(&ib.RequestGlobalCancel{}, AnyID, timeout); err != nil {
+		t.Fatal(err)
+	}
+	n := 0
+	for _, r := range gw.Requests() {
+		if _, ok := r.(*ib.RequestAccountSummary); ok {
+			n++
+		}
+	}
+	if n != 1 {
+		t.Fatalf("expected one account summary request, got %d", n)
+	}
+
+	// the monitor closes with the account summary manager
+	s.Close()
+	if _, err := ib.SinkManager(m, timeout, 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := <-m.Alerts(); ok {
+		t.Fatal("expected alerts to be closed")
+	}
+}
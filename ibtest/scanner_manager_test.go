@@ -0,0 +1,167 @@
+package ibtest
+
+import (
+	"testing"
+
+	"github.com/gofinance/ib"
+)
+
+const scannerParameters = `<?xml version="1.0" encoding="UTF-8"?>
+<ScanParameterResponse>
+	<InstrumentList varName="instrumentList">
+		<Instrument>
+			<name>US Stocks</name>
+			<type>STK</type>
+			<filters>PRICE,VOLUME</filters>
+		</Instrument>
+	</InstrumentList>
+	<LocationTree varName="locationTree">
+		<Location>
+			<name>US Stocks</name>
+			<instruments>STK</instruments>
+			<locationCode>STK.US</locationCode>
+			<LocationTree varName="locationTree">
+				<Location>
+					<name>Listed/NASDAQ</name>
+					<instruments>STK</instruments>
+					<locationCode>STK.US.MAJOR</locationCode>
+				</Location>
+			</LocationTree>
+		</Location>
+	</LocationTree>
+	<ScanTypeList varName="scanTypeList">
+		<ScanType>
+			<displayName>Top % Gainers</displayName>
+			<scanCode>TOP_PERC_GAIN</scanCode>
+			<instruments>STK,STOCK.NA</instruments>
+		</ScanType>
+	</ScanTypeList>
+	<FilterList varName="filterList">
+		<RangeFilter>
+			<id>PRICE</id>
+			<category>Price</category>
+			<AbstractField type="price"><code>priceAbove</code><displayName>Price above</displayName></AbstractField>
+			<AbstractField type="price"><code>priceBelow</code><displayName>Price below</displayName></AbstractField>
+		</RangeFilter>
+		<SimpleFilter>
+			<id>HALTED</id>
+			<AbstractField type="bool"><code>haltedIs</code></AbstractField>
+		</SimpleFilter>
+	</FilterList>
+</ScanParameterResponse>`
+
+func TestScannerParametersManager(t *testing.T) {
+	gw := newGateway(t)
+	defer gw.Close()
+
+	// errors for other requests, and unrelated errors without a request ID,
+	// are ignored
+	unrelated := &ib.ErrorMessage{Code: 200, Message: "No security definition has been found for the request"}
+	unrelated.SetID(42)
+	connectivity := &ib.ErrorMessage{Code: 1100, Message: "Connectivity between IB and Trader Workstation has been lost."}
+	connectivity.SetID(-1)
+	gw.Reply(&ib.RequestScannerParameters{}, AnyID, unrelated, connectivity, &ib.ScannerParameters{XML: scannerParameters})
+
+	engine := newEngine(t, gw, ib.EngineOptions{})
+	defer engine.Stop()
+
+	m, err := ib.NewScannerParametersManager(engine)
+	if err != nil {
+		t.Fatalf("error creating manager: %s", err)
+	}
+	if _, err := ib.SinkManager(m, timeout, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	p := m.Parameters()
+	if len(p.Instruments) != 1 || len(p.ScanTypes) != 1 || len(p.RangeFilters) != 1 || len(p.SimpleFilters) != 1 {
+		t.Fatalf("unexpected parameters %+v", p)
+	}
+	if l, ok := p.Location("STK.US.MAJOR"); !ok || l.Name != "Listed/NASDAQ" {
+		t.Fatalf("nested location not found: %+v", l)
+	}
+
+	sub := ib.ScannerSubscription{Instrument: "STK", LocationCode: "STK.US.MAJOR", ScanCode: "TOP_PERC_GAIN"}
+	if err := p.Validate(sub, []ib.TagValue{{Tag: "priceAbove", Value: "5"}, {Tag: "haltedIs", Value: "false"}}); err != nil {
+		t.Fatal(err)
+	}
+	bad := []ib.ScannerSubscription{
+		{Instrument: "FUT", LocationCode: "STK.US.MAJOR", ScanCode: "TOP_PERC_GAIN"},
+		{Instrument: "STK", LocationCode: "STK.EU", ScanCode: "TOP_PERC_GAIN"},
+		{Instrument: "STK", LocationCode: "STK.US", ScanCode: "HOT_BY_VOLUME"},
+	}
+	for _, s := range bad {
+		if err := p.Validate(s, nil); err == nil {
+			t.Fatalf("expected %+v to be invalid", s)
+		}
+	}
+	if err := p.Validate(sub, []ib.TagValue{{Tag: "volumeAbove", Value: "1"}}); err == nil {
+		t.Fatal("expected unknown filter to be invalid")
+	}
+}
+
+func TestScannerParametersManagerError(t *testing.T) {
+	gw := newGateway(t)
+	defer gw.Close()
+
+	e := &ib.ErrorMessage{Code: 162, Message: "Historical Market Data Service error message:Scanner parameters unavailable"}
+	e.SetID(-1)
+	gw.Reply(&ib.RequestScannerParameters{}, AnyID, e)
+
+	engine := newEngine(t, gw, ib.EngineOptions{})
+	defer engine.Stop()
+
+	m, err := ib.NewScannerParametersManager(engine)
+	if err != nil {
+		t.Fatalf("error creating manager: %s", err)
+	}
+	if _, err := ib.SinkManager(m, timeout, 1); err == nil {
+		t.Fatal("expected a fatal error")
+	}
+}
+
+func TestScannerManager(t *testing.T) {
+	gw := newGateway(t)
+	defer gw.Close()
+
+	row := func(rank int64, id int64) ib.ScannerDetail {
+		return ib.ScannerDetail{Rank: rank, ContractID: id}
+	}
+	gw.Reply(&ib.RequestScannerSubscription{}, AnyID,
+		&ib.ScannerData{Detail: []ib.ScannerDetail{row(0, 1), row(1, 2), row(2, 3)}},
+		&ib.ScannerData{Detail: []ib.ScannerDetail{row(0, 3), row(1, 1), row(2, 4)}},
+	)
+
+	engine := newEngine(t, gw, ib.EngineOptions{})
+	defer engine.Stop()
+
+	sub := ib.ScannerSubscription{NumberOfRows: 3, Instrument: "STK", LocationCode: "STK.US.MAJOR", ScanCode: "TOP_PERC_GAIN"}
+	m, err := ib.NewScannerManager(engine, sub, nil)
+	if err != nil {
+		t.Fatalf("error creating manager: %s", err)
+	}
+	if _, err := ib.SinkManager(m, timeout, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	if rows := m.Rows(); len(rows) != 3 || rows[0].ContractID != 3 {
+		t.Fatalf("unexpected rows %+v", rows)
+	}
+	if a := m.Added(); len(a) != 1 || a[0].ContractID != 4 {
+		t.Fatalf("unexpected added rows %+v", a)
+	}
+	if r := m.Removed(); len(r) != 1 || r[0].ContractID != 2 {
+		t.Fatalf("unexpected removed rows %+v", r)
+	}
+
+	req, err := gw.Await(&ib.RequestScannerSubscription{}, AnyID, timeout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := req.(*ib.RequestScannerSubscription).Subscription; s.ScanCode != sub.ScanCode {
+		t.Fatalf("unexpected subscription %+v", s)
+	}
+	if _, err := gw.Await(&ib.CancelScannerSubscription{}, req.(ib.MatchedRequest).ID(), timeout); err != nil {
+		t.Fatal(err)
+	}
+}
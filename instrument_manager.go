@@ -1,17 +1,102 @@
 package ib
 
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Quote is a snapshot of the market data of an instrument. Values which have
+// not been received are zero.
+type Quote struct {
+	Bid           float64
+	BidSize       int64
+	Ask           float64
+	AskSize       int64
+	Last          float64
+	LastSize      int64
+	LastTimestamp time.Time
+	High          float64
+	Low           float64
+	Open          float64
+	Close         float64
+	Volume        int64
+	AverageVolume int64
+	OpenInterest  int64
+	MarkPrice     float64
+	RTVolume      RTVolume
+	Halted        bool
+	Shortable     float64 // > 2.5 shortable, > 1.5 hard to borrow
+	Low13Week     float64
+	High13Week    float64
+	Low26Week     float64
+	High26Week    float64
+	Low52Week     float64
+	High52Week    float64
+	ImpliedVol    float64
+	HistoricalVol float64
+	BidOption     TickOptionComputation
+	AskOption     TickOptionComputation
+	LastOption    TickOptionComputation
+	ModelOption   TickOptionComputation
+	EFP           TickEFP // most recent EFP computation
+}
+
+// RTVolume is the last trade as reported by the RT Volume generic tick (233).
+type RTVolume struct {
+	Price       float64
+	Size        int64
+	Time        time.Time
+	TotalVolume int64
+	VWAP        float64
+	SingleTrade bool
+}
+
+// parseRTVolume parses "price;size;epochMillis;totalVolume;vwap;singleTrade".
+// Price and size are empty for volume-only updates.
+func parseRTVolume(s string) (v RTVolume, err error) {
+	f := strings.Split(s, ";")
+	if len(f) != 6 {
+		return v, fmt.Errorf("goib: malformed RT volume %q", s)
+	}
+	if f[0] != "" {
+		if v.Price, err = strconv.ParseFloat(f[0], 64); err != nil {
+			return v, err
+		}
+	}
+	if f[1] != "" {
+		if v.Size, err = strconv.ParseInt(f[1], 10, 64); err != nil {
+			return v, err
+		}
+	}
+	ms, err := strconv.ParseInt(f[2], 10, 64)
+	if err != nil {
+		return v, err
+	}
+	v.Time = time.Unix(ms/1000, (ms%1000)*int64(time.Millisecond))
+	if v.TotalVolume, err = strconv.ParseInt(f[3], 10, 64); err != nil {
+		return v, err
+	}
+	if v.VWAP, err = strconv.ParseFloat(f[4], 64); err != nil {
+		return v, err
+	}
+	v.SingleTrade, err = strconv.ParseBool(f[5])
+	return v, err
+}
+
 // InstrumentManager .
 type InstrumentManager struct {
 	AbstractManager
-	id   int64
-	c    Contract
-	last float64
-	bid  float64
-	ask  float64
+	id    int64
+	c     Contract
+	ticks string
+	quote Quote
 }
 
-// NewInstrumentManager .
-func NewInstrumentManager(e *Engine, c Contract) (*InstrumentManager, error) {
+// NewInstrumentManager requests market data for the contract, including any
+// given generic ticks (eg "233" for RT volume, "236" for shortable).
+func NewInstrumentManager(e *Engine, c Contract, genericTicks ...string) (*InstrumentManager, error) {
 	am, err := NewAbstractManager(e)
 	if err != nil {
 		return nil, err
@@ -19,7 +104,9 @@ func NewInstrumentManager(e *Engine, c Contract) (*InstrumentManager, error) {
 
 	m := &InstrumentManager{
 		AbstractManager: *am,
+		id:              e.NextRequestID(),
 		c:               c,
+		ticks:           strings.Join(genericTicks, ","),
 	}
 
 	go m.startMainLoop(m.preLoop, m.receive, m.preDestroy)
@@ -27,8 +114,7 @@ func NewInstrumentManager(e *Engine, c Contract) (*InstrumentManager, error) {
 }
 
 func (i *InstrumentManager) preLoop() error {
-	i.id = i.eng.NextRequestID()
-	req := &RequestMarketData{Contract: i.c}
+	req := &RequestMarketData{Contract: i.c, GenericTickList: i.ticks}
 	req.SetID(i.id)
 	i.eng.Subscribe(i.rc, i.id)
 	return i.eng.Send(req)
@@ -42,6 +128,7 @@ func (i *InstrumentManager) preDestroy() {
 }
 
 func (i *InstrumentManager) receive(r Reply) (UpdateStatus, error) {
+	q := &i.quote
 	switch r.(type) {
 	case *ErrorMessage:
 		r := r.(*ErrorMessage)
@@ -51,39 +138,134 @@ func (i *InstrumentManager) receive(r Reply) (UpdateStatus, error) {
 		return UpdateFalse, r.Error()
 	case *TickPrice:
 		r := r.(*TickPrice)
+		// bid, ask and last prices carry their size, which IB may or may not
+		// also send as a separate TickSize
 		switch r.Type {
 		case TickLast:
-			i.last = r.Price
+			q.Last = r.Price
+			if r.Size != 0 {
+				q.LastSize = r.Size
+			}
 		case TickBid:
-			i.bid = r.Price
+			q.Bid = r.Price
+			if r.Size != 0 {
+				q.BidSize = r.Size
+			}
 		case TickAsk:
-			i.ask = r.Price
+			q.Ask = r.Price
+			if r.Size != 0 {
+				q.AskSize = r.Size
+			}
+		case TickHigh:
+			q.High = r.Price
+		case TickLow:
+			q.Low = r.Price
+		case TickOpen:
+			q.Open = r.Price
+		case TickClose:
+			q.Close = r.Price
+		case TickMarkPrice:
+			q.MarkPrice = r.Price
+		case TickLow13Week:
+			q.Low13Week = r.Price
+		case TickHigh13Week:
+			q.High13Week = r.Price
+		case TickLow26Week:
+			q.Low26Week = r.Price
+		case TickHigh26Week:
+			q.High26Week = r.Price
+		case TickLow52Week:
+			q.Low52Week = r.Price
+		case TickHigh52Week:
+			q.High52Week = r.Price
+		}
+	case *TickSize:
+		r := r.(*TickSize)
+		switch TickType(r.Type) {
+		case TickBidSize:
+			q.BidSize = r.Size
+		case TickAskSize:
+			q.AskSize = r.Size
+		case TickLastSize:
+			q.LastSize = r.Size
+		case TickVolume:
+			q.Volume = r.Size
+		case TickAverageVolume:
+			q.AverageVolume = r.Size
+		case TickOpenInterest:
+			q.OpenInterest = r.Size
+		}
+	case *TickGeneric:
+		r := r.(*TickGeneric)
+		switch r.Type {
+		case TickHalted:
+			q.Halted = r.Value > 0
+		case TickShortable:
+			q.Shortable = r.Value
+		case TickOptionImpliedVol:
+			q.ImpliedVol = r.Value
+		case TickOptionHistoricalVol:
+			q.HistoricalVol = r.Value
+		}
+	case *TickString:
+		r := r.(*TickString)
+		switch r.Type {
+		case TickLastTimestamp:
+			if secs, err := strconv.ParseInt(r.Value, 10, 64); err == nil {
+				q.LastTimestamp = time.Unix(secs, 0)
+			}
+		case TickRTVolume:
+			// a malformed RT volume is not worth failing the manager over
+			if v, err := parseRTVolume(r.Value); err == nil {
+				q.RTVolume = v
+			}
 		}
+	case *TickOptionComputation:
+		r := r.(*TickOptionComputation)
+		switch r.Type {
+		case TickBidOptionComputation:
+			q.BidOption = *r
+		case TickAskOptionComputation:
+			q.AskOption = *r
+		case TickLastOptionComputation:
+			q.LastOption = *r
+		case TickModelOption:
+			q.ModelOption = *r
+		}
+	case *TickEFP:
+		q.EFP = *r.(*TickEFP)
 	}
 
-	if i.last <= 0 && (i.bid <= 0 || i.ask <= 0) {
+	if q.Last <= 0 && (q.Bid <= 0 || q.Ask <= 0) {
 		return UpdateFalse, nil
 	}
 	return UpdateTrue, nil
 }
 
+// Quote returns a consistent snapshot of all market data received.
+func (i *InstrumentManager) Quote() Quote {
+	i.rwm.RLock()
+	defer i.rwm.RUnlock()
+	return i.quote
+}
+
 // Bid .
 func (i *InstrumentManager) Bid() float64 {
 	i.rwm.RLock()
 	defer i.rwm.RUnlock()
-	return i.bid
+	return i.quote.Bid
 }
 
 // Ask .
 func (i *InstrumentManager) Ask() float64 {
 	i.rwm.RLock()
 	defer i.rwm.RUnlock()
-	return i.ask
+	return i.quote.Ask
 }
 
 // Last .
 func (i *InstrumentManager) Last() float64 {
 	i.rwm.RLock()
 	defer i.rwm.RUnlock()
-	return i.last
+	return i.quote.Last
 }
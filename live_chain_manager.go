@@ -0,0 +1,389 @@
+package ib
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ChainFilter selects the options of a chain to stream.
+type ChainFilter struct {
+	MinExpiry     time.Time // zero means no minimum
+	MaxExpiry     time.Time // zero means no maximum
+	Spot          float64   // underlying price, for StrikePercent
+	StrikePercent float64   // keep strikes within this % of Spot; zero keeps all
+	Right         string    // "C", "P" or "" for both
+}
+
+func (f ChainFilter) expiry(t time.Time) bool {
+	return (f.MinExpiry.IsZero() || !t.Before(f.MinExpiry)) && (f.MaxExpiry.IsZero() || !t.After(f.MaxExpiry))
+}
+
+func (f ChainFilter) strike(k float64) bool {
+	return f.StrikePercent == 0 || math.Abs(k-f.Spot) <= f.Spot*f.StrikePercent/100
+}
+
+func (f ChainFilter) right(r string) bool {
+	return f.Right == "" || f.Right == r
+}
+
+// LiveChainOptions configures how a LiveChainManager uses market data lines.
+type LiveChainOptions struct {
+	Lines    int           // concurrent market data subscriptions (default 100)
+	Rotation time.Duration // how long each batch is streamed when rotating (default 10s)
+}
+
+func (o LiveChainOptions) withDefaults() LiveChainOptions {
+	if o.Lines <= 0 {
+		o.Lines = 100
+	}
+	if o.Rotation <= 0 {
+		o.Rotation = 10 * time.Second
+	}
+	return o
+}
+
+// LiveLeg is the latest market data of one option of a chain. The Greeks are
+// those of IB's model option computation.
+type LiveLeg struct {
+	Expiry    time.Time
+	Strike    float64
+	Right     string
+	Contract  Contract
+	Bid       float64
+	Ask       float64
+	Last      float64
+	BidSize   int64
+	AskSize   int64
+	LastSize  int64
+	Greeks    Greeks
+	Updated   time.Time // zero until the first tick
+	Streaming bool      // false while rotated out, when values may be stale
+	Err       error     // the last error IB reported for the leg
+}
+
+// SmilePoint is the implied volatility at a strike. A missing leg or one
+// without a model computation is GreekNotComputed.
+type SmilePoint struct {
+	Strike float64
+	CallIV float64
+	PutIV  float64
+}
+
+type chainLeg struct {
+	id int64
+	LiveLeg
+}
+
+// LiveChainManager streams market data for the options of chains (eg from a
+// ChainManager) selected by a ChainFilter. When more options are selected than
+// there are market data lines, the subscriptions are rotated through batches,
+// and the values of options rotated out are kept until streamed again.
+type LiveChainManager struct {
+	AbstractManager
+	opt    LiveChainOptions
+	legs   []*chainLeg
+	ids    map[int64]*chainLeg
+	rotm   sync.Mutex // serialises subscription changes
+	active []*chainLeg
+	cursor int
+	closed bool
+}
+
+// NewLiveChainManager .
+func NewLiveChainManager(e *Engine, chains OptionChains, f ChainFilter, o LiveChainOptions) (*LiveChainManager, error) {
+	am, err := NewAbstractManager(e)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &LiveChainManager{
+		AbstractManager: *am,
+		opt:             o.withDefaults(),
+		ids:             map[int64]*chainLeg{},
+	}
+	for expiry, chain := range chains {
+		if !f.expiry(expiry) {
+			continue
+		}
+		for _, strike := range chain.Strikes {
+			if !f.strike(strike.Price) {
+				continue
+			}
+			for right, data := range map[string]*ContractData{"C": strike.Call, "P": strike.Put} {
+				if data == nil || !f.right(right) {
+					continue
+				}
+				m.legs = append(m.legs, &chainLeg{
+					id: e.NextRequestID(),
+					LiveLeg: LiveLeg{
+						Expiry:   expiry,
+						Strike:   strike.Price,
+						Right:    right,
+						Contract: data.Contract.Summary,
+						Greeks:   notComputedGreeks,
+					},
+				})
+			}
+		}
+	}
+	if len(m.legs) == 0 {
+		return nil, errors.New("goib: no options match the chain filter")
+	}
+	sort.Slice(m.legs, func(i, j int) bool { return legLess(&m.legs[i].LiveLeg, &m.legs[j].LiveLeg) })
+	for _, l := range m.legs {
+		m.ids[l.id] = l
+	}
+
+	go m.startMainLoop(m.preLoop, m.receive, m.preDestroy)
+	if len(m.legs) > m.opt.Lines {
+		go m.rotate()
+	}
+	return m, nil
+}
+
+var notComputedGreeks = Greeks{
+	Delta:      GreekNotComputed,
+	Gamma:      GreekNotComputed,
+	Vega:       GreekNotComputed,
+	Theta:      GreekNotComputed,
+	IV:         GreekNotComputed,
+	OptPrice:   GreekNotComputed,
+	PvDividend: GreekNotComputed,
+	UndPrice:   GreekNotComputed,
+}
+
+func legLess(a *LiveLeg, b *LiveLeg) bool {
+	if !a.Expiry.Equal(b.Expiry) {
+		return a.Expiry.Before(b.Expiry)
+	}
+	if a.Strike != b.Strike {
+		return a.Strike < b.Strike
+	}
+	return a.Right < b.Right
+}
+
+func (m *LiveChainManager) preLoop() error {
+	for _, l := range m.legs {
+		m.eng.Subscribe(m.rc, l.id)
+	}
+
+	m.rotm.Lock()
+	defer m.rotm.Unlock()
+	if m.active == nil {
+		m.activate(m.nextBatch())
+	}
+	// (re)send every active subscription, as a reconnect loses them all
+	for _, l := range m.active {
+		if err := m.eng.Send(l.request()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *LiveChainManager) preDestroy() {
+	for _, l := range m.legs {
+		m.eng.Unsubscribe(m.rc, l.id)
+	}
+
+	m.rotm.Lock()
+	defer m.rotm.Unlock()
+	m.closed = true
+	for _, l := range m.active {
+		m.eng.Send(l.cancel())
+	}
+}
+
+// rotate moves the subscriptions on to the next batch of legs every rotation
+// period, until the manager exits.
+func (m *LiveChainManager) rotate() {
+	ticker := time.NewTicker(m.opt.Rotation)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-m.term:
+			return
+		case <-ticker.C:
+		}
+
+		m.rotm.Lock()
+		if m.closed {
+			m.rotm.Unlock()
+			return
+		}
+		old := m.active
+		m.activate(m.nextBatch())
+		streaming := map[*chainLeg]bool{}
+		for _, l := range m.active {
+			streaming[l] = true
+		}
+		for _, l := range old {
+			if !streaming[l] {
+				m.eng.Send(l.cancel())
+			}
+			delete(streaming, l)
+		}
+		for _, l := range m.active {
+			if streaming[l] {
+				m.eng.Send(l.request())
+			}
+		}
+		m.rotm.Unlock()
+	}
+}
+
+// nextBatch returns the next legs to stream. Callers must hold rotm.
+func (m *LiveChainManager) nextBatch() []*chainLeg {
+	if len(m.legs) <= m.opt.Lines {
+		return m.legs
+	}
+	batch := make([]*chainLeg, 0, m.opt.Lines)
+	for i := 0; i < m.opt.Lines; i++ {
+		batch = append(batch, m.legs[(m.cursor+i)%len(m.legs)])
+	}
+	m.cursor = (m.cursor + m.opt.Lines) % len(m.legs)
+	return batch
+}
+
+// activate makes the batch the active legs. Callers must hold rotm.
+func (m *LiveChainManager) activate(batch []*chainLeg) {
+	m.rwm.Lock()
+	defer m.rwm.Unlock()
+	for _, l := range m.active {
+		l.Streaming = false
+	}
+	for _, l := range batch {
+		l.Streaming = true
+	}
+	m.active = batch
+}
+
+func (l *chainLeg) request() *RequestMarketData {
+	req := &RequestMarketData{Contract: l.Contract}
+	req.SetID(l.id)
+	return req
+}
+
+func (l *chainLeg) cancel() *CancelMarketData {
+	req := &CancelMarketData{}
+	req.SetID(l.id)
+	return req
+}
+
+func (m *LiveChainManager) receive(r Reply) (UpdateStatus, error) {
+	mr, ok := r.(MatchedReply)
+	if !ok {
+		return UpdateFalse, nil
+	}
+	l, ok := m.ids[mr.ID()]
+	if !ok {
+		return UpdateFalse, nil
+	}
+	switch r.(type) {
+	case *ErrorMessage:
+		r := r.(*ErrorMessage)
+		if r.SeverityWarning() {
+			return UpdateFalse, nil
+		}
+		// one bad leg should not stop the rest of the chain streaming
+		l.Err = r.Error()
+		return UpdateTrue, nil
+	case *TickPrice:
+		r := r.(*TickPrice)
+		switch r.Type {
+		case TickBid:
+			l.Bid = r.Price
+		case TickAsk:
+			l.Ask = r.Price
+		case TickLast:
+			l.Last = r.Price
+		default:
+			return UpdateFalse, nil
+		}
+	case *TickSize:
+		r := r.(*TickSize)
+		switch TickType(r.Type) {
+		case TickBidSize:
+			l.BidSize = r.Size
+		case TickAskSize:
+			l.AskSize = r.Size
+		case TickLastSize:
+			l.LastSize = r.Size
+		default:
+			return UpdateFalse, nil
+		}
+	case *TickOptionComputation:
+		r := r.(*TickOptionComputation)
+		if r.Type != TickModelOption {
+			return UpdateFalse, nil
+		}
+		l.Greeks = r.Greeks()
+	default:
+		return UpdateFalse, nil
+	}
+	l.Updated = time.Now()
+	return UpdateTrue, nil
+}
+
+// Legs returns all selected options, ordered by expiry, strike and right.
+func (m *LiveChainManager) Legs() []LiveLeg {
+	m.rwm.RLock()
+	defer m.rwm.RUnlock()
+	legs := make([]LiveLeg, len(m.legs))
+	for i, l := range m.legs {
+		legs[i] = l.LiveLeg
+	}
+	return legs
+}
+
+// Leg returns the option with the given expiry, strike and right.
+func (m *LiveChainManager) Leg(expiry time.Time, strike float64, right string) (LiveLeg, bool) {
+	m.rwm.RLock()
+	defer m.rwm.RUnlock()
+	for _, l := range m.legs {
+		if l.Expiry.Equal(expiry) && l.Strike == strike && l.Right == right {
+			return l.LiveLeg, true
+		}
+	}
+	return LiveLeg{}, false
+}
+
+// Expiries returns the selected expiries, in order.
+func (m *LiveChainManager) Expiries() []time.Time {
+	m.rwm.RLock()
+	defer m.rwm.RUnlock()
+	var expiries []time.Time
+	for _, l := range m.legs {
+		if n := len(expiries); n == 0 || !expiries[n-1].Equal(l.Expiry) {
+			expiries = append(expiries, l.Expiry)
+		}
+	}
+	return expiries
+}
+
+// Smile returns the implied volatility by strike for the expiry, in strike
+// order. Strikes with no implied volatility for either right are left out.
+func (m *LiveChainManager) Smile(expiry time.Time) []SmilePoint {
+	m.rwm.RLock()
+	defer m.rwm.RUnlock()
+	var smile []SmilePoint
+	for _, l := range m.legs {
+		if !l.Expiry.Equal(expiry) || l.Greeks.IV == GreekNotComputed {
+			continue
+		}
+		n := len(smile)
+		if n == 0 || smile[n-1].Strike != l.Strike {
+			smile = append(smile, SmilePoint{Strike: l.Strike, CallIV: GreekNotComputed, PutIV: GreekNotComputed})
+			n++
+		}
+		if l.Right == "C" {
+			smile[n-1].CallIV = l.Greeks.IV
+		} else {
+			smile[n-1].PutIV = l.Greeks.IV
+		}
+	}
+	return smile
+}
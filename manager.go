@@ -53,7 +53,9 @@ const (
 // Managers will register for Engine state changes. If the Engine exits, the
 // Manager will close. If Engine.FatalError() returns an error, it will be made
 // available via manager.FatalError() (unless an earlier error was recorded).
-// This means clients need not track Manager errors or states themselves.
+// This means clients need not track Manager errors or states themselves. If
+// the Engine reconnects to the gateway, the Manager remains open and repeats
+// its IB API requests on the new connection.
 //
 // Every Manager defines a Close() method which blocks until the Manager has
 // released its resources. The Close() method will not return any new error or
@@ -97,42 +99,51 @@ func NewAbstractManager(e *Engine) (*AbstractManager, error) {
 }
 
 func (a *AbstractManager) startMainLoop(preLoop func() error, receive func(r Reply) (UpdateStatus, error), preDestroy func()) {
-	errors := make(chan error)
-	preLoopFinished := make(chan bool)
+	preLoopDone := make(chan error)
+	preLoopRunning := false
+
+	runPreLoop := func() {
+		preLoopRunning = true
+		go func() { preLoopDone <- preLoop() }()
+	}
 
 	defer func() {
-		<-preLoopFinished // ensures preLoop goroutine has exited
-		preDestroy()
+		if preLoopRunning {
+			<-preLoopDone // ensures preLoop goroutine has exited
+		}
 		a.eng.UnsubscribeState(a.engs)
+		preDestroy()
 		close(a.update)
 		close(a.term)
 	}()
 
 	go a.eng.SubscribeState(a.engs)
-	go func() {
-		err := preLoop()
-		if err != nil {
-			errors <- err
-		}
-		close(errors)
-		errors = nil
-		preLoopFinished <- true
-	}()
+	runPreLoop()
 
 	for {
 		select {
 		case <-a.exit:
 			return
-		case e, ok := <-errors:
-			if ok {
-				a.err = e
+		case err := <-preLoopDone:
+			preLoopRunning = false
+			if err != nil {
+				a.err = err
 				return
 			}
 		case r := <-a.rc:
 			if a.consume(r, receive) {
 				return
 			}
-		case <-a.engs:
+		case s := <-a.engs:
+			switch s {
+			case EngineReconnecting:
+				continue
+			case EngineReconnected:
+				if !preLoopRunning {
+					runPreLoop()
+				}
+				continue
+			}
 			if a.err == nil {
 				a.err = a.eng.FatalError()
 			}
@@ -0,0 +1,202 @@
+package ib
+
+// Market depth operations and sides, as reported in MarketDepth and MarketDepthL2.
+const (
+	DepthInsert = 0
+	DepthUpdate = 1
+	DepthDelete = 2
+
+	DepthAsk = 0
+	DepthBid = 1
+)
+
+// DepthLevel is one position of an order book side. MarketMaker is only set
+// for Level II (MarketDepthL2) books.
+type DepthLevel struct {
+	Price       float64
+	Size        int64
+	MarketMaker string
+}
+
+// MarketDepthManager maintains the order book of a contract from market depth
+// updates. The book is rebuilt from scratch after a reconnect.
+type MarketDepthManager struct {
+	AbstractManager
+	id   int64
+	c    Contract
+	rows int64
+	bids []DepthLevel
+	asks []DepthLevel
+}
+
+// NewMarketDepthManager requests up to the given number of rows per side.
+func NewMarketDepthManager(e *Engine, c Contract, rows int64) (*MarketDepthManager, error) {
+	am, err := NewAbstractManager(e)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &MarketDepthManager{
+		AbstractManager: *am,
+		id:              e.NextRequestID(),
+		c:               c,
+		rows:            rows,
+	}
+
+	go m.startMainLoop(m.preLoop, m.receive, m.preDestroy)
+	return m, nil
+}
+
+func (m *MarketDepthManager) preLoop() error {
+	m.rwm.Lock()
+	m.bids = nil
+	m.asks = nil
+	m.rwm.Unlock()
+
+	req := &RequestMarketDepth{Contract: m.c, NumRows: m.rows}
+	req.SetID(m.id)
+	m.eng.Subscribe(m.rc, m.id)
+	return m.eng.Send(req)
+}
+
+func (m *MarketDepthManager) preDestroy() {
+	m.eng.Unsubscribe(m.rc, m.id)
+	req := &CancelMarketDepth{}
+	req.SetID(m.id)
+	m.eng.Send(req)
+}
+
+func (m *MarketDepthManager) receive(r Reply) (UpdateStatus, error) {
+	switch r.(type) {
+	case *ErrorMessage:
+		r := r.(*ErrorMessage)
+		if r.ID() != m.id || r.SeverityWarning() {
+			return UpdateFalse, nil
+		}
+		return UpdateFalse, r.Error()
+	case *MarketDepth:
+		r := r.(*MarketDepth)
+		return m.apply(r.Side, r.Operation, r.Position, DepthLevel{Price: r.Price, Size: r.Size}), nil
+	case *MarketDepthL2:
+		r := r.(*MarketDepthL2)
+		return m.apply(r.Side, r.Operation, r.Position, DepthLevel{Price: r.Price, Size: r.Size, MarketMaker: r.MarketMaker}), nil
+	}
+	return UpdateFalse, nil
+}
+
+// apply performs a depth operation on one side of the book.
+func (m *MarketDepthManager) apply(side int64, op int64, pos int64, l DepthLevel) UpdateStatus {
+	book := &m.asks
+	if side == DepthBid {
+		book = &m.bids
+	}
+	levels := *book
+	if pos < 0 {
+		return UpdateFalse
+	}
+
+	// positions beyond the end of the book would leave a gap of levels with
+	// no price, so such operations are ignored
+	switch op {
+	case DepthInsert:
+		if pos > int64(len(levels)) {
+			return UpdateFalse
+		}
+		levels = append(levels, DepthLevel{})
+		copy(levels[pos+1:], levels[pos:])
+		levels[pos] = l
+		if m.rows > 0 && int64(len(levels)) > m.rows {
+			levels = levels[:m.rows]
+		}
+	case DepthUpdate:
+		if pos >= int64(len(levels)) {
+			return UpdateFalse
+		}
+		levels[pos] = l
+	case DepthDelete:
+		if pos >= int64(len(levels)) {
+			return UpdateFalse
+		}
+		levels = append(levels[:pos], levels[pos+1:]...)
+	default:
+		return UpdateFalse
+	}
+
+	*book = levels
+	return UpdateTrue
+}
+
+// Book returns consistent copies of both sides of the book, best prices first.
+func (m *MarketDepthManager) Book() (bids []DepthLevel, asks []DepthLevel) {
+	m.rwm.RLock()
+	defer m.rwm.RUnlock()
+	return append([]DepthLevel(nil), m.bids...), append([]DepthLevel(nil), m.asks...)
+}
+
+// Bids returns a copy of the bid side, highest price first.
+func (m *MarketDepthManager) Bids() []DepthLevel {
+	m.rwm.RLock()
+	defer m.rwm.RUnlock()
+	return append([]DepthLevel(nil), m.bids...)
+}
+
+// Asks returns a copy of the ask side, lowest price first.
+func (m *MarketDepthManager) Asks() []DepthLevel {
+	m.rwm.RLock()
+	defer m.rwm.RUnlock()
+	return append([]DepthLevel(nil), m.asks...)
+}
+
+// BestBid returns the top of the bid side, or false if it is empty.
+func (m *MarketDepthManager) BestBid() (DepthLevel, bool) {
+	m.rwm.RLock()
+	defer m.rwm.RUnlock()
+	if len(m.bids) == 0 {
+		return DepthLevel{}, false
+	}
+	return m.bids[0], true
+}
+
+// BestAsk returns the top of the ask side, or false if it is empty.
+func (m *MarketDepthManager) BestAsk() (DepthLevel, bool) {
+	m.rwm.RLock()
+	defer m.rwm.RUnlock()
+	if len(m.asks) == 0 {
+		return DepthLevel{}, false
+	}
+	return m.asks[0], true
+}
+
+// Spread returns the best ask less the best bid, or false if either side is empty.
+func (m *MarketDepthManager) Spread() (float64, bool) {
+	m.rwm.RLock()
+	defer m.rwm.RUnlock()
+	if len(m.bids) == 0 || len(m.asks) == 0 {
+		return 0, false
+	}
+	return m.asks[0].Price - m.bids[0].Price, true
+}
+
+// BidDepth returns the total size of the best n bid levels (all if n <= 0).
+func (m *MarketDepthManager) BidDepth(n int) int64 {
+	m.rwm.RLock()
+	defer m.rwm.RUnlock()
+	return cumulativeSize(m.bids, n)
+}
+
+// AskDepth returns the total size of the best n ask levels (all if n <= 0).
+func (m *MarketDepthManager) AskDepth(n int) int64 {
+	m.rwm.RLock()
+	defer m.rwm.RUnlock()
+	return cumulativeSize(m.asks, n)
+}
+
+func cumulativeSize(levels []DepthLevel, n int) (size int64) {
+	if n <= 0 || n > len(levels) {
+		n = len(levels)
+	}
+	for _, l := range levels[:n] {
+		size += l.Size
+	}
+	return size
+}
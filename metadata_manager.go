@@ -46,7 +46,15 @@ func NewMetadataManager(e *Engine, c Contract) (*MetadataManager, error) {
 }
 
 func (m *MetadataManager) preLoop() error {
-	return m.request()
+	if m.id == 0 {
+		return m.request()
+	}
+
+	// repeat the current attempt after an engine reconnection
+	m.rwm.Lock()
+	m.metadata = []ContractData{}
+	m.rwm.Unlock()
+	return m.send()
 }
 
 func (m *MetadataManager) request() error {
@@ -67,6 +75,10 @@ func (m *MetadataManager) request() error {
 
 	m.eng.Unsubscribe(m.rc, m.id) // AbstractMgr goroutine already rx reply
 	m.id = m.eng.NextRequestID()
+	return m.send()
+}
+
+func (m *MetadataManager) send() error {
 	req := &RequestContractData{
 		Contract: m.c,
 	}
@@ -0,0 +1,162 @@
+package ib
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// GreekNotComputed is IB's sentinel for a Greeks value which was not computed.
+const GreekNotComputed = math.MaxFloat64
+
+// optionCalcTimeout is how long to wait for a calculation result.
+var optionCalcTimeout = 10 * time.Second
+
+// Greeks are the option values of a TickOptionComputation. Values which were
+// not computed are GreekNotComputed.
+type Greeks struct {
+	Delta      float64
+	Gamma      float64
+	Vega       float64
+	Theta      float64
+	IV         float64
+	OptPrice   float64
+	PvDividend float64
+	UndPrice   float64
+}
+
+// Greeks converts values outside the range IB computes to GreekNotComputed,
+// as IB's own client does: delta, gamma, vega and theta beyond +/-1, and
+// negative volatilities and prices.
+func (t *TickOptionComputation) Greeks() Greeks {
+	nc := func(v float64, notComputed bool) float64 {
+		if notComputed {
+			return GreekNotComputed
+		}
+		return v
+	}
+	return Greeks{
+		Delta:      nc(t.Delta, math.Abs(t.Delta) > 1),
+		Gamma:      nc(t.Gamma, math.Abs(t.Gamma) > 1),
+		Vega:       nc(t.Vega, math.Abs(t.Vega) > 1),
+		Theta:      nc(t.Theta, math.Abs(t.Theta) > 1),
+		IV:         nc(t.ImpliedVol, t.ImpliedVol < 0),
+		OptPrice:   nc(t.OptionPrice, t.OptionPrice < 0),
+		PvDividend: nc(t.PvDividend, t.PvDividend < 0),
+		UndPrice:   nc(t.SpotPrice, t.SpotPrice < 0),
+	}
+}
+
+// OptionCalcManager asks IB to calculate implied volatilities and option
+// prices for an option contract. Its calculation methods block until IB
+// replies, and may be called concurrently. Each result is also made available
+// via Greeks() and signalled on the refresh channel, which clients must
+// consume as with any other Manager.
+type OptionCalcManager struct {
+	AbstractManager
+	c      Contract
+	greeks Greeks
+}
+
+// NewOptionCalcManager .
+func NewOptionCalcManager(e *Engine, c Contract) (*OptionCalcManager, error) {
+	am, err := NewAbstractManager(e)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &OptionCalcManager{AbstractManager: *am, c: c}
+
+	go m.startMainLoop(m.preLoop, m.receive, m.preDestroy)
+	return m, nil
+}
+
+// each calculation subscribes its own channel, so there is nothing to request
+func (m *OptionCalcManager) preLoop() error { return nil }
+
+// receive records a result forwarded by calc.
+func (m *OptionCalcManager) receive(r Reply) (UpdateStatus, error) {
+	t, ok := r.(*TickOptionComputation)
+	if !ok {
+		return UpdateFalse, nil
+	}
+	m.greeks = t.Greeks()
+	return UpdateTrue, nil
+}
+
+func (m *OptionCalcManager) preDestroy() {}
+
+// Greeks returns the result of the most recent calculation.
+func (m *OptionCalcManager) Greeks() Greeks {
+	m.rwm.RLock()
+	defer m.rwm.RUnlock()
+	return m.greeks
+}
+
+// ImpliedVol calculates the implied volatility (and Greeks) of the option at
+// the given option and underlying prices.
+func (m *OptionCalcManager) ImpliedVol(optionPrice float64, underPrice float64) (Greeks, error) {
+	req := &RequestCalcImpliedVol{Contract: m.c, OptionPrice: optionPrice, UnderPrice: underPrice}
+	req.SetID(m.eng.NextRequestID())
+	cancel := &CancelCalcImpliedVol{}
+	cancel.SetID(req.ID())
+	return m.calc(req, cancel)
+}
+
+// Price calculates the price (and Greeks) of the option at the given
+// volatility and underlying price.
+func (m *OptionCalcManager) Price(vol float64, underPrice float64) (Greeks, error) {
+	req := &RequestCalcOptionPrice{Contract: m.c, Volatility: vol, UnderPrice: underPrice}
+	req.SetID(m.eng.NextRequestID())
+	cancel := &CancelCalcOptionPrice{}
+	cancel.SetID(req.ID())
+	return m.calc(req, cancel)
+}
+
+// calc sends the request and awaits its TickOptionComputation, then sends the
+// cancel request, as IB keeps recalculating until cancelled. The result is handed to the main loop
+// without waiting, so callers need not consume the refresh channel before
+// their next calculation.
+func (m *OptionCalcManager) calc(req MatchedRequest, cancel MatchedRequest) (Greeks, error) {
+	rc := make(chan Reply)
+	m.eng.Subscribe(rc, req.ID())
+	defer m.eng.Unsubscribe(rc, req.ID())
+
+	if err := m.eng.Send(req); err != nil {
+		return Greeks{}, err
+	}
+	// IB has already ended a calculation it reported an error for
+	failed := false
+	defer func() {
+		if !failed {
+			m.eng.Send(cancel)
+		}
+	}()
+
+	timeout := time.After(optionCalcTimeout)
+	for {
+		select {
+		case r := <-rc:
+			switch r := r.(type) {
+			case *ErrorMessage:
+				if r.ID() == req.ID() && !r.SeverityWarning() {
+					failed = true
+					return Greeks{}, r.Error()
+				}
+			case *TickOptionComputation:
+				go func() {
+					select {
+					case m.rc <- r:
+					case <-m.term:
+					}
+				}()
+				return r.Greeks(), nil
+			}
+		case <-timeout:
+			return Greeks{}, fmt.Errorf("goib: no option calculation within %s", optionCalcTimeout)
+		case <-m.term:
+			return Greeks{}, errors.New("goib: option calculation manager closed")
+		}
+	}
+}
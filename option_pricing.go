@@ -0,0 +1,295 @@
+package ib
+
+import (
+	"errors"
+	"math"
+	"time"
+)
+
+// OptionStyle is the exercise style assumed when pricing an option.
+type OptionStyle int
+
+// OptionStyle values.
+const (
+	European OptionStyle = iota // Black-Scholes-Merton
+	American                    // Cox-Ross-Rubinstein binomial tree
+)
+
+// defaultBinomialSteps is used when PricingParams.Steps is zero.
+const defaultBinomialSteps = 200
+
+// PricingParams are the market inputs for pricing options locally. Rate and
+// DividendYield are continuously compounded annual rates.
+//
+// Greeks follow IB's conventions (as in TickOptionComputation): Vega is per
+// volatility point (1%) and Theta is per calendar day.
+type PricingParams struct {
+	UnderPrice    float64
+	Rate          float64
+	DividendYield float64
+	Volatility    float64 // ignored by ImpliedVol
+	Style         OptionStyle
+	Steps         int       // binomial tree steps (American only)
+	Now           time.Time // valuation time; zero means time.Now()
+}
+
+// years returns the time to expiry in years, which is never negative.
+func (p PricingParams) years(expiry time.Time) float64 {
+	now := p.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	t := expiry.Sub(now).Hours() / 24 / 365
+	if t < 0 {
+		return 0
+	}
+	return t
+}
+
+// Price values the option with the given right ("C" or "P"), strike and
+// expiry at p.Volatility.
+func (p PricingParams) Price(right string, strike float64, expiry time.Time) Greeks {
+	o := pricingOption{
+		call:  right == "C",
+		s:     p.UnderPrice,
+		k:     strike,
+		t:     p.years(expiry),
+		r:     p.Rate,
+		q:     p.DividendYield,
+		vol:   p.Volatility,
+		style: p.Style,
+		steps: p.Steps,
+	}
+	return o.greeks()
+}
+
+// ImpliedVol solves for the volatility at which the option is worth price.
+func (p PricingParams) ImpliedVol(price float64, right string, strike float64, expiry time.Time) (float64, error) {
+	o := pricingOption{
+		call:  right == "C",
+		s:     p.UnderPrice,
+		k:     strike,
+		t:     p.years(expiry),
+		r:     p.Rate,
+		q:     p.DividendYield,
+		style: p.Style,
+		steps: p.Steps,
+	}
+	return o.impliedVol(price)
+}
+
+// StrikeValues are the local valuations of a strike's call and put.
+type StrikeValues struct {
+	Call Greeks
+	Put  Greeks
+}
+
+// Value prices the strike's call and put.
+func (o *OptionStrike) Value(p PricingParams) StrikeValues {
+	return StrikeValues{
+		Call: p.Price("C", o.Price, o.expiry),
+		Put:  p.Price("P", o.Price, o.expiry),
+	}
+}
+
+// Value prices every strike of the chain, keyed by strike price.
+func (o *OptionChain) Value(p PricingParams) map[float64]StrikeValues {
+	values := make(map[float64]StrikeValues, len(o.Strikes))
+	for k, strike := range o.Strikes {
+		values[k] = strike.Value(p)
+	}
+	return values
+}
+
+// pricingOption is a fully specified option valuation problem.
+type pricingOption struct {
+	call  bool
+	s, k  float64 // underlying and strike prices
+	t     float64 // years to expiry
+	r, q  float64 // rate and dividend yield
+	vol   float64
+	style OptionStyle
+	steps int
+}
+
+func (o pricingOption) intrinsic(s float64) float64 {
+	if o.call {
+		return math.Max(s-o.k, 0)
+	}
+	return math.Max(o.k-s, 0)
+}
+
+func (o pricingOption) greeks() Greeks {
+	return o.value(true)
+}
+
+// price returns only the option value.
+func (o pricingOption) price() float64 {
+	return o.value(false).OptPrice
+}
+
+// value prices the option, leaving out the binomial vega (which needs two
+// more trees) unless vega is set.
+func (o pricingOption) value(vega bool) Greeks {
+	g := Greeks{
+		IV:         o.vol,
+		UndPrice:   o.s,
+		PvDividend: o.s * (1 - math.Exp(-o.q*o.t)),
+	}
+	if o.t == 0 || o.vol <= 0 {
+		o.deterministic(&g)
+		return g
+	}
+	if o.style == American {
+		o.binomial(&g, vega)
+	} else {
+		o.blackScholes(&g)
+	}
+	return g
+}
+
+// deterministic values the option when the underlying has no volatility (or
+// no time) left, so that it simply grows at the cost of carry. A European
+// option is worth its discounted payoff at expiry, max(S·e^-qT - K·e^-rT, 0)
+// for a call, and an American option the best discounted payoff of exercising
+// at any time until then.
+func (o pricingOption) deterministic(g *Greeks) {
+	payoff := func(t float64) float64 {
+		v := o.s*math.Exp(-o.q*t) - o.k*math.Exp(-o.r*t)
+		if !o.call {
+			v = -v
+		}
+		return v
+	}
+	times := []float64{o.t}
+	if o.style == American {
+		times = append(times, 0)
+		// the discounted payoff is stationary where q·S·e^-qt = r·K·e^-rt
+		if o.r != o.q && o.r*o.q > 0 {
+			if t := math.Log(o.r*o.k/(o.q*o.s)) / (o.r - o.q); t > 0 && t < o.t {
+				times = append(times, t)
+			}
+		}
+	}
+	for _, t := range times {
+		if v := payoff(t); v > g.OptPrice {
+			g.OptPrice = v
+			g.Delta = math.Exp(-o.q * t)
+			if !o.call {
+				g.Delta = -g.Delta
+			}
+		}
+	}
+}
+
+func normPDF(x float64) float64 {
+	return math.Exp(-x*x/2) / math.Sqrt(2*math.Pi)
+}
+
+func normCDF(x float64) float64 {
+	return math.Erfc(-x/math.Sqrt2) / 2
+}
+
+// blackScholes values a European option with the Black-Scholes-Merton formula.
+func (o pricingOption) blackScholes(g *Greeks) {
+	sqrtT := math.Sqrt(o.t)
+	d1 := (math.Log(o.s/o.k) + (o.r-o.q+o.vol*o.vol/2)*o.t) / (o.vol * sqrtT)
+	d2 := d1 - o.vol*sqrtT
+	dq := math.Exp(-o.q * o.t)
+	dr := math.Exp(-o.r * o.t)
+
+	g.Gamma = dq * normPDF(d1) / (o.s * o.vol * sqrtT)
+	g.Vega = o.s * dq * normPDF(d1) * sqrtT / 100
+	decay := -o.s * dq * normPDF(d1) * o.vol / (2 * sqrtT)
+	if o.call {
+		g.OptPrice = o.s*dq*normCDF(d1) - o.k*dr*normCDF(d2)
+		g.Delta = dq * normCDF(d1)
+		g.Theta = (decay - o.r*o.k*dr*normCDF(d2) + o.q*o.s*dq*normCDF(d1)) / 365
+	} else {
+		g.OptPrice = o.k*dr*normCDF(-d2) - o.s*dq*normCDF(-d1)
+		g.Delta = -dq * normCDF(-d1)
+		g.Theta = (decay + o.r*o.k*dr*normCDF(-d2) - o.q*o.s*dq*normCDF(-d1)) / 365
+	}
+}
+
+// binomial values an American option with a Cox-Ross-Rubinstein tree. Delta,
+// gamma and theta are read from the first nodes of the tree; vega is found by
+// revaluing at one volatility point either side.
+func (o pricingOption) binomial(g *Greeks, vega bool) {
+	n := o.steps
+	if n <= 0 {
+		n = defaultBinomialSteps
+	}
+	if n < 2 {
+		n = 2
+	}
+
+	dt := o.t / float64(n)
+	u := math.Exp(o.vol * math.Sqrt(dt))
+	d := 1 / u
+	p := (math.Exp((o.r-o.q)*dt) - d) / (u - d)
+	disc := math.Exp(-o.r * dt)
+
+	// values[j] is the value at the node with j up moves
+	values := make([]float64, n+1)
+	for j := 0; j <= n; j++ {
+		values[j] = o.intrinsic(o.s * math.Pow(u, float64(2*j-n)))
+	}
+	var step1, step2 [3]float64
+	for i := n - 1; i >= 0; i-- {
+		for j := 0; j <= i; j++ {
+			hold := disc * (p*values[j+1] + (1-p)*values[j])
+			values[j] = math.Max(hold, o.intrinsic(o.s*math.Pow(u, float64(2*j-i))))
+		}
+		switch i {
+		case 2:
+			copy(step2[:], values[:3])
+		case 1:
+			copy(step1[:], values[:2])
+		}
+	}
+
+	g.OptPrice = values[0]
+	g.Delta = (step1[1] - step1[0]) / (o.s*u - o.s*d)
+	up := (step2[2] - step2[1]) / (o.s*u*u - o.s)
+	down := (step2[1] - step2[0]) / (o.s - o.s*d*d)
+	g.Gamma = (up - down) / ((o.s*u*u - o.s*d*d) / 2)
+	g.Theta = (step2[1] - values[0]) / (2 * dt) / 365
+	if !vega {
+		return
+	}
+
+	bump := 0.01
+	hi, lo := o, o
+	hi.vol += bump
+	lo.vol = math.Max(o.vol-bump, 1e-6)
+	g.Vega = (hi.price() - lo.price()) / ((hi.vol - lo.vol) * 100)
+}
+
+// impliedVol solves for the volatility by bisection, which is robust for both
+// the closed form and the binomial tree.
+func (o pricingOption) impliedVol(price float64) (float64, error) {
+	if o.t == 0 {
+		return 0, errors.New("goib: cannot solve implied volatility of an expired option")
+	}
+	lo, hi := 1e-4, 5.0
+	value := func(vol float64) float64 {
+		o.vol = vol
+		return o.price()
+	}
+	if price < value(lo) || price > value(hi) {
+		return 0, errors.New("goib: option price outside the range of implied volatilities")
+	}
+	for i := 0; i < 100; i++ {
+		mid := (lo + hi) / 2
+		if value(mid) < price {
+			lo = mid
+		} else {
+			hi = mid
+		}
+		if hi-lo < 1e-6 {
+			break
+		}
+	}
+	return (lo + hi) / 2, nil
+}
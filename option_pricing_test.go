@@ -0,0 +1,152 @@
+package ib
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func near(a, b, tolerance float64) bool {
+	return math.Abs(a-b) <= tolerance
+}
+
+func pricingParams(style OptionStyle) PricingParams {
+	return PricingParams{
+		UnderPrice: 100,
+		Rate:       0.05,
+		Volatility: 0.2,
+		Style:      style,
+		Now:        date(2017, time.January, 1),
+	}
+}
+
+var pricingExpiry = date(2018, time.January, 1) // one year
+
+func TestBlackScholesPrice(t *testing.T) {
+	p := pricingParams(European)
+
+	call := p.Price("C", 100, pricingExpiry)
+	if !near(call.OptPrice, 10.4506, 1e-4) || !near(call.Delta, 0.6368, 1e-4) {
+		t.Fatalf("unexpected call %+v", call)
+	}
+	if !near(call.Gamma, 0.018762, 1e-6) || !near(call.Vega, 0.375240, 1e-6) || !near(call.Theta, -6.4140/365, 1e-6) {
+		t.Fatalf("unexpected call greeks %+v", call)
+	}
+
+	put := p.Price("P", 100, pricingExpiry)
+	if !near(put.OptPrice, 5.5735, 1e-4) || !near(put.Delta, call.Delta-1, 1e-9) {
+		t.Fatalf("unexpected put %+v", put)
+	}
+
+	// put-call parity with a dividend yield
+	p.DividendYield = 0.03
+	call = p.Price("C", 110, pricingExpiry)
+	put = p.Price("P", 110, pricingExpiry)
+	parity := 100*math.Exp(-0.03) - 110*math.Exp(-0.05)
+	if !near(call.OptPrice-put.OptPrice, parity, 1e-9) {
+		t.Fatalf("put-call parity broken: %v != %v", call.OptPrice-put.OptPrice, parity)
+	}
+}
+
+func TestBinomialPrice(t *testing.T) {
+	p := pricingParams(American)
+
+	// an American call without dividends is worth the European call
+	call := p.Price("C", 100, pricingExpiry)
+	if !near(call.OptPrice, 10.4506, 0.02) || !near(call.Delta, 0.6368, 0.01) || !near(call.Vega, 0.3752, 0.01) {
+		t.Fatalf("unexpected call %+v", call)
+	}
+
+	put := p.Price("P", 100, pricingExpiry)
+	if !near(put.OptPrice, 6.0896, 0.02) || put.Delta >= 0 || put.Gamma <= 0 || put.Theta >= 0 {
+		t.Fatalf("unexpected put %+v", put)
+	}
+
+	// deep in the money American puts are exercised
+	deep := p.Price("P", 200, pricingExpiry)
+	if deep.OptPrice != 100 {
+		t.Fatalf("expected early exercise value, got %v", deep.OptPrice)
+	}
+}
+
+func TestZeroVolPrice(t *testing.T) {
+	p := pricingParams(European)
+	p.Volatility = 0
+	p.DividendYield = 0.03
+
+	// the discounted forward payoff, not the undiscounted intrinsic value
+	forward := 100*math.Exp(-0.03) - 100*math.Exp(-0.05)
+	if call := p.Price("C", 100, pricingExpiry); !near(call.OptPrice, forward, 1e-9) || !near(call.Delta, math.Exp(-0.03), 1e-9) {
+		t.Fatalf("unexpected call %+v", call)
+	}
+	if put := p.Price("P", 100, pricingExpiry); put.OptPrice != 0 || put.Delta != 0 {
+		t.Fatalf("unexpected put %+v", put)
+	}
+	if put := p.Price("P", 110, pricingExpiry); !near(put.OptPrice, 110*math.Exp(-0.05)-100*math.Exp(-0.03), 1e-9) {
+		t.Fatalf("unexpected put %+v", put)
+	}
+
+	// an American put this deep is best exercised straight away
+	p.Style = American
+	if put := p.Price("P", 110, pricingExpiry); !near(put.OptPrice, 10, 1e-9) || put.Delta != -1 {
+		t.Fatalf("unexpected American put %+v", put)
+	}
+	if call := p.Price("C", 100, pricingExpiry); !near(call.OptPrice, forward, 1e-9) {
+		t.Fatalf("unexpected American call %+v", call)
+	}
+
+	// at expiry only the intrinsic value remains
+	p.Now = pricingExpiry.Add(time.Hour)
+	if call := p.Price("C", 90, pricingExpiry); call.OptPrice != 10 || call.Delta != 1 {
+		t.Fatalf("unexpected expired call %+v", call)
+	}
+}
+
+func TestImpliedVol(t *testing.T) {
+	for _, style := range []OptionStyle{European, American} {
+		p := pricingParams(style)
+		p.Volatility = 0.35
+		price := p.Price("P", 95, pricingExpiry).OptPrice
+
+		vol, err := p.ImpliedVol(price, "P", 95, pricingExpiry)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !near(vol, 0.35, 1e-4) {
+			t.Fatalf("style %d: expected implied vol 0.35, got %v", style, vol)
+		}
+	}
+
+	p := pricingParams(European)
+	if _, err := p.ImpliedVol(0.01, "C", 50, pricingExpiry); err == nil {
+		t.Fatal("expected error for price below intrinsic value")
+	}
+}
+
+func TestOptionChainValue(t *testing.T) {
+	chain := &OptionChain{Expiry: pricingExpiry, Strikes: map[float64]*OptionStrike{}}
+	for _, k := range []float64{90, 100, 110} {
+		chain.Strikes[k] = &OptionStrike{expiry: pricingExpiry, Price: k}
+	}
+
+	p := pricingParams(European)
+	values := chain.Value(p)
+	if len(values) != 3 {
+		t.Fatalf("expected 3 strikes, got %d", len(values))
+	}
+	if !near(values[100].Call.OptPrice, 10.4506, 1e-4) {
+		t.Fatalf("unexpected ATM call %+v", values[100].Call)
+	}
+	if !(values[90].Call.OptPrice > values[100].Call.OptPrice && values[100].Call.OptPrice > values[110].Call.OptPrice) {
+		t.Fatal("call values should fall as the strike rises")
+	}
+	if v := chain.Strikes[110].Value(p); v != values[110] {
+		t.Fatalf("strike and chain values differ: %+v %+v", v, values[110])
+	}
+
+	// expired options are worth their intrinsic value
+	p.Now = pricingExpiry.Add(time.Hour)
+	if v := chain.Strikes[90].Value(p); v.Call.OptPrice != 10 || v.Put.OptPrice != 0 || v.Call.Delta != 1 {
+		t.Fatalf("unexpected expired values %+v", v)
+	}
+}
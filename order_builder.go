@@ -0,0 +1,272 @@
+package ib
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Order actions.
+const (
+	ActionBuy   = "BUY"
+	ActionSell  = "SELL"
+	ActionShort = "SSHORT"
+)
+
+// Order types created by the order builders.
+const (
+	OrderTypeMarket       = "MKT"
+	OrderTypeLimit        = "LMT"
+	OrderTypeStop         = "STP"
+	OrderTypeStopLimit    = "STP LMT"
+	OrderTypeTrailingStop = "TRAIL"
+)
+
+// OCA types, as set in Order.OCAType.
+const (
+	OCACancelWithBlock    = 1 // cancel the other orders, blocking overfills
+	OCAReduceWithBlock    = 2 // reduce the other orders, blocking overfills
+	OCAReduceWithoutBlock = 3 // reduce the other orders
+)
+
+// priced reports whether the price field has been set (NewOrder leaves prices
+// at math.MaxFloat64).
+func priced(p float64) bool {
+	return p != math.MaxFloat64 && !math.IsNaN(p) && !math.IsInf(p, 0)
+}
+
+// ValidateOrder checks the fields the order builders set: the action, a
+// positive quantity, the prices required by the order type, and the parent
+// and OCA settings.
+func ValidateOrder(o Order) error {
+	return validateOrder(o, false)
+}
+
+// ValidateComboOrder is ValidateOrder for an order on a BAG contract, whose
+// limit and stop prices may be zero or negative (a credit).
+func ValidateComboOrder(o Order) error {
+	return validateOrder(o, true)
+}
+
+func validateOrder(o Order, combo bool) error {
+	switch o.Action {
+	case ActionBuy, ActionSell, ActionShort:
+	default:
+		return fmt.Errorf("goib: invalid order action %q", o.Action)
+	}
+	if o.TotalQty <= 0 {
+		return fmt.Errorf("goib: order quantity must be positive, not %d", o.TotalQty)
+	}
+
+	limit := priced(o.LimitPrice)
+	aux := priced(o.AuxPrice)
+	switch o.OrderType {
+	case OrderTypeMarket:
+		if limit || aux {
+			return errors.New("goib: market order must not have a limit or stop price")
+		}
+	case OrderTypeLimit:
+		// a combo order may instead have a limit price on each leg
+		if !limit && len(o.OrderComboLegs) > 0 {
+			for _, l := range o.OrderComboLegs {
+				if !priced(l.Price) {
+					return errors.New("goib: combo leg limit order requires a price on every leg")
+				}
+			}
+		} else if combo && !limit {
+			return errors.New("goib: limit order requires a limit price")
+		} else if !combo && (!limit || o.LimitPrice <= 0) {
+			return errors.New("goib: limit order requires a positive limit price")
+		}
+	case OrderTypeStop:
+		if combo && !aux {
+			return errors.New("goib: stop order requires a stop price")
+		} else if !combo && (!aux || o.AuxPrice <= 0) {
+			return errors.New("goib: stop order requires a positive stop price")
+		}
+	case OrderTypeStopLimit:
+		if combo && (!limit || !aux) {
+			return errors.New("goib: stop limit order requires stop and limit prices")
+		} else if !combo && (!limit || o.LimitPrice <= 0 || !aux || o.AuxPrice <= 0) {
+			return errors.New("goib: stop limit order requires positive stop and limit prices")
+		}
+	case OrderTypeTrailingStop:
+		percent := priced(o.TrailingPercent)
+		if aux == percent {
+			return errors.New("goib: trailing stop requires either a trailing amount or a trailing percent")
+		}
+		if aux && o.AuxPrice <= 0 {
+			return errors.New("goib: trailing amount must be positive")
+		}
+		if percent && (o.TrailingPercent <= 0 || o.TrailingPercent >= 100) {
+			return fmt.Errorf("goib: trailing percent must be between 0 and 100, not %v", o.TrailingPercent)
+		}
+	}
+
+	if o.ParentID != 0 && o.ParentID == o.OrderID {
+		return fmt.Errorf("goib: order %d cannot be its own parent", o.OrderID)
+	}
+	if o.OCAGroup == "" && o.OCAType != 0 {
+		return errors.New("goib: OCA type requires an OCA group")
+	}
+	if o.OCAGroup != "" && (o.OCAType < OCACancelWithBlock || o.OCAType > OCAReduceWithoutBlock) {
+		return fmt.Errorf("goib: invalid OCA type %d", o.OCAType)
+	}
+	return nil
+}
+
+// newTypedOrder returns a validated order of the type, leaving NewOrder's
+// defaults for all other fields.
+func newTypedOrder(action string, qty int64, orderType string, set func(o *Order)) (Order, error) {
+	return newValidatedOrder(ValidateOrder, action, qty, orderType, set)
+}
+
+func newValidatedOrder(validate func(Order) error, action string, qty int64, orderType string, set func(o *Order)) (Order, error) {
+	o, err := NewOrder()
+	if err != nil {
+		return o, err
+	}
+	o.Action = action
+	o.TotalQty = qty
+	o.OrderType = orderType
+	if set != nil {
+		set(&o)
+	}
+	if err := validate(o); err != nil {
+		return Order{}, err
+	}
+	return o, nil
+}
+
+// MarketOrder .
+func MarketOrder(action string, qty int64) (Order, error) {
+	return newTypedOrder(action, qty, OrderTypeMarket, nil)
+}
+
+// LimitOrder .
+func LimitOrder(action string, qty int64, limit float64) (Order, error) {
+	return newTypedOrder(action, qty, OrderTypeLimit, func(o *Order) { o.LimitPrice = limit })
+}
+
+// StopOrder .
+func StopOrder(action string, qty int64, stop float64) (Order, error) {
+	return newTypedOrder(action, qty, OrderTypeStop, func(o *Order) { o.AuxPrice = stop })
+}
+
+// StopLimit is a limit order at limit, activated once stop is reached.
+func StopLimit(action string, qty int64, stop float64, limit float64) (Order, error) {
+	return newTypedOrder(action, qty, OrderTypeStopLimit, func(o *Order) {
+		o.AuxPrice = stop
+		o.LimitPrice = limit
+	})
+}
+
+// TrailingStop is a stop which trails the market price by amount.
+func TrailingStop(action string, qty int64, amount float64) (Order, error) {
+	return newTypedOrder(action, qty, OrderTypeTrailingStop, func(o *Order) { o.AuxPrice = amount })
+}
+
+// TrailingStopPercent is a stop which trails the market price by percent (eg
+// 2 for 2%).
+func TrailingStopPercent(action string, qty int64, percent float64) (Order, error) {
+	return newTypedOrder(action, qty, OrderTypeTrailingStop, func(o *Order) { o.TrailingPercent = percent })
+}
+
+// opposite returns the action which closes a position opened by action.
+func opposite(action string) string {
+	if action == ActionBuy {
+		return ActionSell
+	}
+	return ActionBuy
+}
+
+// BracketOrder is an entry order with a take profit limit order and a stop
+// loss order attached, which IB cancels as one another fills.
+type BracketOrder struct {
+	Entry      Order
+	TakeProfit Order
+	StopLoss   Order
+}
+
+// Bracket attaches take profit and stop loss exits for the entry's quantity.
+// The take profit must be on the profitable side of the stop loss (and of the
+// entry's limit price, if any).
+func Bracket(entry Order, takeProfit float64, stopLoss float64) (BracketOrder, error) {
+	if err := ValidateOrder(entry); err != nil {
+		return BracketOrder{}, err
+	}
+	if entry.OCAGroup != "" {
+		return BracketOrder{}, errors.New("goib: bracket entry cannot be in an OCA group")
+	}
+
+	long := entry.Action == ActionBuy
+	lower, upper := stopLoss, takeProfit
+	if !long {
+		lower, upper = takeProfit, stopLoss
+	}
+	if lower >= upper {
+		return BracketOrder{}, fmt.Errorf("goib: take profit %v is not on the profitable side of stop loss %v", takeProfit, stopLoss)
+	}
+	if priced(entry.LimitPrice) && (entry.LimitPrice <= lower || entry.LimitPrice >= upper) {
+		return BracketOrder{}, fmt.Errorf("goib: entry limit %v is not between take profit and stop loss", entry.LimitPrice)
+	}
+
+	tp, err := LimitOrder(opposite(entry.Action), entry.TotalQty, takeProfit)
+	if err != nil {
+		return BracketOrder{}, err
+	}
+	sl, err := StopOrder(opposite(entry.Action), entry.TotalQty, stopLoss)
+	if err != nil {
+		return BracketOrder{}, err
+	}
+	return BracketOrder{Entry: entry, TakeProfit: tp, StopLoss: sl}, nil
+}
+
+// PlaceOrders allocates the order IDs and returns the requests to send, in
+// order. The children refer to the entry through ParentID, and only the last
+// request is transmitted, so that IB activates the bracket as a whole.
+func (b BracketOrder) PlaceOrders(e *Engine, c Contract) ([]*PlaceOrder, error) {
+	orders := []Order{b.Entry, b.TakeProfit, b.StopLoss}
+	reqs := make([]*PlaceOrder, len(orders))
+	for i, o := range orders {
+		id, err := e.NextOrderID()
+		if err != nil {
+			return nil, err
+		}
+		o.OrderID = id
+		o.Transmit = i == len(orders)-1
+		if i > 0 {
+			o.ParentID = reqs[0].Order.OrderID
+		}
+		if err := ValidateOrder(o); err != nil {
+			return nil, err
+		}
+		reqs[i] = &PlaceOrder{Contract: c, Order: o}
+		reqs[i].SetID(id)
+	}
+	return reqs, nil
+}
+
+// OCAGroup puts the orders in a one-cancels-all group of the OCA type (eg
+// OCACancelWithBlock).
+func OCAGroup(group string, ocaType int64, orders ...Order) ([]Order, error) {
+	if group == "" {
+		return nil, errors.New("goib: OCA group requires a name")
+	}
+	if len(orders) < 2 {
+		return nil, errors.New("goib: OCA group requires at least two orders")
+	}
+	grouped := make([]Order, len(orders))
+	for i, o := range orders {
+		if o.ParentID != 0 {
+			return nil, errors.New("goib: OCA group orders cannot have a parent")
+		}
+		o.OCAGroup = group
+		o.OCAType = ocaType
+		if err := ValidateOrder(o); err != nil {
+			return nil, err
+		}
+		grouped[i] = o
+	}
+	return grouped, nil
+}
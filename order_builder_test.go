@@ -0,0 +1,85 @@
+package ib
+
+import (
+	"math"
+	"testing"
+)
+
+func TestOrderBuilders(t *testing.T) {
+	if o, err := MarketOrder(ActionBuy, 100); err != nil || o.OrderType != OrderTypeMarket || o.LimitPrice != math.MaxFloat64 {
+		t.Fatalf("unexpected market order %+v (%v)", o, err)
+	}
+	if o, err := StopLimit(ActionSell, 10, 99, 98.5); err != nil || o.AuxPrice != 99 || o.LimitPrice != 98.5 {
+		t.Fatalf("unexpected stop limit order %+v (%v)", o, err)
+	}
+	if o, err := TrailingStop(ActionSell, 10, 1.5); err != nil || o.AuxPrice != 1.5 || o.TrailingPercent != math.MaxFloat64 {
+		t.Fatalf("unexpected trailing stop %+v (%v)", o, err)
+	}
+	if o, err := TrailingStopPercent(ActionSell, 10, 2); err != nil || o.TrailingPercent != 2 || o.AuxPrice != math.MaxFloat64 {
+		t.Fatalf("unexpected trailing stop percent %+v (%v)", o, err)
+	}
+
+	invalid := []func() (Order, error){
+		func() (Order, error) { return MarketOrder("HOLD", 100) },
+		func() (Order, error) { return LimitOrder(ActionBuy, 0, 10) },
+		func() (Order, error) { return LimitOrder(ActionBuy, 100, -1) },
+		func() (Order, error) { return StopOrder(ActionSell, 100, math.MaxFloat64) },
+		func() (Order, error) { return TrailingStopPercent(ActionSell, 100, 150) },
+	}
+	for i, f := range invalid {
+		if _, err := f(); err == nil {
+			t.Errorf("invalid order %d was accepted", i)
+		}
+	}
+}
+
+func TestBracket(t *testing.T) {
+	entry, err := LimitOrder(ActionBuy, 100, 50)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := Bracket(entry, 55, 48)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.TakeProfit.Action != ActionSell || b.TakeProfit.LimitPrice != 55 || b.TakeProfit.TotalQty != 100 {
+		t.Fatalf("unexpected take profit %+v", b.TakeProfit)
+	}
+	if b.StopLoss.Action != ActionSell || b.StopLoss.OrderType != OrderTypeStop || b.StopLoss.AuxPrice != 48 {
+		t.Fatalf("unexpected stop loss %+v", b.StopLoss)
+	}
+
+	if _, err := Bracket(entry, 48, 55); err == nil {
+		t.Fatal("expected error for inverted long bracket")
+	}
+	if _, err := Bracket(entry, 60, 52); err == nil {
+		t.Fatal("expected error for entry outside the bracket")
+	}
+
+	short, _ := MarketOrder(ActionSell, 100)
+	if _, err := Bracket(short, 45, 52); err != nil {
+		t.Fatalf("unexpected error for short bracket: %v", err)
+	}
+}
+
+func TestOCAGroup(t *testing.T) {
+	a, _ := LimitOrder(ActionSell, 100, 55)
+	b, _ := StopOrder(ActionSell, 100, 48)
+
+	orders, err := OCAGroup("exit", OCACancelWithBlock, a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, o := range orders {
+		if o.OCAGroup != "exit" || o.OCAType != OCACancelWithBlock {
+			t.Fatalf("unexpected OCA settings %+v", o)
+		}
+	}
+
+	if _, err := OCAGroup("exit", 4, a, b); err == nil {
+		t.Fatal("expected error for invalid OCA type")
+	}
+	if _, err := OCAGroup("exit", OCACancelWithBlock, a); err == nil {
+		t.Fatal("expected error for a single order group")
+	}
+}
@@ -0,0 +1,271 @@
+package ib
+
+import "errors"
+
+// Order status values, as reported in OrderStatus.Status
+const (
+	OrderPendingSubmit = "PendingSubmit"
+	OrderPendingCancel = "PendingCancel"
+	OrderPreSubmitted  = "PreSubmitted"
+	OrderSubmitted     = "Submitted"
+	OrderAPICancelled  = "ApiCancelled"
+	OrderCancelled     = "Cancelled"
+	OrderFilled        = "Filled"
+	OrderInactive      = "Inactive"
+)
+
+// errorOrderCancelled is the ErrorMessage code confirming an order cancellation.
+const errorOrderCancelled = 202
+
+// OrderManager places a single order and tracks it until it is filled,
+// cancelled or inactive (eg rejected), collecting its executions and
+// commission reports. The manager finishes once the order reaches one of
+// these final states and all fills are known.
+// Closing the manager stops tracking, but does not cancel the order.
+type OrderManager struct {
+	AbstractManager
+	id          int64 // order ID, 0 until allocated
+	execID      int64
+	contract    Contract
+	order       Order
+	status      OrderStatus
+	pending     bool  // RequestExecutions awaiting ExecutionDataEnd
+	requested   int64 // filled shares when executions were last requested
+	fills       []ExecutionData
+	commissions map[string]CommissionReport
+}
+
+// NewOrderManager .
+func NewOrderManager(e *Engine, c Contract, o Order) (*OrderManager, error) {
+	am, err := NewAbstractManager(e)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &OrderManager{
+		AbstractManager: *am,
+		execID:          e.NextRequestID(),
+		contract:        c,
+		order:           o,
+		commissions:     map[string]CommissionReport{},
+	}
+
+	go m.startMainLoop(m.preLoop, m.receive, m.preDestroy)
+	return m, nil
+}
+
+func (m *OrderManager) preLoop() error {
+	m.rwm.RLock()
+	id := m.id
+	m.rwm.RUnlock()
+
+	m.eng.Subscribe(m.rc, m.execID)
+	m.eng.Subscribe(m.rc, UnmatchedReplyID)
+
+	// after a reconnect, only catch up on fills missed while disconnected
+	if id != 0 {
+		m.rwm.Lock()
+		defer m.rwm.Unlock()
+		return m.requestExecutions()
+	}
+
+	id, err := m.eng.NextOrderID()
+	if err != nil {
+		return err
+	}
+	m.rwm.Lock()
+	m.id = id
+	m.status = OrderStatus{id: id, Status: OrderPendingSubmit}
+	m.order.OrderID = id
+	m.rwm.Unlock()
+
+	m.eng.Subscribe(m.rc, id)
+	return m.place()
+}
+
+// place sends the current order. Callers must not hold the lock.
+func (m *OrderManager) place() error {
+	m.rwm.RLock()
+	req := &PlaceOrder{Contract: m.contract, Order: m.order}
+	req.SetID(m.id)
+	m.rwm.RUnlock()
+	return m.eng.Send(req)
+}
+
+// requestExecutions asks for this client's executions. Callers must hold the lock.
+func (m *OrderManager) requestExecutions() error {
+	m.pending = true
+	m.requested = m.status.Filled
+	req := &RequestExecutions{Filter: ExecutionFilter{ClientID: m.eng.ClientID()}}
+	req.SetID(m.execID)
+	return m.eng.Send(req)
+}
+
+func (m *OrderManager) receive(r Reply) (UpdateStatus, error) {
+	switch r.(type) {
+	case *ErrorMessage:
+		r := r.(*ErrorMessage)
+		if r.ID() != m.id && r.ID() != m.execID {
+			return UpdateFalse, nil
+		}
+		if r.SeverityWarning() || r.Code == errorOrderCancelled {
+			return UpdateFalse, nil
+		}
+		if r.Code == errorDuplicateOrderID && r.ID() == m.id {
+			return UpdateFalse, &DuplicateOrderIDError{OrderID: m.id}
+		}
+		return UpdateFalse, r.Error()
+	case *OrderStatus:
+		r := r.(*OrderStatus)
+		m.status = *r
+		if m.status.Filled > m.filled() && !m.pending {
+			if err := m.requestExecutions(); err != nil {
+				return UpdateFalse, err
+			}
+		}
+		return m.update(), nil
+	case *ExecutionData:
+		r := r.(*ExecutionData)
+		if r.Exec.OrderID != m.id {
+			return UpdateFalse, nil
+		}
+		for i, f := range m.fills {
+			if f.Exec.ExecID == r.Exec.ExecID {
+				m.fills[i] = *r
+				return UpdateFalse, nil
+			}
+		}
+		m.fills = append(m.fills, *r)
+		return UpdateFalse, nil
+	case *ExecutionDataEnd:
+		m.pending = false
+		if m.status.Filled > m.requested && m.status.Filled > m.filled() {
+			// more fills were reported while the request was pending
+			if err := m.requestExecutions(); err != nil {
+				return UpdateFalse, err
+			}
+		}
+		return m.update(), nil
+	case *CommissionReport:
+		r := r.(*CommissionReport)
+		m.commissions[r.ExecutionID] = *r
+		for _, f := range m.fills {
+			if f.Exec.ExecID == r.ExecutionID {
+				return UpdateTrue, nil
+			}
+		}
+		return UpdateFalse, nil
+	}
+	return UpdateFalse, nil
+}
+
+// update reports whether the order has reached a final state with all fills known.
+func (m *OrderManager) update() UpdateStatus {
+	if m.pending || m.filled() < m.status.Filled {
+		return UpdateTrue
+	}
+	switch m.status.Status {
+	case OrderFilled, OrderCancelled, OrderAPICancelled, OrderInactive:
+		return UpdateFinish
+	}
+	return UpdateTrue
+}
+
+// filled returns the number of shares in the known fills.
+func (m *OrderManager) filled() (shares int64) {
+	for _, f := range m.fills {
+		shares += f.Exec.Shares
+	}
+	return shares
+}
+
+func (m *OrderManager) preDestroy() {
+	m.eng.Unsubscribe(m.rc, m.execID)
+	m.eng.Unsubscribe(m.rc, UnmatchedReplyID)
+	if m.id != 0 {
+		m.eng.Unsubscribe(m.rc, m.id)
+	}
+}
+
+// OrderID returns the order ID, or 0 if the order has not been placed yet.
+func (m *OrderManager) OrderID() int64 {
+	m.rwm.RLock()
+	defer m.rwm.RUnlock()
+	return m.id
+}
+
+// Order returns the order as most recently placed or modified.
+func (m *OrderManager) Order() Order {
+	m.rwm.RLock()
+	defer m.rwm.RUnlock()
+	return m.order
+}
+
+// Status returns the most recent order status.
+func (m *OrderManager) Status() OrderStatus {
+	m.rwm.RLock()
+	defer m.rwm.RUnlock()
+	return m.status
+}
+
+// Fills returns the executions of the order.
+func (m *OrderManager) Fills() []ExecutionData {
+	m.rwm.RLock()
+	defer m.rwm.RUnlock()
+	return append([]ExecutionData(nil), m.fills...)
+}
+
+// Commissions returns the commission reports of the order's executions.
+func (m *OrderManager) Commissions() []CommissionReport {
+	m.rwm.RLock()
+	defer m.rwm.RUnlock()
+	var r []CommissionReport
+	for _, f := range m.fills {
+		if c, ok := m.commissions[f.Exec.ExecID]; ok {
+			r = append(r, c)
+		}
+	}
+	return r
+}
+
+// AvgPrice returns the volume-weighted average price of the known fills,
+// falling back to the average fill price reported by the order status.
+func (m *OrderManager) AvgPrice() float64 {
+	m.rwm.RLock()
+	defer m.rwm.RUnlock()
+	var shares int64
+	var value float64
+	for _, f := range m.fills {
+		shares += f.Exec.Shares
+		value += float64(f.Exec.Shares) * f.Exec.Price
+	}
+	if shares == 0 {
+		return m.status.AverageFillPrice
+	}
+	return value / float64(shares)
+}
+
+// Modify replaces the order (eg to change its price or quantity). The order ID
+// is retained.
+func (m *OrderManager) Modify(o Order) error {
+	m.rwm.Lock()
+	if m.id == 0 {
+		m.rwm.Unlock()
+		return errors.New("goib: order has not been placed yet")
+	}
+	o.OrderID = m.id
+	m.order = o
+	m.rwm.Unlock()
+	return m.place()
+}
+
+// Cancel requests cancellation of the order.
+func (m *OrderManager) Cancel() error {
+	id := m.OrderID()
+	if id == 0 {
+		return errors.New("goib: order has not been placed yet")
+	}
+	req := &CancelOrder{}
+	req.SetID(id)
+	return m.eng.Send(req)
+}
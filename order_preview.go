@@ -0,0 +1,106 @@
+package ib
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+// previewTimeout is how long PreviewOrder waits for IB's answer.
+var previewTimeout = 10 * time.Second
+
+// MarginImpact is IB's what-if assessment of an order: the impact the order
+// would have on the account's margin and equity, and its commission. Values IB
+// did not report are math.MaxFloat64.
+type MarginImpact struct {
+	InitialMargin      float64
+	MaintenanceMargin  float64
+	EquityWithLoan     float64
+	Commission         float64
+	MinCommission      float64
+	MaxCommission      float64
+	CommissionCurrency string
+	WarningText        string
+}
+
+// NewMarginImpact parses the margin strings of the OrderState of a what-if
+// OpenOrder.
+func NewMarginImpact(s OrderState) (MarginImpact, error) {
+	m := MarginImpact{
+		Commission:         s.Commission,
+		MinCommission:      s.MinCommission,
+		MaxCommission:      s.MaxCommission,
+		CommissionCurrency: s.CommissionCurrency,
+		WarningText:        s.WarningText,
+	}
+	for _, f := range []struct {
+		name string
+		s    string
+		v    *float64
+	}{
+		{"initial margin", s.InitialMargin, &m.InitialMargin},
+		{"maintenance margin", s.MaintenanceMargin, &m.MaintenanceMargin},
+		{"equity with loan", s.EquityWithLoan, &m.EquityWithLoan},
+	} {
+		if f.s == "" {
+			*f.v = math.MaxFloat64
+			continue
+		}
+		v, err := strconv.ParseFloat(f.s, 64)
+		if err != nil {
+			return MarginImpact{}, fmt.Errorf("goib: cannot parse %s %q", f.name, f.s)
+		}
+		*f.v = v
+	}
+	return m, nil
+}
+
+// PreviewOrder asks IB what the order would do to the account's margin,
+// without transmitting it. It places the order with WhatIf set and blocks for
+// the OpenOrder reply.
+func PreviewOrder(e *Engine, c Contract, o Order) (MarginImpact, error) {
+	id, err := e.NextOrderID()
+	if err != nil {
+		return MarginImpact{}, err
+	}
+	o.OrderID = id
+	o.WhatIf = true
+
+	rc := make(chan Reply)
+	e.Subscribe(rc, id)
+	defer e.Unsubscribe(rc, id)
+
+	req := &PlaceOrder{Contract: c, Order: o}
+	req.SetID(id)
+	if err := e.Send(req); err != nil {
+		return MarginImpact{}, err
+	}
+
+	timeout := time.After(previewTimeout)
+	for {
+		select {
+		case r := <-rc:
+			switch r := r.(type) {
+			case *ErrorMessage:
+				if r.ID() != id || r.SeverityWarning() {
+					continue
+				}
+				if r.Code == errorDuplicateOrderID {
+					return MarginImpact{}, &DuplicateOrderIDError{OrderID: id}
+				}
+				return MarginImpact{}, r.Error()
+			case *OpenOrder:
+				return NewMarginImpact(r.OrderState)
+			}
+		case <-timeout:
+			// a what-if order is never transmitted, but make sure
+			cancel := &CancelOrder{}
+			cancel.SetID(id)
+			e.Send(cancel)
+			return MarginImpact{}, fmt.Errorf("goib: no what-if preview of order %d within %s", id, previewTimeout)
+		case <-e.terminated:
+			return MarginImpact{}, fmt.Errorf("goib: engine exited before preview of order %d", id)
+		}
+	}
+}
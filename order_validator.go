@@ -0,0 +1,136 @@
+package ib
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// OrderViolation is a problem CheckOrder found with an order, which IB would
+// otherwise reject after it was sent.
+type OrderViolation struct {
+	Field   string // the Order or Contract field at fault, eg "LimitPrice"
+	Message string
+}
+
+func (v OrderViolation) Error() string {
+	return fmt.Sprintf("goib: %s: %s", v.Field, v.Message)
+}
+
+// Time in force values, as set in Order.TIF.
+const (
+	TIFDay               = "DAY"
+	TIFGoodTillCancelled = "GTC"
+	TIFImmediateOrCancel = "IOC"
+	TIFGoodTillDate      = "GTD"
+	TIFOpening           = "OPG"
+	TIFFillOrKill        = "FOK"
+	TIFDayTillCancelled  = "DTC"
+)
+
+// CheckOrder validates the order for the contract against the contract's
+// details, returning every violation found (none if the order looks valid).
+// It checks the order type is offered, the exchange is valid, prices are
+// multiples of the minimum tick, and that the quantity, time in force, algo
+// and delta neutral fields are consistent.
+func CheckOrder(o Order, c Contract, d ContractDetails) []OrderViolation {
+	var vs []OrderViolation
+	add := func(field string, format string, args ...interface{}) {
+		vs = append(vs, OrderViolation{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	validate := ValidateOrder
+	if c.SecurityType == bagSecType {
+		validate = ValidateComboOrder
+	}
+	if err := validate(o); err != nil {
+		add("Order", "%s", strings.TrimPrefix(err.Error(), "goib: "))
+	}
+
+	if c.ContractID != 0 && d.Summary.ContractID != 0 && c.ContractID != d.Summary.ContractID {
+		add("ContractID", "contract %d does not match details of contract %d", c.ContractID, d.Summary.ContractID)
+	}
+	// IB lists order types without spaces (eg "STPLMT")
+	if !containsField(d.OrderTypes, strings.Replace(o.OrderType, " ", "", -1)) {
+		add("OrderType", "order type %q is not offered for the contract", o.OrderType)
+	}
+	if c.Exchange == "" || !containsField(d.ValidExchanges, c.Exchange) {
+		add("Exchange", "exchange %q is not one of %q", c.Exchange, d.ValidExchanges)
+	}
+
+	// MinTick is in the magnified price units
+	if d.MinTick > 0 {
+		magnifier := float64(1)
+		if d.PriceMagnifier > 1 {
+			magnifier = float64(d.PriceMagnifier)
+		}
+		for _, p := range []struct {
+			field string
+			price float64
+		}{
+			{"LimitPrice", o.LimitPrice},
+			{"AuxPrice", o.AuxPrice},
+			{"TrailStopPrice", o.TrailStopPrice},
+		} {
+			if !priced(p.price) {
+				continue
+			}
+			ticks := p.price * magnifier / d.MinTick
+			if math.Abs(ticks-math.Round(ticks)) > 1e-6 {
+				add(p.field, "price %v is not a multiple of the minimum tick %v", p.price, d.MinTick/magnifier)
+			}
+		}
+	}
+
+	if o.MinQty != math.MaxInt64 && (o.MinQty <= 0 || o.MinQty > o.TotalQty) {
+		add("MinQty", "minimum quantity %d must be positive and at most the order quantity %d", o.MinQty, o.TotalQty)
+	}
+	if o.DisplaySize < 0 || o.DisplaySize > o.TotalQty {
+		add("DisplaySize", "display size %d must be between 0 and the order quantity %d", o.DisplaySize, o.TotalQty)
+	}
+
+	switch o.TIF {
+	case "", TIFDay, TIFGoodTillCancelled, TIFImmediateOrCancel, TIFOpening, TIFFillOrKill, TIFDayTillCancelled:
+		if o.GoodTillDate != "" {
+			add("GoodTillDate", "good till date requires time in force %s, not %q", TIFGoodTillDate, o.TIF)
+		}
+	case TIFGoodTillDate:
+		if o.GoodTillDate == "" {
+			add("GoodTillDate", "time in force %s requires a good till date", TIFGoodTillDate)
+		}
+	default:
+		add("TIF", "unknown time in force %q", o.TIF)
+	}
+
+	if o.AlgoStrategy == "" && len(o.AlgoParams.Params) > 0 {
+		add("AlgoParams", "algo parameters require an algo strategy")
+	}
+	for _, p := range o.AlgoParams.Params {
+		if p == nil || p.Tag == "" || p.Value == "" {
+			add("AlgoParams", "algo parameters must have a tag and a value")
+			break
+		}
+	}
+
+	if o.DeltaNeutralOrderType == "" {
+		if priced(o.DeltaNeutralAuxPrice) || o.DeltaNeutral.ContractID != 0 {
+			add("DeltaNeutralOrderType", "delta neutral fields require a delta neutral order type")
+		}
+	} else {
+		if !priced(o.Volatility) {
+			add("Volatility", "delta neutral orders require a volatility")
+		}
+		switch o.DeltaNeutralOrderType {
+		case OrderTypeLimit, OrderTypeStop, OrderTypeStopLimit, "REL":
+			if !priced(o.DeltaNeutralAuxPrice) {
+				add("DeltaNeutralAuxPrice", "delta neutral order type %q requires an aux price", o.DeltaNeutralOrderType)
+			}
+		}
+		// a short sale held elsewhere (slot 2) must say where
+		if dn := o.DeltaNeutral; dn.ShortSale && dn.ShortSaleSlot == 2 && dn.DesignatedLocation == "" {
+			add("DeltaNeutral", "short sale slot 2 requires a designated location")
+		}
+	}
+
+	return vs
+}
@@ -0,0 +1,74 @@
+package ib
+
+import (
+	"testing"
+)
+
+var validatorDetails = ContractDetails{
+	Summary:        Contract{ContractID: 8314, Symbol: "IBM", SecurityType: "STK", Exchange: "SMART"},
+	MinTick:        0.01,
+	PriceMagnifier: 1,
+	OrderTypes:     "ACTIVETIM,ALGO,DAY,GTC,GTD,LMT,MKT,STP,STPLMT,TRAIL",
+	ValidExchanges: "SMART,NYSE,ISLAND",
+}
+
+func violated(vs []OrderViolation, field string) bool {
+	for _, v := range vs {
+		if v.Field == field {
+			return true
+		}
+	}
+	return false
+}
+
+func TestCheckOrder(t *testing.T) {
+	c := Contract{ContractID: 8314, Symbol: "IBM", SecurityType: "STK", Exchange: "SMART", Currency: "USD"}
+
+	o, _ := StopLimit(ActionBuy, 100, 150.25, 150.5)
+	if vs := CheckOrder(o, c, validatorDetails); len(vs) != 0 {
+		t.Fatalf("unexpected violations %v", vs)
+	}
+
+	o, _ = LimitOrder(ActionBuy, 100, 150.255)
+	o.TIF = TIFGoodTillDate
+	o.AlgoStrategy = "Adaptive"
+	o.AlgoParams.Params = []*TagValue{{Tag: "adaptivePriority"}}
+	bad := c
+	bad.Exchange = "ARCA"
+	vs := CheckOrder(o, bad, validatorDetails)
+	for _, field := range []string{"LimitPrice", "Exchange", "GoodTillDate", "AlgoParams"} {
+		if !violated(vs, field) {
+			t.Errorf("expected a %s violation in %v", field, vs)
+		}
+	}
+	if len(vs) != 4 {
+		t.Fatalf("expected 4 violations, got %v", vs)
+	}
+
+	o, _ = MarketOrder(ActionSell, 100)
+	o.OrderType = "REL"
+	o.TotalQty = -5
+	o.DeltaNeutralOrderType = OrderTypeLimit
+	vs = CheckOrder(o, c, validatorDetails)
+	for _, field := range []string{"Order", "OrderType", "Volatility", "DeltaNeutralAuxPrice"} {
+		if !violated(vs, field) {
+			t.Errorf("expected a %s violation in %v", field, vs)
+		}
+	}
+}
+
+func TestCheckOrderPriceMagnifier(t *testing.T) {
+	d := validatorDetails
+	d.MinTick = 1
+	d.PriceMagnifier = 100
+	c := Contract{Exchange: "SMART"}
+
+	o, _ := LimitOrder(ActionBuy, 1, 12.34)
+	if vs := CheckOrder(o, c, d); len(vs) != 0 {
+		t.Fatalf("unexpected violations %v", vs)
+	}
+	o.LimitPrice = 12.345
+	if vs := CheckOrder(o, c, d); !violated(vs, "LimitPrice") {
+		t.Fatalf("expected a tick violation, got %v", vs)
+	}
+}
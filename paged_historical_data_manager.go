@@ -0,0 +1,190 @@
+package ib
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// errorHistoricalData is the ErrorMessage code for historical data service
+// errors, including queries which returned no data.
+const errorHistoricalData = 162
+
+// histMaxDurations holds the longest duration IB accepts per request for each
+// bar size.
+var histMaxDurations = map[HistDataBarSize]time.Duration{
+	HistBarSize1Sec:   1800 * time.Second,
+	HistBarSize5Sec:   3600 * time.Second,
+	HistBarSize10Sec:  4 * time.Hour,
+	HistBarSize15Sec:  4 * time.Hour,
+	HistBarSize30Sec:  8 * time.Hour,
+	HistBarSize1Min:   24 * time.Hour,
+	HistBarSize2Min:   2 * 24 * time.Hour,
+	HistBarSize3Min:   7 * 24 * time.Hour,
+	HistBarSize5Min:   7 * 24 * time.Hour,
+	HistBarSize10Min:  7 * 24 * time.Hour,
+	HistBarSize15Min:  14 * 24 * time.Hour,
+	HistBarSize20Min:  14 * 24 * time.Hour,
+	HistBarSize30Min:  30 * 24 * time.Hour,
+	HistBarSize1Hour:  30 * 24 * time.Hour,
+	HistBarSize2Hour:  30 * 24 * time.Hour,
+	HistBarSize3Hour:  30 * 24 * time.Hour,
+	HistBarSize4Hour:  30 * 24 * time.Hour,
+	HistBarSize8Hour:  30 * 24 * time.Hour,
+	HistBarSize1Day:   365 * 24 * time.Hour,
+	HistBarSize1Week:  365 * 24 * time.Hour,
+	HistBarSize1Month: 365 * 24 * time.Hour,
+}
+
+// histDuration formats d as a request Duration, rounding up to whole seconds
+// (below a day) or days.
+func histDuration(d time.Duration) string {
+	const day = 24 * time.Hour
+	if d < day {
+		return fmt.Sprintf("%d S", (d+time.Second-1)/time.Second)
+	}
+	return fmt.Sprintf("%d D", (d+day-1)/day)
+}
+
+// PagedHistoricalDataManager downloads historical data for a range longer than
+// IB accepts in one request. It requests windows of the longest legal duration
+// for the bar size, walking backwards from the end of the range, and merges
+// the bars into one ordered list. Refresh() signals each window received.
+type PagedHistoricalDataManager struct {
+	AbstractManager
+	request  RequestHistoricalData // the window currently requested
+	start    time.Time
+	end      time.Time
+	window   time.Duration
+	histData []HistoricalDataItem
+}
+
+// NewPagedHistoricalDataManager downloads the bars of [start, request.EndDateTime).
+// The request's Duration is ignored.
+func NewPagedHistoricalDataManager(e *Engine, request RequestHistoricalData, start time.Time) (*PagedHistoricalDataManager, error) {
+	window, ok := histMaxDurations[request.BarSize]
+	if !ok {
+		return nil, fmt.Errorf("goib: unsupported historical bar size %q", request.BarSize)
+	}
+	if !start.Before(request.EndDateTime) {
+		return nil, fmt.Errorf("goib: historical data range %s - %s is empty", start, request.EndDateTime)
+	}
+
+	am, err := NewAbstractManager(e)
+	if err != nil {
+		return nil, err
+	}
+
+	request.id = e.NextRequestID()
+	m := &PagedHistoricalDataManager{
+		AbstractManager: *am,
+		request:         request,
+		start:           start,
+		end:             request.EndDateTime,
+		window:          window,
+	}
+	m.setWindow(request.EndDateTime)
+
+	go m.startMainLoop(m.preLoop, m.receive, m.preDestroy)
+	return m, nil
+}
+
+// setWindow sets up the request for the window ending at end.
+func (m *PagedHistoricalDataManager) setWindow(end time.Time) {
+	d := m.window
+	if rest := end.Sub(m.start); rest < d {
+		d = rest
+	}
+	m.request.EndDateTime = end
+	m.request.Duration = histDuration(d)
+}
+
+func (m *PagedHistoricalDataManager) preLoop() error {
+	m.eng.Subscribe(m.rc, m.request.id)
+	m.rwm.RLock()
+	req := m.request
+	m.rwm.RUnlock()
+	return m.eng.HistoricalScheduler().Send(&req)
+}
+
+func (m *PagedHistoricalDataManager) receive(r Reply) (UpdateStatus, error) {
+	switch r.(type) {
+	case *ErrorMessage:
+		r := r.(*ErrorMessage)
+		if r.ID() != m.request.id || r.SeverityWarning() {
+			return UpdateFalse, nil
+		}
+		if IsPacingViolation(r) && m.eng.HistoricalScheduler().Retry(r.ID()) {
+			return UpdateFalse, nil
+		}
+		if r.Code == errorHistoricalData && strings.Contains(r.Message, "returned no data") {
+			return m.page(nil)
+		}
+		return UpdateFalse, r.Error()
+	case *HistoricalData:
+		return m.page(r.(*HistoricalData).Data)
+	}
+	return UpdateFalse, fmt.Errorf("Unexpected type %v", r)
+}
+
+// page merges the bars of the current window and requests the next one.
+func (m *PagedHistoricalDataManager) page(items []HistoricalDataItem) (UpdateStatus, error) {
+	next := m.request.EndDateTime.Add(-m.window)
+	for _, i := range items {
+		if i.Date.Before(next) {
+			next = i.Date
+		}
+	}
+	m.merge(items)
+
+	if !next.After(m.start) {
+		m.request.EndDateTime = m.start
+		return UpdateFinish, nil
+	}
+	m.setWindow(next)
+	req := m.request
+	if err := m.eng.HistoricalScheduler().Send(&req); err != nil {
+		return UpdateFalse, err
+	}
+	return UpdateTrue, nil
+}
+
+// merge adds the items within the range, keeping bars ordered and unique by date.
+func (m *PagedHistoricalDataManager) merge(items []HistoricalDataItem) {
+	for _, i := range items {
+		if !i.Date.Before(m.start) && i.Date.Before(m.end) {
+			m.histData = append(m.histData, i)
+		}
+	}
+	sort.SliceStable(m.histData, func(a, b int) bool {
+		return m.histData[a].Date.Before(m.histData[b].Date)
+	})
+	var merged []HistoricalDataItem
+	for _, i := range m.histData {
+		if n := len(merged); n > 0 && merged[n-1].Date.Equal(i.Date) {
+			continue
+		}
+		merged = append(merged, i)
+	}
+	m.histData = merged
+}
+
+func (m *PagedHistoricalDataManager) preDestroy() {
+	m.eng.HistoricalScheduler().Cancel(m.request.id)
+	m.eng.Unsubscribe(m.rc, m.request.id)
+}
+
+// Items returns the bars received so far, oldest first.
+func (m *PagedHistoricalDataManager) Items() []HistoricalDataItem {
+	m.rwm.RLock()
+	defer m.rwm.RUnlock()
+	return append([]HistoricalDataItem(nil), m.histData...)
+}
+
+// Progress returns the fraction of the range downloaded, from 0 to 1.
+func (m *PagedHistoricalDataManager) Progress() float64 {
+	m.rwm.RLock()
+	defer m.rwm.RUnlock()
+	return float64(m.end.Sub(m.request.EndDateTime)) / float64(m.end.Sub(m.start))
+}
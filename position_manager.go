@@ -0,0 +1,159 @@
+package ib
+
+import "strings"
+
+// PositionChangeKind describes how a position changed.
+type PositionChangeKind int
+
+// PositionChangeKind values.
+const (
+	PositionOpened PositionChangeKind = iota
+	PositionChanged
+	PositionClosed
+)
+
+// PositionChange is one change to the positions held. Old is the zero
+// Position for an opened position, and New has a zero Position quantity for a
+// closed one.
+type PositionChange struct {
+	Kind PositionChangeKind
+	Old  Position
+	New  Position
+}
+
+// PositionManager keeps the positions of all accounts up to date, streaming
+// changes until it is closed. Zero positions are dropped.
+//
+// Refresh() signals once the initial positions have been received and then
+// after each change. The changes themselves are returned by Changes().
+type PositionManager struct {
+	AbstractManager
+	positions map[PositionKey]Position
+	seen      map[PositionKey]bool // keys received since (re)requesting
+	synced    bool                 // PositionEnd received since (re)requesting
+	changes   []PositionChange
+}
+
+// NewPositionManager .
+func NewPositionManager(e *Engine) (*PositionManager, error) {
+	am, err := NewAbstractManager(e)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &PositionManager{
+		AbstractManager: *am,
+		positions:       map[PositionKey]Position{},
+	}
+
+	go m.startMainLoop(m.preLoop, m.receive, m.preDestroy)
+	return m, nil
+}
+
+func (m *PositionManager) preLoop() error {
+	m.rwm.Lock()
+	m.seen = map[PositionKey]bool{}
+	m.synced = false
+	m.rwm.Unlock()
+
+	m.eng.Subscribe(m.rc, UnmatchedReplyID)
+	return m.eng.Send(&RequestPositions{})
+}
+
+func (m *PositionManager) receive(r Reply) (UpdateStatus, error) {
+	switch r.(type) {
+	case *ErrorMessage:
+		r := r.(*ErrorMessage)
+		if !isPositionError(r) || r.SeveritySystem() {
+			return UpdateFalse, nil
+		}
+		return UpdateFalse, r.Error()
+	case *Position:
+		r := r.(*Position)
+		m.seen[r.Key] = true
+		if !m.apply(*r) || !m.synced {
+			return UpdateFalse, nil
+		}
+		return UpdateTrue, nil
+	case *PositionEnd:
+		if m.synced {
+			return UpdateFalse, nil
+		}
+		// positions not resent after a reconnect were closed while away
+		for k, p := range m.positions {
+			if !m.seen[k] {
+				closed := p
+				closed.Position = 0
+				m.apply(closed)
+			}
+		}
+		m.synced = true
+		return UpdateTrue, nil
+	}
+	return UpdateFalse, nil
+}
+
+// isPositionError reports whether the ErrorMessage concerns positions but no
+// request ID, as is the case for RequestPositions errors. Errors are delivered
+// to every observer, so the others concern other requests.
+func isPositionError(e *ErrorMessage) bool {
+	if e.ID() != -1 && e.ID() != UnmatchedReplyID {
+		return false
+	}
+	return strings.Contains(strings.ToLower(e.Message), "position")
+}
+
+// apply updates the positions, recording and reporting any change.
+func (m *PositionManager) apply(p Position) bool {
+	old, held := m.positions[p.Key]
+	switch {
+	case p.Position == 0 && !held:
+		return false
+	case p.Position == 0:
+		delete(m.positions, p.Key)
+		m.changes = append(m.changes, PositionChange{Kind: PositionClosed, Old: old, New: p})
+	case !held:
+		m.positions[p.Key] = p
+		m.changes = append(m.changes, PositionChange{Kind: PositionOpened, New: p})
+	case old.Position != p.Position || old.AverageCost != p.AverageCost:
+		m.positions[p.Key] = p
+		m.changes = append(m.changes, PositionChange{Kind: PositionChanged, Old: old, New: p})
+	default:
+		return false
+	}
+	return true
+}
+
+func (m *PositionManager) preDestroy() {
+	m.eng.Unsubscribe(m.rc, UnmatchedReplyID)
+	m.eng.Send(&CancelPositions{})
+}
+
+// Positions returns the open positions.
+func (m *PositionManager) Positions() map[PositionKey]Position {
+	m.rwm.RLock()
+	defer m.rwm.RUnlock()
+	positions := make(map[PositionKey]Position, len(m.positions))
+	for k, p := range m.positions {
+		positions[k] = p
+	}
+	return positions
+}
+
+// Position returns the open position for the key.
+func (m *PositionManager) Position(k PositionKey) (Position, bool) {
+	m.rwm.RLock()
+	defer m.rwm.RUnlock()
+	p, ok := m.positions[k]
+	return p, ok
+}
+
+// Changes returns the changes since the last call, oldest first. Calling it
+// after each Refresh() signal observes every change exactly once.
+func (m *PositionManager) Changes() []PositionChange {
+	m.rwm.Lock()
+	defer m.rwm.Unlock()
+	changes := m.changes
+	m.changes = nil
+	return changes
+}
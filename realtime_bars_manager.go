@@ -0,0 +1,204 @@
+package ib
+
+import (
+	"fmt"
+	"time"
+)
+
+// realTimeBarSize is the only bar size supported for real time bars.
+const realTimeBarSize = 5 * time.Second
+
+// RealTimeBar is a RealtimeBars reply with its start time decoded, or an
+// aggregate of several of them.
+type RealTimeBar struct {
+	Time   time.Time
+	Open   float64
+	High   float64
+	Low    float64
+	Close  float64
+	Volume float64
+	WAP    float64
+	Count  int64
+}
+
+// merge adds the later bar n into b.
+func (b *RealTimeBar) merge(n RealTimeBar) {
+	if n.High > b.High {
+		b.High = n.High
+	}
+	if n.Low < b.Low {
+		b.Low = n.Low
+	}
+	if v := b.Volume + n.Volume; v > 0 {
+		b.WAP = (b.WAP*b.Volume + n.WAP*n.Volume) / v
+	} else {
+		b.WAP = n.WAP
+	}
+	b.Close = n.Close
+	b.Volume += n.Volume
+	b.Count += n.Count
+}
+
+// barRing holds the most recent bars up to its capacity.
+type barRing struct {
+	bars []RealTimeBar
+	next int
+	full bool
+}
+
+func newBarRing(size int) *barRing {
+	return &barRing{bars: make([]RealTimeBar, size)}
+}
+
+func (r *barRing) push(b RealTimeBar) {
+	r.bars[r.next] = b
+	r.next = (r.next + 1) % len(r.bars)
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+// slice returns a copy of the bars, oldest first.
+func (r *barRing) slice() []RealTimeBar {
+	if !r.full {
+		return append([]RealTimeBar(nil), r.bars[:r.next]...)
+	}
+	return append(append([]RealTimeBar(nil), r.bars[r.next:]...), r.bars[:r.next]...)
+}
+
+// RealTimeBarsManager streams 5 second bars, keeping the most recent of them
+// and aggregating them into bars of the given intervals (eg time.Minute).
+// Aggregated bars are aligned to multiples of their interval and complete
+// with the last 5 second bar they cover.
+type RealTimeBarsManager struct {
+	AbstractManager
+	request    RequestRealTimeBars
+	bars       *barRing
+	aggregated map[time.Duration]*barRing
+	building   map[time.Duration]*RealTimeBar
+}
+
+// NewRealTimeBarsManager keeps up to size bars of 5 seconds and of each interval.
+func NewRealTimeBarsManager(e *Engine, request RequestRealTimeBars, size int, intervals ...time.Duration) (*RealTimeBarsManager, error) {
+	if size <= 0 {
+		return nil, fmt.Errorf("goib: invalid real time bars buffer size %d", size)
+	}
+	for _, i := range intervals {
+		if i <= 0 || i%realTimeBarSize != 0 {
+			return nil, fmt.Errorf("goib: real time bars interval %s is not a multiple of %s", i, realTimeBarSize)
+		}
+	}
+
+	am, err := NewAbstractManager(e)
+	if err != nil {
+		return nil, err
+	}
+
+	request.id = e.NextRequestID()
+	request.BarSize = int64(realTimeBarSize / time.Second)
+	m := &RealTimeBarsManager{
+		AbstractManager: *am,
+		request:         request,
+		bars:            newBarRing(size),
+		aggregated:      map[time.Duration]*barRing{},
+		building:        map[time.Duration]*RealTimeBar{},
+	}
+	for _, i := range intervals {
+		m.aggregated[i] = newBarRing(size)
+	}
+
+	go m.startMainLoop(m.preLoop, m.receive, m.preDestroy)
+	return m, nil
+}
+
+func (m *RealTimeBarsManager) preLoop() error {
+	m.eng.Subscribe(m.rc, m.request.id)
+	return m.eng.Send(&m.request)
+}
+
+func (m *RealTimeBarsManager) receive(r Reply) (UpdateStatus, error) {
+	switch r.(type) {
+	case *ErrorMessage:
+		r := r.(*ErrorMessage)
+		if r.ID() != m.request.id || r.SeverityWarning() {
+			return UpdateFalse, nil
+		}
+		return UpdateFalse, r.Error()
+	case *RealtimeBars:
+		r := r.(*RealtimeBars)
+		bar := RealTimeBar{
+			Time:   time.Unix(r.Time, 0),
+			Open:   r.Open,
+			High:   r.High,
+			Low:    r.Low,
+			Close:  r.Close,
+			Volume: r.Volume,
+			WAP:    r.WAP,
+			Count:  r.Count,
+		}
+		m.bars.push(bar)
+		for i := range m.aggregated {
+			m.aggregate(i, bar)
+		}
+		return UpdateTrue, nil
+	}
+	return UpdateFalse, fmt.Errorf("Unexpected type %v", r)
+}
+
+// aggregate adds bar to the bar of the given interval being built.
+func (m *RealTimeBarsManager) aggregate(interval time.Duration, bar RealTimeBar) {
+	start := bar.Time.Truncate(interval)
+	b := m.building[interval]
+	if b != nil && !b.Time.Equal(start) {
+		// bars were missed at the end of the previous interval
+		m.aggregated[interval].push(*b)
+		b = nil
+	}
+	if b == nil {
+		b = &RealTimeBar{}
+		*b = bar
+		b.Time = start
+		m.building[interval] = b
+	} else {
+		b.merge(bar)
+	}
+	if !bar.Time.Add(realTimeBarSize).Before(start.Add(interval)) {
+		m.aggregated[interval].push(*b)
+		delete(m.building, interval)
+	}
+}
+
+func (m *RealTimeBarsManager) preDestroy() {
+	m.eng.Unsubscribe(m.rc, m.request.id)
+	req := &CancelRealTimeBars{}
+	req.SetID(m.request.id)
+	m.eng.Send(req)
+}
+
+// Bars returns the most recent 5 second bars, oldest first.
+func (m *RealTimeBarsManager) Bars() []RealTimeBar {
+	m.rwm.RLock()
+	defer m.rwm.RUnlock()
+	return m.bars.slice()
+}
+
+// Aggregated returns the most recent completed bars of the given interval,
+// oldest first. It returns nil for intervals which were not requested.
+func (m *RealTimeBarsManager) Aggregated(interval time.Duration) []RealTimeBar {
+	m.rwm.RLock()
+	defer m.rwm.RUnlock()
+	if r, ok := m.aggregated[interval]; ok {
+		return r.slice()
+	}
+	return nil
+}
+
+// Current returns the incomplete bar of the given interval, if any.
+func (m *RealTimeBarsManager) Current(interval time.Duration) (RealTimeBar, bool) {
+	m.rwm.RLock()
+	defer m.rwm.RUnlock()
+	if b, ok := m.building[interval]; ok {
+		return *b, true
+	}
+	return RealTimeBar{}, false
+}
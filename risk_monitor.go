@@ -0,0 +1,297 @@
+package ib
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RiskComparator is how a RiskRule compares a value to its threshold.
+type RiskComparator int
+
+// RiskComparator values.
+const (
+	RiskBelow RiskComparator = iota // fires when the value falls below the threshold
+	RiskAbove                       // fires when the value rises above the threshold
+)
+
+// RiskRule is a threshold on an account summary tag. Once fired, a rule only
+// clears when the value moves back past the threshold by Hysteresis, so a
+// value hovering around the threshold does not raise a stream of alerts.
+type RiskRule struct {
+	Name       string
+	Tag        string // eg AccountSummaryTagCushion
+	Account    string // empty for every account
+	Comparator RiskComparator
+	Threshold  float64
+	Hysteresis float64
+	KillSwitch bool // send RequestGlobalCancel when the rule fires
+}
+
+func (r RiskRule) fires(v float64) bool {
+	if r.Comparator == RiskAbove {
+		return v > r.Threshold
+	}
+	return v < r.Threshold
+}
+
+func (r RiskRule) clears(v float64) bool {
+	if r.Comparator == RiskAbove {
+		return v <= r.Threshold-r.Hysteresis
+	}
+	return v >= r.Threshold+r.Hysteresis
+}
+
+// RiskAlertKind .
+type RiskAlertKind int
+
+// RiskAlertKind values.
+const (
+	RiskFired RiskAlertKind = iota
+	RiskCleared
+)
+
+// RiskAlert is published when a rule fires or clears for an account.
+type RiskAlert struct {
+	Kind    RiskAlertKind
+	Rule    RiskRule
+	Account string
+	Value   AccountAmount
+	Time    time.Time
+	// GlobalCancel reports whether a kill switch rule sent RequestGlobalCancel,
+	// and CancelErr any error sending it.
+	GlobalCancel bool
+	CancelErr    error
+}
+
+type riskKey struct {
+	rule    int
+	account string
+}
+
+// RiskMonitor checks each value streamed by an AccountSummaryManager against
+// its rules, publishing an alert on Alerts() whenever a rule fires or clears.
+// Refresh() is signalled after each such change to Firing().
+//
+// The monitor observes the replies to the AccountSummaryManager's request
+// rather than making its own, so it sees every value even if several arrive
+// between two of that manager's refreshes. The AccountSummaryManager remains
+// the client's to consume and close; the monitor closes once it does, with its
+// FatalError().
+type RiskMonitor struct {
+	AbstractManager
+	summary *AccountSummaryManager
+	rules   []RiskRule
+	firing  map[riskKey]RiskAlert
+	all     chan Reply
+	alerts  chan RiskAlert
+	qm      sync.Mutex
+	queue   []RiskAlert
+	queued  chan struct{}
+}
+
+// NewRiskMonitor requires every rule's tag to be one streamed by the
+// AccountSummaryManager.
+func NewRiskMonitor(s *AccountSummaryManager, rules ...RiskRule) (*RiskMonitor, error) {
+	if s == nil {
+		return nil, errors.New("goib: risk monitor requires an account summary manager")
+	}
+	if len(rules) == 0 {
+		return nil, errors.New("goib: risk monitor requires rules")
+	}
+	streamed := map[string]bool{}
+	for _, tag := range s.tags {
+		streamed[tag] = true
+	}
+	for _, r := range rules {
+		if r.Tag == "" {
+			return nil, errors.New("goib: risk rule requires a tag")
+		}
+		if !streamed[r.Tag] {
+			return nil, fmt.Errorf("goib: risk rule tag %s is not streamed by the account summary manager", r.Tag)
+		}
+	}
+	am, err := NewAbstractManager(s.eng)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &RiskMonitor{
+		AbstractManager: *am,
+		summary:         s,
+		rules:           rules,
+		firing:          map[riskKey]RiskAlert{},
+		all:             make(chan Reply),
+		alerts:          make(chan RiskAlert),
+		queued:          make(chan struct{}, 1),
+	}
+
+	// subscribe before reading the values already received, so none is missed
+	m.eng.SubscribeAll(m.all)
+	go m.startMainLoop(m.preLoop, m.receive, m.preDestroy)
+	go m.watch(s.Values())
+	go m.publish()
+	return m, nil
+}
+
+// the account summary manager makes and repeats the request
+func (m *RiskMonitor) preLoop() error { return nil }
+
+// watch passes the values already received, and then each reply to the
+// account summary manager's request, to the main loop. It passes an
+// AccountSummaryEnd once the account summary manager closes.
+func (m *RiskMonitor) watch(initial map[AccountSummaryKey]AccountSummary) {
+	defer m.eng.UnsubscribeAll(m.all)
+
+	forward := func(r Reply) bool {
+		select {
+		case m.rc <- r:
+			return true
+		case <-m.term:
+			return false
+		}
+	}
+	for _, v := range initial {
+		v := v
+		if !forward(&v) {
+			return
+		}
+	}
+	for {
+		select {
+		case r := <-m.all:
+			if s, ok := r.(*AccountSummary); ok && s.ID() == m.summary.id {
+				if !forward(s) {
+					return
+				}
+			}
+		case <-m.summary.term:
+			forward(&AccountSummaryEnd{})
+			return
+		case <-m.term:
+			return
+		}
+	}
+}
+
+func (m *RiskMonitor) receive(r Reply) (UpdateStatus, error) {
+	switch r.(type) {
+	case *AccountSummary:
+		if m.check(r.(*AccountSummary)) {
+			return UpdateTrue, nil
+		}
+	case *AccountSummaryEnd:
+		if err := m.summary.FatalError(); err != nil {
+			return UpdateFalse, err
+		}
+		return UpdateFinish, nil
+	}
+	return UpdateFalse, nil
+}
+
+// check evaluates the rules for the tag of the value, reporting whether any
+// alert was published.
+func (m *RiskMonitor) check(s *AccountSummary) bool {
+	v, err := strconv.ParseFloat(s.Value, 64)
+	if err != nil {
+		return false
+	}
+	published := false
+	for i, rule := range m.rules {
+		if rule.Tag != s.Key.Key || (rule.Account != "" && rule.Account != s.Key.AccountCode) {
+			continue
+		}
+		k := riskKey{rule: i, account: s.Key.AccountCode}
+		alert := RiskAlert{
+			Rule:    rule,
+			Account: s.Key.AccountCode,
+			Value:   AccountAmount{Value: v, Currency: s.Currency},
+			Time:    time.Now(),
+		}
+		_, firing := m.firing[k]
+		switch {
+		case !firing && rule.fires(v):
+			alert.Kind = RiskFired
+			if rule.KillSwitch {
+				alert.GlobalCancel = true
+				alert.CancelErr = m.eng.Send(&RequestGlobalCancel{})
+			}
+			m.firing[k] = alert
+		case firing && rule.clears(v):
+			alert.Kind = RiskCleared
+			delete(m.firing, k)
+		default:
+			continue
+		}
+		m.enqueue(alert)
+		published = true
+	}
+	return published
+}
+
+// enqueue queues the alert for publishing, so a slow reader of Alerts() never
+// blocks the monitor.
+func (m *RiskMonitor) enqueue(a RiskAlert) {
+	m.qm.Lock()
+	m.queue = append(m.queue, a)
+	m.qm.Unlock()
+	select {
+	case m.queued <- struct{}{}:
+	default:
+	}
+}
+
+// publish sends queued alerts until the monitor exits, then closes Alerts().
+func (m *RiskMonitor) publish() {
+	defer close(m.alerts)
+	for {
+		m.qm.Lock()
+		var next RiskAlert
+		pending := len(m.queue) > 0
+		if pending {
+			next = m.queue[0]
+		}
+		m.qm.Unlock()
+
+		if !pending {
+			select {
+			case <-m.queued:
+				continue
+			case <-m.term:
+				return
+			}
+		}
+		select {
+		case m.alerts <- next:
+			m.qm.Lock()
+			m.queue = m.queue[1:]
+			m.qm.Unlock()
+		case <-m.term:
+			return
+		}
+	}
+}
+
+func (m *RiskMonitor) preDestroy() {}
+
+// Alerts returns the channel of alerts, which is closed when the monitor exits.
+func (m *RiskMonitor) Alerts() <-chan RiskAlert {
+	return m.alerts
+}
+
+// Firing returns the alerts of the rules currently fired.
+func (m *RiskMonitor) Firing() []RiskAlert {
+	m.rwm.RLock()
+	defer m.rwm.RUnlock()
+	var firing []RiskAlert
+	for i := range m.rules {
+		for k, a := range m.firing {
+			if k.rule == i {
+				firing = append(firing, a)
+			}
+		}
+	}
+	return firing
+}
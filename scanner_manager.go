@@ -0,0 +1,160 @@
+package ib
+
+import "strings"
+
+// ScannerManager runs a market scanner subscription, keeping the latest ranked
+// results and the rows which entered or left them on the last refresh.
+type ScannerManager struct {
+	AbstractManager
+	request RequestScannerSubscription
+	rows    []ScannerDetail
+	added   []ScannerDetail
+	removed []ScannerDetail
+}
+
+// NewScannerManager .
+func NewScannerManager(e *Engine, s ScannerSubscription, options []TagValue) (*ScannerManager, error) {
+	am, err := NewAbstractManager(e)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &ScannerManager{
+		AbstractManager: *am,
+		request:         RequestScannerSubscription{Subscription: s, ScannerSubscriptionOptions: options},
+	}
+	m.request.SetID(e.NextRequestID())
+
+	go m.startMainLoop(m.preLoop, m.receive, m.preDestroy)
+	return m, nil
+}
+
+func (m *ScannerManager) preLoop() error {
+	m.eng.Subscribe(m.rc, m.request.id)
+	return m.eng.Send(&m.request)
+}
+
+func (m *ScannerManager) receive(r Reply) (UpdateStatus, error) {
+	switch r.(type) {
+	case *ErrorMessage:
+		r := r.(*ErrorMessage)
+		if r.ID() != m.request.id || r.SeverityWarning() {
+			return UpdateFalse, nil
+		}
+		return UpdateFalse, r.Error()
+	case *ScannerData:
+		r := r.(*ScannerData)
+		m.added = scannerDiff(r.Detail, m.rows)
+		m.removed = scannerDiff(m.rows, r.Detail)
+		m.rows = r.Detail
+		return UpdateTrue, nil
+	}
+	return UpdateFalse, nil
+}
+
+// scannerDiff returns the rows of a whose contract is not in b.
+func scannerDiff(a []ScannerDetail, b []ScannerDetail) (diff []ScannerDetail) {
+	in := map[int64]bool{}
+	for _, d := range b {
+		in[d.ContractID] = true
+	}
+	for _, d := range a {
+		if !in[d.ContractID] {
+			diff = append(diff, d)
+		}
+	}
+	return diff
+}
+
+func (m *ScannerManager) preDestroy() {
+	m.eng.Unsubscribe(m.rc, m.request.id)
+	req := &CancelScannerSubscription{}
+	req.SetID(m.request.id)
+	m.eng.Send(req)
+}
+
+// Rows returns the latest results, in rank order.
+func (m *ScannerManager) Rows() []ScannerDetail {
+	m.rwm.RLock()
+	defer m.rwm.RUnlock()
+	return append([]ScannerDetail(nil), m.rows...)
+}
+
+// Added returns the rows which entered the results on the last refresh.
+func (m *ScannerManager) Added() []ScannerDetail {
+	m.rwm.RLock()
+	defer m.rwm.RUnlock()
+	return append([]ScannerDetail(nil), m.added...)
+}
+
+// Removed returns the rows which left the results on the last refresh.
+func (m *ScannerManager) Removed() []ScannerDetail {
+	m.rwm.RLock()
+	defer m.rwm.RUnlock()
+	return append([]ScannerDetail(nil), m.removed...)
+}
+
+// ScannerParametersManager fetches and parses the scanner parameters.
+type ScannerParametersManager struct {
+	AbstractManager
+	id     int64
+	params *ScanParameterSet
+}
+
+// NewScannerParametersManager .
+func NewScannerParametersManager(e *Engine) (*ScannerParametersManager, error) {
+	am, err := NewAbstractManager(e)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &ScannerParametersManager{AbstractManager: *am, id: UnmatchedReplyID}
+
+	go m.startMainLoop(m.preLoop, m.receive, m.preDestroy)
+	return m, nil
+}
+
+func (m *ScannerParametersManager) preLoop() error {
+	m.eng.Subscribe(m.rc, m.id)
+	return m.eng.Send(&RequestScannerParameters{})
+}
+
+func (m *ScannerParametersManager) receive(r Reply) (UpdateStatus, error) {
+	switch r.(type) {
+	case *ErrorMessage:
+		r := r.(*ErrorMessage)
+		if !isScannerError(r) || r.SeverityWarning() {
+			return UpdateFalse, nil
+		}
+		return UpdateFalse, r.Error()
+	case *ScannerParameters:
+		params, err := r.(*ScannerParameters).Parse()
+		if err != nil {
+			return UpdateFalse, err
+		}
+		m.params = params
+		return UpdateFinish, nil
+	}
+	return UpdateFalse, nil
+}
+
+// isScannerError reports whether the ErrorMessage concerns the scanner but
+// no request ID, as is the case for RequestScannerParameters errors. Errors
+// are delivered to every observer, so the others concern other requests.
+func isScannerError(e *ErrorMessage) bool {
+	if e.ID() != -1 && e.ID() != UnmatchedReplyID {
+		return false
+	}
+	return strings.Contains(strings.ToLower(e.Message), "scanner")
+}
+
+func (m *ScannerParametersManager) preDestroy() {
+	m.eng.Unsubscribe(m.rc, m.id)
+}
+
+// Parameters returns the parsed scanner parameters.
+func (m *ScannerParametersManager) Parameters() *ScanParameterSet {
+	m.rwm.RLock()
+	defer m.rwm.RUnlock()
+	return m.params
+}
@@ -0,0 +1,147 @@
+package ib
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+// ScanParameterSet is the typed form of the ScannerParameters XML.
+type ScanParameterSet struct {
+	Instruments   []ScanInstrument `xml:"InstrumentList>Instrument"`
+	Locations     []ScanLocation   `xml:"LocationTree>Location"`
+	ScanTypes     []ScanType       `xml:"ScanTypeList>ScanType"`
+	RangeFilters  []ScanFilter     `xml:"FilterList>RangeFilter"`
+	SimpleFilters []ScanFilter     `xml:"FilterList>SimpleFilter"`
+}
+
+// ScanInstrument is an instrument type which can be scanned (eg "STK").
+type ScanInstrument struct {
+	Name      string `xml:"name"`
+	Type      string `xml:"type"`
+	Filters   string `xml:"filters"` // comma separated filter IDs
+	Group     string `xml:"group"`
+	ShortName string `xml:"shortName"`
+}
+
+// ScanLocation is a node of the location tree (eg "STK.US.MAJOR").
+type ScanLocation struct {
+	Name          string         `xml:"name"`
+	Instruments   string         `xml:"instruments"`
+	RouteExchange string         `xml:"routeExchange"`
+	LocationCode  string         `xml:"locationCode"`
+	Locations     []ScanLocation `xml:"LocationTree>Location"`
+}
+
+// ScanType is a scan code (eg "TOP_PERC_GAIN").
+type ScanType struct {
+	DisplayName string `xml:"displayName"`
+	ScanCode    string `xml:"scanCode"`
+	Instruments string `xml:"instruments"` // comma separated instrument types
+}
+
+// ScanFilter is a filter, whose fields are used as scanner subscription
+// options (eg "priceAbove").
+type ScanFilter struct {
+	ID       string      `xml:"id"`
+	Category string      `xml:"category"`
+	Fields   []ScanField `xml:"AbstractField"`
+}
+
+// ScanField is a filter field.
+type ScanField struct {
+	Type        string `xml:"type,attr"`
+	Code        string `xml:"code"`
+	DisplayName string `xml:"displayName"`
+}
+
+// Parse decodes the XML of the reply.
+func (s *ScannerParameters) Parse() (*ScanParameterSet, error) {
+	p := &ScanParameterSet{}
+	if err := xml.Unmarshal([]byte(s.XML), p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Location returns the location with the given code, searching the whole tree.
+func (p *ScanParameterSet) Location(code string) (ScanLocation, bool) {
+	var find func([]ScanLocation) (ScanLocation, bool)
+	find = func(ls []ScanLocation) (ScanLocation, bool) {
+		for _, l := range ls {
+			if l.LocationCode == code {
+				return l, true
+			}
+			if found, ok := find(l.Locations); ok {
+				return found, true
+			}
+		}
+		return ScanLocation{}, false
+	}
+	return find(p.Locations)
+}
+
+// ScanType returns the scan type with the given code.
+func (p *ScanParameterSet) ScanType(code string) (ScanType, bool) {
+	for _, t := range p.ScanTypes {
+		if t.ScanCode == code {
+			return t, true
+		}
+	}
+	return ScanType{}, false
+}
+
+// Field returns the filter field with the given code.
+func (p *ScanParameterSet) Field(code string) (ScanField, bool) {
+	for _, fs := range [][]ScanFilter{p.RangeFilters, p.SimpleFilters} {
+		for _, f := range fs {
+			for _, field := range f.Fields {
+				if field.Code == code {
+					return field, true
+				}
+			}
+		}
+	}
+	return ScanField{}, false
+}
+
+// Validate checks the subscription's instrument, location and scan code, and
+// that each option is a known filter field.
+func (p *ScanParameterSet) Validate(s ScannerSubscription, options []TagValue) error {
+	found := false
+	for _, i := range p.Instruments {
+		if i.Type == s.Instrument {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return fmt.Errorf("goib: unknown scanner instrument %q", s.Instrument)
+	}
+	if _, ok := p.Location(s.LocationCode); !ok {
+		return fmt.Errorf("goib: unknown scanner location %q", s.LocationCode)
+	}
+	t, ok := p.ScanType(s.ScanCode)
+	if !ok {
+		return fmt.Errorf("goib: unknown scan code %q", s.ScanCode)
+	}
+	if !containsField(t.Instruments, s.Instrument) {
+		return fmt.Errorf("goib: scan code %q does not support instrument %q", s.ScanCode, s.Instrument)
+	}
+	for _, o := range options {
+		if _, ok := p.Field(o.Tag); !ok {
+			return fmt.Errorf("goib: unknown scanner filter %q", o.Tag)
+		}
+	}
+	return nil
+}
+
+// containsField reports whether the comma separated list contains s.
+func containsField(list string, s string) bool {
+	for _, f := range strings.Split(list, ",") {
+		if strings.TrimSpace(f) == s {
+			return true
+		}
+	}
+	return false
+}
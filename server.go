@@ -0,0 +1,59 @@
+package ib
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"time"
+)
+
+// This file implements the gateway side of the wire protocol. It is used by
+// package ibtest, and is equally suitable for recorders and proxies.
+
+// ServerHandshake reads the client version and replies with the server
+// version and the given server time, as a gateway does on a new connection.
+func ServerHandshake(r *bufio.Reader, w io.Writer, t time.Time) (clientVersion int64, err error) {
+	clientShake := &clientHandshake{}
+	if err = clientShake.read(r); err != nil {
+		return
+	}
+
+	b := &bytes.Buffer{}
+	serverShake := &serverHandshake{version: minServerVersion, time: t}
+	if err = serverShake.write(b); err != nil {
+		return
+	}
+	_, err = w.Write(b.Bytes())
+	return clientShake.version, err
+}
+
+// ReadRequest decodes the next Request sent by a client.
+func ReadRequest(b *bufio.Reader) (Request, error) {
+	hdr := &header{}
+	if err := hdr.read(b); err != nil {
+		return nil, err
+	}
+
+	r, err := code2Req(hdr.code)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.read(b); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// WriteReply encodes the given Reply (including its header) into b.
+func WriteReply(b *bytes.Buffer, r Reply) error {
+	// the engine ignores reply versions
+	hdr := &header{
+		code:    int64(r.code()),
+		version: 1,
+	}
+	if err := hdr.write(b); err != nil {
+		return err
+	}
+	return r.write(b)
+}
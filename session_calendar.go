@@ -0,0 +1,223 @@
+package ib
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ibTimezones maps the legacy zone names IB sends in TimezoneID to IANA
+// zones. (Go would load some of these, such as "EST", as fixed offsets without
+// daylight saving.)
+var ibTimezones = map[string]string{
+	"EST":     "America/New_York",
+	"EST5EDT": "America/New_York",
+	"CST":     "America/Chicago",
+	"CST6CDT": "America/Chicago",
+	"MST":     "America/Denver",
+	"MST7MDT": "America/Denver",
+	"PST":     "America/Los_Angeles",
+	"PST8PDT": "America/Los_Angeles",
+	"GMT":     "Europe/London",
+	"MET":     "Europe/Berlin",
+	"CET":     "Europe/Berlin",
+	"JST":     "Asia/Tokyo",
+	"CTT":     "Asia/Shanghai",
+	"HKT":     "Asia/Hong_Kong",
+	"AET":     "Australia/Sydney",
+	"IST":     "Asia/Kolkata",
+}
+
+// LoadIBLocation loads the location for an IB TimezoneID.
+func LoadIBLocation(id string) (*time.Location, error) {
+	if zone, ok := ibTimezones[id]; ok {
+		id = zone
+	}
+	loc, err := time.LoadLocation(id)
+	if err != nil {
+		return nil, fmt.Errorf("goib: unknown time zone %q: %s", id, err)
+	}
+	return loc, nil
+}
+
+// Session is a period a market is open, from Start until (not including) End.
+type Session struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Contains reports whether t is within the session.
+func (s Session) Contains(t time.Time) bool {
+	return !t.Before(s.Start) && t.Before(s.End)
+}
+
+// ParseSessions parses IB trading hours in the location. Both of IB's formats
+// are accepted: "20140314:0930-1600,1700-1800;20140315:CLOSED", where a range
+// ending before it starts began on the previous day (eg "1700-1600" for an
+// overnight futures session), and "20180323:0930-20180323:1600". Sessions are
+// returned in order, with contiguous sessions merged.
+func ParseSessions(hours string, loc *time.Location) ([]Session, error) {
+	var sessions []Session
+	for _, day := range strings.Split(hours, ";") {
+		day = strings.TrimSpace(day)
+		if day == "" {
+			continue
+		}
+		i := strings.Index(day, ":")
+		if i < 0 {
+			return nil, fmt.Errorf("goib: malformed trading hours %q", day)
+		}
+		date, ranges := day[:i], day[i+1:]
+		if ranges == "CLOSED" {
+			continue
+		}
+		for _, r := range strings.Split(ranges, ",") {
+			s, err := parseSession(date, r, loc)
+			if err != nil {
+				return nil, err
+			}
+			sessions = append(sessions, s)
+		}
+	}
+
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].Start.Before(sessions[j].Start) })
+	var merged []Session
+	for _, s := range sessions {
+		if n := len(merged); n > 0 && !s.Start.After(merged[n-1].End) {
+			if s.End.After(merged[n-1].End) {
+				merged[n-1].End = s.End
+			}
+			continue
+		}
+		merged = append(merged, s)
+	}
+	return merged, nil
+}
+
+func parseSession(date string, r string, loc *time.Location) (Session, error) {
+	parts := strings.Split(r, "-")
+	if len(parts) != 2 {
+		return Session{}, fmt.Errorf("goib: malformed trading hours range %q", r)
+	}
+	start, err := time.ParseInLocation("20060102 1504", date+" "+parts[0], loc)
+	if err != nil {
+		return Session{}, fmt.Errorf("goib: malformed trading hours range %q", r)
+	}
+
+	// the newer format dates each end of the range
+	if i := strings.Index(parts[1], ":"); i >= 0 {
+		end, err := time.ParseInLocation("20060102 1504", parts[1][:i]+" "+parts[1][i+1:], loc)
+		if err != nil || !end.After(start) {
+			return Session{}, fmt.Errorf("goib: malformed trading hours range %q", r)
+		}
+		return Session{Start: start, End: end}, nil
+	}
+
+	end, err := time.ParseInLocation("20060102 1504", date+" "+parts[1], loc)
+	if err != nil {
+		return Session{}, fmt.Errorf("goib: malformed trading hours range %q", r)
+	}
+	if !end.After(start) {
+		start = start.AddDate(0, 0, -1)
+	}
+	return Session{Start: start, End: end}, nil
+}
+
+// SessionCalendar is the trading and liquid (regular) hours of a contract.
+// It only knows the sessions IB sent, which usually cover the next few days.
+type SessionCalendar struct {
+	loc     *time.Location
+	trading []Session
+	liquid  []Session
+}
+
+// NewSessionCalendar parses the hours of the contract details.
+func NewSessionCalendar(d ContractDetails) (*SessionCalendar, error) {
+	loc, err := LoadIBLocation(d.TimezoneID)
+	if err != nil {
+		return nil, err
+	}
+	trading, err := ParseSessions(d.TradingHours, loc)
+	if err != nil {
+		return nil, err
+	}
+	liquid, err := ParseSessions(d.LiquidHours, loc)
+	if err != nil {
+		return nil, err
+	}
+	return &SessionCalendar{loc: loc, trading: trading, liquid: liquid}, nil
+}
+
+// Location is the exchange's time zone.
+func (c *SessionCalendar) Location() *time.Location {
+	return c.loc
+}
+
+// Sessions returns all trading sessions.
+func (c *SessionCalendar) Sessions() []Session {
+	return append([]Session(nil), c.trading...)
+}
+
+// LiquidSessions returns all liquid sessions.
+func (c *SessionCalendar) LiquidSessions() []Session {
+	return append([]Session(nil), c.liquid...)
+}
+
+// sessionAt returns the index of the first session ending after t.
+func sessionAt(sessions []Session, t time.Time) int {
+	return sort.Search(len(sessions), func(i int) bool { return sessions[i].End.After(t) })
+}
+
+func sessionsContain(sessions []Session, t time.Time) bool {
+	i := sessionAt(sessions, t)
+	return i < len(sessions) && sessions[i].Contains(t)
+}
+
+// IsOpen reports whether t is within a trading session.
+func (c *SessionCalendar) IsOpen(t time.Time) bool {
+	return sessionsContain(c.trading, t)
+}
+
+// IsLiquid reports whether t is within a liquid session.
+func (c *SessionCalendar) IsLiquid(t time.Time) bool {
+	return sessionsContain(c.liquid, t)
+}
+
+// NextOpen returns the start of the first trading session starting after t.
+// It returns false if the calendar has no later session.
+func (c *SessionCalendar) NextOpen(t time.Time) (time.Time, bool) {
+	for i := sessionAt(c.trading, t); i < len(c.trading); i++ {
+		if c.trading[i].Start.After(t) {
+			return c.trading[i].Start.In(c.loc), true
+		}
+	}
+	return time.Time{}, false
+}
+
+// NextClose returns the end of the trading session containing t or, when
+// closed, of the next session. It returns false if the calendar has no such
+// session.
+func (c *SessionCalendar) NextClose(t time.Time) (time.Time, bool) {
+	if i := sessionAt(c.trading, t); i < len(c.trading) {
+		return c.trading[i].End.In(c.loc), true
+	}
+	return time.Time{}, false
+}
+
+// SessionsBetween returns the trading sessions overlapping the period from a
+// until b, clipped to it.
+func (c *SessionCalendar) SessionsBetween(a time.Time, b time.Time) []Session {
+	var sessions []Session
+	for i := sessionAt(c.trading, a); i < len(c.trading) && c.trading[i].Start.Before(b); i++ {
+		s := c.trading[i]
+		if s.Start.Before(a) {
+			s.Start = a
+		}
+		if s.End.After(b) {
+			s.End = b
+		}
+		sessions = append(sessions, s)
+	}
+	return sessions
+}
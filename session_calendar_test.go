@@ -0,0 +1,82 @@
+package ib
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSessionCalendar(t *testing.T) {
+	c, err := NewSessionCalendar(ContractDetails{
+		TimezoneID:   "EST",
+		TradingHours: "20140313:0400-2000;20140314:0400-2000;20140315:CLOSED;20140316:CLOSED;20140317:0400-2000",
+		LiquidHours:  "20140313:0930-1600;20140314:0930-1600;20140315:CLOSED;20140316:CLOSED;20140317:0930-1600",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ny, _ := time.LoadLocation("America/New_York")
+	at := func(d int, hhmm int) time.Time {
+		return time.Date(2014, time.March, d, hhmm/100, hhmm%100, 0, 0, ny)
+	}
+
+	if !c.IsOpen(at(14, 800)) || c.IsLiquid(at(14, 800)) {
+		t.Fatal("expected pre-market trading")
+	}
+	if !c.IsLiquid(at(14, 1200)) || c.IsOpen(at(14, 2000)) || c.IsOpen(at(15, 1200)) {
+		t.Fatal("unexpected session state")
+	}
+	// in UTC, the session is still found
+	if !c.IsLiquid(at(14, 1200).UTC()) {
+		t.Fatal("expected liquid in UTC")
+	}
+
+	if open, ok := c.NextOpen(at(14, 2100)); !ok || !open.Equal(at(17, 400)) {
+		t.Fatalf("unexpected next open %v", open)
+	}
+	if end, ok := c.NextClose(at(14, 1200)); !ok || !end.Equal(at(14, 2000)) {
+		t.Fatalf("unexpected next close %v", end)
+	}
+	if end, ok := c.NextClose(at(15, 1200)); !ok || !end.Equal(at(17, 2000)) {
+		t.Fatalf("unexpected next close when closed %v", end)
+	}
+	if _, ok := c.NextOpen(at(17, 1200)); ok {
+		t.Fatal("expected no open beyond the calendar")
+	}
+
+	s := c.SessionsBetween(at(13, 1200), at(17, 600))
+	if len(s) != 3 || !s[0].Start.Equal(at(13, 1200)) || !s[2].End.Equal(at(17, 600)) {
+		t.Fatalf("unexpected sessions %v", s)
+	}
+}
+
+func TestParseSessionsOvernight(t *testing.T) {
+	loc, err := LoadIBLocation("CST")
+	if err != nil {
+		t.Fatal(err)
+	}
+	chi := func(d int, hhmm int) time.Time {
+		return time.Date(2014, time.March, d, hhmm/100, hhmm%100, 0, 0, loc)
+	}
+
+	// overnight futures sessions, with a maintenance break
+	s, err := ParseSessions("20140313:1700-1515,1530-1615;20140314:1700-1515,1530-1615", loc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s) != 4 || !s[0].Start.Equal(chi(12, 1700)) || !s[0].End.Equal(chi(13, 1515)) || !s[2].Start.Equal(chi(13, 1700)) {
+		t.Fatalf("unexpected sessions %v", s)
+	}
+
+	// the newer format, with contiguous ranges merged
+	s, err = ParseSessions("20140313:1700-20140314:1600;20140314:1600-20140314:1700;20140315:CLOSED", loc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s) != 1 || !s[0].Start.Equal(chi(13, 1700)) || !s[0].End.Equal(chi(14, 1700)) {
+		t.Fatalf("unexpected sessions %v", s)
+	}
+
+	if _, err := ParseSessions("20140313:0930", loc); err == nil {
+		t.Fatal("expected error for malformed hours")
+	}
+}
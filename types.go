@@ -1,11 +1,13 @@
 package ib
 
 import (
+	"bufio"
 	"bytes"
 )
 
 // Request .
 type Request interface {
+	readable
 	writable
 	code() OutgoingMessageID
 	version() int64
@@ -14,6 +16,7 @@ type Request interface {
 // Reply .
 type Reply interface {
 	readable
+	writable
 	code() IncomingMessageID
 }
 
@@ -28,6 +31,7 @@ type MatchedRequest interface {
 type MatchedReply interface {
 	Reply
 	ID() int64
+	SetID(id int64)
 }
 
 type clientHandshake struct {
@@ -37,3 +41,8 @@ type clientHandshake struct {
 func (c *clientHandshake) write(b *bytes.Buffer) error {
 	return writeInt(b, c.version)
 }
+
+func (c *clientHandshake) read(b *bufio.Reader) (err error) {
+	c.version, err = readInt(b)
+	return err
+}
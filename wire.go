@@ -20,6 +20,7 @@ const (
 	timeReadLocalDateTime                // read datetime which is in local time (any TZ designator is ignored)
 	timeReadLocalDate                    // read date which is in local time and does not have any timezone designator
 	timeReadLocalTime                    // read time which is in local time and does not have any timezone designator
+	timeReadUTC                          // read datetime as written by timeWriteUTC
 )
 
 type readable interface {
@@ -58,6 +59,25 @@ func readInt(b *bufio.Reader) (int64, error) {
 	return i, err
 }
 
+// readTagValueList reads an IB "tag=value;" options string into a Go slice.
+func readTagValueList(b *bufio.Reader) (r []TagValue, err error) {
+	s, err := readString(b)
+	if err != nil {
+		return
+	}
+	for _, opt := range strings.Split(s, ";") {
+		if opt == "" {
+			continue
+		}
+		tv := strings.SplitN(opt, "=", 2)
+		if len(tv) == 1 {
+			tv = append(tv, "")
+		}
+		r = append(r, TagValue{Tag: tv[0], Value: tv[1]})
+	}
+	return r, nil
+}
+
 // readIntList reads an IB pipe-separated string of integers into a Go slice.
 func readIntList(b *bufio.Reader) ([]int, error) {
 	s, err := readString(b)
@@ -119,6 +139,11 @@ func readTime(b *bufio.Reader, f timeFmt) (t time.Time, err error) {
 		return time.Unix(epochSecs, 0), nil
 	}
 
+	if f == timeReadUTC {
+		t, err = time.Parse("20060102 15:04:05 MST", timeString)
+		return t.Local(), err
+	}
+
 	if f == timeReadLocalDateTime {
 		format := "20060102 15:04:05"
 		if len(timeString) < len(format) {